@@ -3,6 +3,7 @@ package main
 import (
 	"database/sql"
 	"fmt"
+	"time"
 
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/gin-gonic/gin"
@@ -13,10 +14,13 @@ import (
 	"github.com/achmadnr21/emploman/internal/utils"
 	gin_api "github.com/achmadnr21/emploman/service"
 
+	"github.com/achmadnr21/emploman/internal/authorization"
+	"github.com/achmadnr21/emploman/internal/domain"
 	"github.com/achmadnr21/emploman/internal/repository"
 
 	"github.com/achmadnr21/emploman/internal/usecase"
 	emp "github.com/achmadnr21/emploman/internal/usecase/employee"
+	usecase_scope "github.com/achmadnr21/emploman/internal/usecase/scope"
 
 	"github.com/achmadnr21/emploman/internal/handler"
 )
@@ -52,10 +56,16 @@ func main() {
 	} else {
 		fmt.Println("[Info] API initialized successfully")
 	}
+	// ClientIP dipakai untuk throttle login dan audit log, jadi hanya proxy yang dikonfigurasi yang dipercaya
+	if err := api.Router.SetTrustedProxies(sc.TrustedProxies); err != nil {
+		fmt.Println("[Error] invalid trusted proxies : ", err)
+		panic("Trusted proxies configuration failed")
+	}
 
 	// ========================= Dependency Injection =========================
 	// Repository initialization
-	roleRepo := repository.NewRoleRepository(db)
+	// role jarang berubah dan dibaca hampir di setiap request
+	roleRepo := repository.NewCachedRoleRepository(repository.NewRoleRepository(db), 5*time.Minute)
 	religionRepo := repository.NewReligionRepository(db)
 	gradeRepo := repository.NewGradeRepository(db)
 	echelonRepo := repository.NewEchelonRepository(db)
@@ -65,18 +75,54 @@ func main() {
 	employeeAssignmentRepo := repository.NewEmployeeAssignmentRepository(db)
 	s3Repo := repository.NewS3Repository(s3client, sc.S3bucket)
 	printRepo := repository.NewPrintRepository(db)
+	refreshTokenRepo := repository.NewRefreshTokenRepository(db)
+	auditRepo := repository.NewAuditRepository(db)
+	unitScopeRepo := repository.NewUnitScopeRepository(db)
+	unitGroupRepo := repository.NewUnitGroupRepository(db)
+	formationRepo := repository.NewFormationRepository(db)
+	transferRepo := repository.NewTransferRepository(db)
+	changeRequestRepo := repository.NewChangeRequestRepository(db)
+	passwordRepo := repository.NewPasswordRepository(db)
+	loginAttemptRepo := repository.NewLoginAttemptRepository(db)
+	twoFactorRepo := repository.NewTwoFactorRepository(db)
+	permissionRepo := repository.NewPermissionRepository(db)
+	// katalog permission di database harus sama dengan catalogue pada package authorization
+	if err := authorization.CheckCatalogue(permissionRepo); err != nil {
+		fmt.Println("[Error] checking permission catalogue : ", err, "(run documents/emploman-permissions.sql)")
+		panic("Permission catalogue check failed")
+	}
+
+	// Notifier dipakai untuk mengirim password sementara dan token lupa password
+	var notifier domain.Notifier = repository.NewLogNotifier()
+	if sc.NotifierFile != "" {
+		notifier = repository.NewFileNotifier(sc.NotifierFile)
+	}
+
+	// Unit scope dipakai bersama oleh usecase assignment, employee dan print
+	scopeResolver := usecase_scope.NewResolver(unitScopeRepo, employeeAssignmentRepo, unitRepo, unitGroupRepo)
+	// Seluruh pengecekan permission usecase lewat satu authorizer
+	authz := authorization.NewAuthorizer(roleRepo, scopeResolver)
 
 	// Usecase initialization
-	authUsecase := usecase.NewAuthUsecase(employeeRepo, roleRepo)
-	empUsecase := emp.NewEmployeeUsecase(employeeRepo, roleRepo, unitRepo, s3Repo)
-	meUsecase := usecase.NewMeUsecase(employeeRepo, roleRepo, unitRepo, s3Repo)
-	printUsecase := usecase.NewPrintUsecase(printRepo, employeeRepo, roleRepo, unitRepo)
-	unitUsecase := usecase.NewUnitUsecase(unitRepo, roleRepo)
-	positionUsecase := usecase.NewPositionUsecase(positionRepo, roleRepo)
-	employeeAssignmentUsecase := usecase.NewEmployeeAssignmentUsecase(employeeAssignmentRepo, employeeRepo, roleRepo, unitRepo, positionRepo)
-	religionUsecase := usecase.NewReligionUsecase(religionRepo, roleRepo)
-	gradeUsecase := usecase.NewGradeUsecase(gradeRepo, roleRepo)
-	echelonUsecase := usecase.NewEchelonUsecase(echelonRepo, roleRepo)
+	authUsecase := usecase.NewAuthUsecase(employeeRepo, roleRepo, refreshTokenRepo, passwordRepo, loginAttemptRepo, twoFactorRepo, auditRepo, authz)
+	empUsecase := emp.NewEmployeeUsecase(employeeRepo, roleRepo, unitRepo, s3Repo, gradeRepo, echelonRepo, religionRepo, auditRepo, authz)
+	meUsecase := usecase.NewMeUsecase(employeeRepo, roleRepo, unitRepo, s3Repo, auditRepo)
+	printUsecase := usecase.NewPrintUsecase(printRepo, employeeRepo, unitRepo, employeeAssignmentRepo, s3Repo, authz)
+	unitUsecase := usecase.NewUnitUsecase(unitRepo, authz, auditRepo)
+	positionUsecase := usecase.NewPositionUsecase(positionRepo, authz, auditRepo)
+	employeeAssignmentUsecase := usecase.NewEmployeeAssignmentUsecase(employeeAssignmentRepo, employeeRepo, unitRepo, positionRepo, auditRepo, authz)
+	religionUsecase := usecase.NewReligionUsecase(religionRepo, authz, auditRepo)
+	gradeUsecase := usecase.NewGradeUsecase(gradeRepo, authz, auditRepo)
+	echelonUsecase := usecase.NewEchelonUsecase(echelonRepo, authz, auditRepo)
+	roleUsecase := usecase.NewRoleUsecase(roleRepo, permissionRepo, auditRepo, authz)
+	auditUsecase := usecase.NewAuditUsecase(auditRepo, authz)
+	unitScopeUsecase := usecase.NewUnitScopeUsecase(unitScopeRepo, employeeRepo, roleRepo, unitRepo, unitGroupRepo, auditRepo, authz)
+	unitGroupUsecase := usecase.NewUnitGroupUsecase(unitGroupRepo, unitRepo, authz, auditRepo)
+	formationUsecase := usecase.NewFormationUsecase(formationRepo, unitRepo, positionRepo, authz, auditRepo)
+	transferUsecase := usecase.NewTransferUsecase(transferRepo, employeeAssignmentRepo, employeeRepo, authz, unitRepo, positionRepo, auditRepo)
+	changeRequestUsecase := usecase.NewChangeRequestUsecase(changeRequestRepo, employeeRepo, authz, gradeRepo, echelonRepo, s3Repo, auditRepo, empUsecase)
+	passwordUsecase := usecase.NewPasswordUsecase(passwordRepo, employeeRepo, authz, refreshTokenRepo, notifier, auditRepo)
+	twoFactorUsecase := usecase.NewTwoFactorUsecase(twoFactorRepo, employeeRepo, roleRepo, loginAttemptRepo, refreshTokenRepo, auditRepo, authz)
 	// Handler initialization
 	handler.NewAuthHandler(apiV, authUsecase)
 	handler.NewEmployeeHandler(apiV, empUsecase)
@@ -88,6 +134,16 @@ func main() {
 	handler.NewReligionHandler(apiV, religionUsecase)
 	handler.NewGradeHandler(apiV, gradeUsecase)
 	handler.NewEchelonHandler(apiV, echelonUsecase)
+	handler.NewRoleHandler(apiV, roleUsecase)
+	handler.NewAuditHandler(apiV, auditUsecase)
+	handler.NewUnitScopeHandler(apiV, unitScopeUsecase)
+	handler.NewUnitGroupHandler(apiV, unitGroupUsecase)
+	handler.NewFormationHandler(apiV, formationUsecase)
+	handler.NewTransferHandler(apiV, transferUsecase)
+	handler.NewChangeRequestHandler(apiV, changeRequestUsecase)
+	handler.NewPasswordHandler(apiV, passwordUsecase)
+	handler.NewTwoFactorHandler(apiV, twoFactorUsecase)
+	handler.NewJWKSHandler(&api.Router.RouterGroup)
 
 	apiV.GET("/ping", HandlePing)
 	// ========================== Start HTTP API =========================
@@ -121,6 +177,15 @@ func service_init() (config.Config, error) {
 	}
 
 	// jwt configuration
-	utils.JwtInit(envload.JwtSecret, envload.RefreshSecret)
+	err = utils.JwtInit(envload.JwtSecret, envload.RefreshSecret, envload.JwtKeyFiles, envload.JwtSigningKid)
+	if err != nil {
+		return config.Config{}, fmt.Errorf("[Error] initializing JWT configuration : %v", err)
+	}
+
+	// card token configuration
+	err = utils.CardInit(envload.CardSecret)
+	if err != nil {
+		return config.Config{}, fmt.Errorf("[Error] initializing card token configuration : %v", err)
+	}
 	return envload, nil
 }
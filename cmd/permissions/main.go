@@ -0,0 +1,14 @@
+// Command permissions menulis seed katalog permission dari internal/authorization/catalogue.go:
+//
+//	go run ./cmd/permissions > documents/emploman-permissions.sql
+package main
+
+import (
+	"fmt"
+
+	"github.com/achmadnr21/emploman/internal/authorization"
+)
+
+func main() {
+	fmt.Print(authorization.CatalogueSQL())
+}
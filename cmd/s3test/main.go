@@ -144,6 +144,8 @@ func serviceInit() (config.Config, error) {
 		return config.Config{}, fmt.Errorf("[Error] S3 init: %v", err)
 	}
 
-	utils.JwtInit(envload.JwtSecret, envload.RefreshSecret)
+	if err := utils.JwtInit(envload.JwtSecret, envload.RefreshSecret, envload.JwtKeyFiles, envload.JwtSigningKid); err != nil {
+		return config.Config{}, fmt.Errorf("[Error] JWT init: %v", err)
+	}
 	return envload, nil
 }
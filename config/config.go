@@ -5,6 +5,7 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
@@ -19,12 +20,18 @@ type Config struct {
 	DbSsl         string
 	JwtSecret     string
 	RefreshSecret string
+	JwtKeyFiles   []string
+	JwtSigningKid string
+	CardSecret    string
 	S3endpoint    string
 	S3accesskey   string
 	S3secretkey   string
 	S3bucket      string
 	S3region      string
 	S3pathstyle   bool
+	NotifierFile  string
+	// TrustedProxies berisi IP/CIDR reverse proxy yang boleh mengisi X-Forwarded-For
+	TrustedProxies []string
 }
 
 func (c *Config) LoadConfig() {
@@ -41,12 +48,30 @@ func (c *Config) LoadConfig() {
 	c.DbSsl = os.Getenv("DB_SSL")
 	c.JwtSecret = os.Getenv("JWT_SECRET")
 	c.RefreshSecret = os.Getenv("REFRESH_SECRET")
+	// file PEM RSA/Ed25519 dipisah koma, kid diambil dari nama file
+	for _, file := range strings.Split(os.Getenv("JWT_KEY_FILES"), ",") {
+		if file = strings.TrimSpace(file); file != "" {
+			c.JwtKeyFiles = append(c.JwtKeyFiles, file)
+		}
+	}
+	c.JwtSigningKid = os.Getenv("JWT_SIGNING_KID")
+	// secret QR kartu pegawai tidak ikut rotasi key JWT
+	c.CardSecret = os.Getenv("CARD_SECRET")
 
 	c.S3endpoint = os.Getenv("S3_ENDPOINT")
 	c.S3accesskey = os.Getenv("S3_ACCESS_KEY_ID")
 	c.S3secretkey = os.Getenv("S3_SECRET_ACCESS_KEY")
 	c.S3bucket = os.Getenv("S3_BUCKET")
 	c.S3region = os.Getenv("S3_REGION")
+	// notifier menulis ke file jika NOTIFIER_FILE diisi, selain itu hanya ke log
+	c.NotifierFile = os.Getenv("NOTIFIER_FILE")
+	// tanpa TRUSTED_PROXIES header X-Forwarded-For diabaikan dan IP diambil dari koneksi langsung
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			c.TrustedProxies = append(c.TrustedProxies, proxy)
+		}
+	}
+
 	c.S3pathstyle, err = strconv.ParseBool(os.Getenv("S3_USE_PATH_STYLE"))
 	if err != nil {
 		c.S3pathstyle = false
@@ -5,9 +5,27 @@ create schema achmadnr;
 create schema auth;
 
 
+drop table achmadnr.employee_recovery_codes;
+drop table achmadnr.employee_two_factors;
+drop table achmadnr.login_attempts;
+drop table achmadnr.password_reset_tokens;
+drop table achmadnr.profile_change_documents;
+drop table achmadnr.profile_change_requests;
+drop table achmadnr.transfer_decisions;
+drop table achmadnr.transfer_requests;
+drop table achmadnr.formations;
+drop table achmadnr.employee_unit_scopes;
+drop table achmadnr.unit_group_members;
+drop table achmadnr.unit_groups;
+drop table achmadnr.audit_log;
+drop table achmadnr.employee_versions;
+drop table achmadnr.employee_status_histories;
+drop table achmadnr.refresh_tokens;
 drop table achmadnr.employee_assignments;
 
 drop table achmadnr.employees;
+drop table achmadnr.role_permissions;
+drop table achmadnr.permissions;
 drop table achmadnr.role_promotions;
 drop table achmadnr.roles;
 drop table achmadnr.religions;
@@ -24,15 +42,7 @@ create table achmadnr.roles(
 	name varchar(255) unique not null,
 	level int not null,
 	description text default 'no desc',
-	can_add_role boolean default false,
-	can_add_employee boolean default false,
-	can_add_unit boolean default false,
-	can_add_position boolean default false,
-	can_add_echelon boolean default false,
-	can_add_religion boolean default false,
-	can_add_grade boolean default false,
-	can_assign_employee_internal boolean default false,
-	can_assign_employee_global boolean default false,
+	require_2fa boolean default false,
 	created_at timestamp default now(),
 	modified_at timestamp default now()
 );
@@ -47,6 +57,26 @@ CREATE TABLE achmadnr.role_promotions (
 	FOREIGN KEY (to_role_id) REFERENCES achmadnr.roles(id) ON DELETE CASCADE
 );
 
+-- katalog permission dengan format <resource>.<aksi>, contoh employee.export atau audit.read.
+-- unit_scoped berarti permission boleh diberikan terbatas pada unit scope pemegang role.
+
+create table achmadnr.permissions(
+	id varchar(64) primary key,
+	description text not null,
+	unit_scoped boolean not null default false,
+	created_at timestamp default now()
+);
+
+create table achmadnr.role_permissions(
+	role_id char(3) not null,
+	permission_id varchar(64) not null,
+	scope varchar(10) not null default 'global' check (scope in ('unit', 'global')),
+	created_at timestamp default now(),
+	primary key (role_id, permission_id),
+	foreign key (role_id) references achmadnr.roles(id) on delete cascade,
+	foreign key (permission_id) references achmadnr.permissions(id) on delete cascade
+);
+
 
 create table achmadnr.religions(
 	id char(3) unique primary key,
@@ -90,7 +120,12 @@ create table achmadnr.employees(
 	religion_id char(3) not null,
 	echelon_id int not null,
 	created_at timestamp default now(),
-	modified_at timestamp default now(),	
+	modified_at timestamp default now(),
+	employment_status varchar(20) not null default 'active' check (employment_status in ('active','on_leave','retired','resigned','dismissed','deceased')),
+	status_effective_date date not null default current_date,
+	status_reason text null,
+	must_change_password boolean not null default false,
+	password_changed_at timestamp null,
 	foreign key (grade_id) references achmadnr.grades(id) on delete set null,
 	foreign key (religion_id) references achmadnr.religions(id) on delete set null,
 	foreign key (echelon_id) references achmadnr.echelons(id) on delete set null,
@@ -101,31 +136,299 @@ create table achmadnr.employees(
 
 create table achmadnr.units(
 	id SERIAL primary key,
+	parent_id int null, -- null berarti unit teratas
 	name varchar(255) unique not null,
 	address text not null,
 	description text default 'no desc',
 	created_at timestamp default now(),
-	modified_at timestamp default now()
+	modified_at timestamp default now(),
+	check (parent_id is null or parent_id <> id),
+	foreign key (parent_id) references achmadnr.units(id) on delete restrict
 );
+create index idx_units_parent on achmadnr.units(parent_id);
 
 create table achmadnr.positions(
 	id SERIAL primary key,
 	name varchar(255) unique not null,
+	is_head boolean default false, -- pemegang posisi ini adalah kepala unit
 	created_at timestamp default now(),
 	modified_at timestamp default now()
 );
 
+-- satu baris untuk setiap periode penempatan, periode aktif jika hari ini berada di antara start_date dan end_date.
+-- assignment_type primary adalah jabatan definitif, acting (Plt/Plh) dan additional (tugas tambahan) boleh dirangkap
+-- database yang masih memakai kolom is_active/assigned_at dimigrasi dengan emploman-migration-assignment-periods.sql
+
+create extension if not exists btree_gist;
+
 create table achmadnr.employee_assignments(
+	id SERIAL primary key,
 	employee_id uuid not null,
 	unit_id int not null,
 	position_id int not null,
-	is_active boolean not null,
-	assigned_at timestamp default now(),
+	start_date date not null default current_date,
+	end_date date null,
+	decree_number varchar(100) not null, -- nomor SK
+	reason varchar(20) not null check (reason in ('mutasi','promotion','secondment')),
+	assignment_type varchar(20) not null default 'primary' check (assignment_type in ('primary','acting','additional')),
 	created_at timestamp default now(),
 	modified_at timestamp default now(),
-	primary key(employee_id, position_id, unit_id),
+	check (end_date is null or end_date >= start_date),
+	-- Plt/Plh wajib memiliki tanggal berakhir
+	check (assignment_type <> 'acting' or end_date is not null),
+	-- periode jabatan definitif satu employee tidak boleh beririsan
+	exclude using gist (employee_id with =, daterange(start_date, end_date, '[]') with &&) where (assignment_type = 'primary'),
 	foreign key(employee_id) references achmadnr.employees(id),
 	foreign key(unit_id) references achmadnr.units(id),
 	foreign key(position_id) references achmadnr.positions(id)
 );
+create index idx_employee_assignments_unit on achmadnr.employee_assignments(unit_id, end_date);
+
+-- refresh token (disimpan dalam bentuk hash sha256), two_factor_verified menandai sesi yang login dengan kode 2FA
+-- migrasi database lama: documents/emploman-migration-refresh-token-2fa.sql
+
+create table achmadnr.refresh_tokens(
+	id varchar(64) primary key,
+	family_id varchar(64) not null,
+	employee_id uuid not null,
+	token_hash char(64) not null,
+	two_factor_verified boolean not null default false,
+	expires_at timestamp not null,
+	revoked_at timestamp null,
+	replaced_by varchar(64) null,
+	created_at timestamp default now(),
+	foreign key(employee_id) references achmadnr.employees(id) on delete cascade
+);
+create index idx_refresh_tokens_family on achmadnr.refresh_tokens(family_id);
+create index idx_refresh_tokens_employee on achmadnr.refresh_tokens(employee_id);
+
+-- riwayat perubahan employment status
+
+create table achmadnr.employee_status_histories(
+	id SERIAL primary key,
+	employee_id uuid not null,
+	from_status varchar(20) not null,
+	to_status varchar(20) not null,
+	effective_date date not null,
+	reason text not null,
+	changed_by uuid not null,
+	created_at timestamp default now(),
+	foreign key(employee_id) references achmadnr.employees(id),
+	foreign key(changed_by) references achmadnr.employees(id)
+);
+create index idx_employee_status_histories_employee on achmadnr.employee_status_histories(employee_id);
+
+-- audit log perubahan data (before/after hanya berisi field yang berubah)
+
+create table achmadnr.audit_log(
+	id bigserial primary key,
+	actor_id uuid not null,
+	action varchar(50) not null,
+	entity_type varchar(50) not null,
+	entity_id varchar(255) not null,
+	before jsonb null,
+	after jsonb null,
+	ip_address varchar(64) null,
+	user_agent text null,
+	created_at timestamp default now()
+);
+create index idx_audit_log_entity on achmadnr.audit_log(entity_type, entity_id);
+create index idx_audit_log_actor on achmadnr.audit_log(actor_id);
+create index idx_audit_log_created_at on achmadnr.audit_log(created_at);
+
+-- versi employee, satu baris untuk setiap perubahan (snapshot tanpa password)
+-- employee yang sudah ada sebelum tabel ini dibuat diberi versi awal lewat emploman-migration-employee-versions.sql
+
+create table achmadnr.employee_versions(
+	id bigserial primary key,
+	employee_id uuid not null,
+	version int not null,
+	changed_by uuid null, -- null untuk snapshot awal sebelum history dicatat
+	valid_from timestamp not null,
+	role_id char(3) not null,
+	nip varchar(20) not null,
+	full_name varchar(255) not null,
+	place_of_birth varchar(100) not null,
+	date_of_birth date not null,
+	gender char(1),
+	phone_number varchar(20) not null,
+	photo_url text,
+	address text not null,
+	npwp varchar(25) null,
+	grade_id int not null,
+	religion_id char(3) not null,
+	echelon_id int not null,
+	employment_status varchar(20) not null,
+	status_effective_date date not null,
+	status_reason text null,
+	created_at timestamp,
+	unique(employee_id, version),
+	foreign key(employee_id) references achmadnr.employees(id) on delete cascade,
+	foreign key(changed_by) references achmadnr.employees(id)
+);
+create index idx_employee_versions_valid_from on achmadnr.employee_versions(employee_id, valid_from);
+
+-- unit group, kumpulan unit bernama yang dapat diberikan sebagai unit scope sekaligus
+-- migrasi database lama: documents/emploman-migration-unit-groups.sql
+
+create table achmadnr.unit_groups(
+	id SERIAL primary key,
+	name varchar(255) unique not null,
+	description text null,
+	created_at timestamp default now(),
+	modified_at timestamp default now()
+);
+
+create table achmadnr.unit_group_members(
+	group_id int not null,
+	unit_id int not null,
+	primary key(group_id, unit_id),
+	foreign key(group_id) references achmadnr.unit_groups(id) on delete cascade,
+	foreign key(unit_id) references achmadnr.units(id) on delete cascade
+);
+
+-- unit scope tambahan untuk pemegang role dengan permission ber-scope unit, include_subunits mencakup seluruh sub unit.
+-- scope diberikan atas satu unit atau satu unit group, grup yang masih dipakai tidak dapat dihapus
 
+create table achmadnr.employee_unit_scopes(
+	id SERIAL primary key,
+	employee_id uuid not null,
+	unit_id int null,
+	unit_group_id int null,
+	include_subunits boolean not null default false,
+	granted_by uuid not null,
+	created_at timestamp default now(),
+	check ((unit_id is null) <> (unit_group_id is null)),
+	unique(employee_id, unit_id),
+	unique(employee_id, unit_group_id),
+	foreign key(employee_id) references achmadnr.employees(id) on delete cascade,
+	foreign key(unit_id) references achmadnr.units(id) on delete cascade,
+	foreign key(unit_group_id) references achmadnr.unit_groups(id)
+);
+
+-- formasi, jumlah kursi setiap posisi pada unit
+
+create table achmadnr.formations(
+	id SERIAL primary key,
+	unit_id int not null,
+	position_id int not null,
+	quota int not null check (quota >= 0),
+	created_at timestamp default now(),
+	modified_at timestamp default now(),
+	unique(unit_id, position_id),
+	foreign key(unit_id) references achmadnr.units(id) on delete cascade,
+	foreign key(position_id) references achmadnr.positions(id) on delete cascade
+);
+
+-- usulan mutasi: draft -> proposed (kepala unit asal) -> accepted (kepala unit tujuan) -> executed (kepegawaian)
+
+create table achmadnr.transfer_requests(
+	id SERIAL primary key,
+	employee_id uuid not null,
+	from_unit_id int not null,
+	to_unit_id int not null,
+	to_position_id int not null,
+	start_date date not null,
+	reason varchar(20) not null check (reason in ('mutasi','promotion','secondment')),
+	decree_number varchar(100) null, -- nomor SK, diisi saat eksekusi
+	assignment_id int null, -- assignment hasil eksekusi
+	status varchar(20) not null default 'draft' check (status in ('draft','proposed','accepted','rejected','executed')),
+	created_by uuid not null,
+	created_at timestamp default now(),
+	modified_at timestamp default now(),
+	check (from_unit_id <> to_unit_id),
+	foreign key(employee_id) references achmadnr.employees(id) on delete cascade,
+	foreign key(from_unit_id) references achmadnr.units(id),
+	foreign key(to_unit_id) references achmadnr.units(id),
+	foreign key(to_position_id) references achmadnr.positions(id),
+	foreign key(assignment_id) references achmadnr.employee_assignments(id)
+);
+-- satu employee hanya boleh memiliki satu usulan mutasi yang masih berjalan
+create unique index idx_transfer_requests_open on achmadnr.transfer_requests(employee_id) where status in ('draft','proposed','accepted');
+create index idx_transfer_requests_status on achmadnr.transfer_requests(status);
+
+create table achmadnr.transfer_decisions(
+	id SERIAL primary key,
+	transfer_id int not null,
+	from_status varchar(20) null, -- null saat transfer dibuat
+	to_status varchar(20) not null,
+	actor_id uuid not null,
+	comment text not null,
+	created_at timestamp default now(),
+	foreign key(transfer_id) references achmadnr.transfer_requests(id) on delete cascade
+);
+create index idx_transfer_decisions_transfer on achmadnr.transfer_decisions(transfer_id);
+
+-- pengajuan perubahan data diri oleh employee, disetujui kepegawaian (can_add_employee)
+
+create table achmadnr.profile_change_requests(
+	id SERIAL primary key,
+	employee_id uuid not null,
+	changes jsonb not null, -- field yang diajukan, lihat domain.ProfileChanges
+	note text null,
+	status varchar(20) not null default 'pending' check (status in ('pending','approved','rejected')),
+	reviewed_by uuid null,
+	review_comment text null,
+	reviewed_at timestamp null,
+	created_at timestamp default now(),
+	foreign key(employee_id) references achmadnr.employees(id) on delete cascade,
+	foreign key(reviewed_by) references achmadnr.employees(id)
+);
+-- satu employee hanya boleh memiliki satu pengajuan yang menunggu review
+create unique index idx_profile_change_requests_pending on achmadnr.profile_change_requests(employee_id) where status = 'pending';
+create index idx_profile_change_requests_status on achmadnr.profile_change_requests(status, created_at);
+
+create table achmadnr.profile_change_documents(
+	id SERIAL primary key,
+	request_id int not null,
+	file_name varchar(255) not null,
+	url text not null,
+	content_type varchar(100) not null,
+	created_at timestamp default now(),
+	foreign key(request_id) references achmadnr.profile_change_requests(id) on delete cascade
+);
+
+-- token lupa password, hanya hash yang disimpan dan setiap token hanya bisa dipakai sekali
+
+create table achmadnr.password_reset_tokens(
+	id SERIAL primary key,
+	employee_id uuid not null,
+	token_hash char(64) unique not null,
+	expires_at timestamp not null,
+	used_at timestamp null,
+	created_at timestamp default now(),
+	foreign key(employee_id) references achmadnr.employees(id) on delete cascade
+);
+create index idx_password_reset_tokens_employee on achmadnr.password_reset_tokens(employee_id) where used_at is null;
+
+-- kegagalan login berturut-turut per nip ("nip:<nip>") dan per ip ("ip:<ip>")
+-- key nip sengaja tidak memakai foreign key agar nip yang tidak terdaftar ikut dibatasi
+
+create table achmadnr.login_attempts(
+	key varchar(100) primary key,
+	failed_count int not null default 0,
+	last_failed_at timestamp not null default now(),
+	locked_until timestamp null
+);
+
+-- two-factor authentication (TOTP RFC 6238), enabled false berarti enrolment belum dikonfirmasi
+
+create table achmadnr.employee_two_factors(
+	employee_id uuid primary key,
+	secret varchar(64) not null, -- base32
+	enabled boolean not null default false,
+	last_used_step bigint not null default 0, -- kode dengan time step <= nilai ini ditolak (anti replay)
+	confirmed_at timestamp null,
+	created_at timestamp default now(),
+	foreign key(employee_id) references achmadnr.employees(id) on delete cascade
+);
+
+create table achmadnr.employee_recovery_codes(
+	id SERIAL primary key,
+	employee_id uuid not null,
+	code_hash char(64) not null,
+	used_at timestamp null,
+	created_at timestamp default now(),
+	foreign key(employee_id) references achmadnr.employees(id) on delete cascade
+);
+create index idx_employee_recovery_codes_employee on achmadnr.employee_recovery_codes(employee_id) where used_at is null;
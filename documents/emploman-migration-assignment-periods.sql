@@ -0,0 +1,69 @@
+-- Migrasi achmadnr.employee_assignments dari kolom is_active/assigned_at ke periode start_date/end_date.
+-- Jalankan sekali pada database yang dibuat sebelum assignment dicatat per periode, data assignment lama tetap
+-- tersimpan sebagai riwayat:
+-- - start_date diambil dari assigned_at
+-- - assignment tidak aktif ditutup pada tanggal terakhir diubah
+-- - periode yang saling tumpang tindih ditutup sehari sebelum periode berikutnya dimulai
+-- - decree_number assignment lama diisi '-' karena nomor SK belum pernah dicatat
+
+SET TIME ZONE 'Asia/Jakarta';
+
+begin;
+
+create extension if not exists btree_gist;
+
+alter table achmadnr.employee_assignments drop constraint if exists employee_assignments_pkey;
+alter table achmadnr.employee_assignments add column id serial;
+alter table achmadnr.employee_assignments add primary key (id);
+
+alter table achmadnr.employee_assignments
+	add column start_date date,
+	add column end_date date null,
+	add column decree_number varchar(100) not null default '-',
+	add column reason varchar(20) not null default 'mutasi',
+	add column assignment_type varchar(20) not null default 'primary';
+
+update achmadnr.employee_assignments
+set start_date = coalesce(assigned_at, created_at, now())::date;
+
+update achmadnr.employee_assignments
+set end_date = greatest(start_date, coalesce(modified_at, now())::date)
+where is_active = false;
+
+-- sebelumnya satu employee bisa memiliki beberapa assignment aktif. Periode ditutup sehari sebelum periode
+-- berikutnya dimulai, periode yang dimulai di hari yang sama dengan periode berikutnya dicatat sebagai tugas
+-- tambahan satu hari agar tidak melanggar exclusion constraint jabatan definitif.
+with ordered as (
+	select id, lead(start_date) over (partition by employee_id order by start_date, id) as next_start
+	from achmadnr.employee_assignments
+)
+update achmadnr.employee_assignments ea
+set end_date = o.next_start - 1
+from ordered o
+where ea.id = o.id and o.next_start > ea.start_date
+	and (ea.end_date is null or ea.end_date >= o.next_start);
+
+with ordered as (
+	select id, lead(start_date) over (partition by employee_id order by start_date, id) as next_start
+	from achmadnr.employee_assignments
+)
+update achmadnr.employee_assignments ea
+set assignment_type = 'additional', end_date = ea.start_date
+from ordered o
+where ea.id = o.id and o.next_start = ea.start_date;
+
+alter table achmadnr.employee_assignments
+	alter column start_date set not null,
+	alter column start_date set default current_date,
+	alter column decree_number drop default,
+	drop column is_active,
+	drop column assigned_at,
+	add check (reason in ('mutasi','promotion','secondment')),
+	add check (assignment_type in ('primary','acting','additional')),
+	add check (end_date is null or end_date >= start_date),
+	add check (assignment_type <> 'acting' or end_date is not null),
+	add exclude using gist (employee_id with =, daterange(start_date, end_date, '[]') with &&) where (assignment_type = 'primary');
+
+create index if not exists idx_employee_assignments_unit on achmadnr.employee_assignments(unit_id, end_date);
+
+commit;
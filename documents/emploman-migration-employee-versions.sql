@@ -0,0 +1,27 @@
+-- Backfill versi awal achmadnr.employee_versions untuk employee yang dibuat sebelum history dicatat.
+-- Versi 1 berlaku sejak employee dibuat (created_at) sehingga pencarian as_of di antara pembuatan dan
+-- perubahan pertama tetap menemukan data. Aman dijalankan ulang.
+
+SET TIME ZONE 'Asia/Jakarta';
+
+begin;
+
+-- snapshot awal yang sudah terlanjur dibuat dengan valid_from = modified_at
+update achmadnr.employee_versions v
+set valid_from = coalesce(e.created_at, v.valid_from)
+from achmadnr.employees e
+where v.employee_id = e.id and v.version = 1 and v.changed_by is null
+	and v.valid_from > e.created_at;
+
+insert into achmadnr.employee_versions (employee_id, version, changed_by, valid_from,
+	role_id, nip, full_name, place_of_birth, date_of_birth, gender,
+	phone_number, photo_url, address, npwp, grade_id, religion_id, echelon_id,
+	employment_status, status_effective_date, status_reason, created_at)
+select id, 1, null, coalesce(created_at, modified_at),
+	role_id, nip, full_name, place_of_birth, date_of_birth, gender,
+	phone_number, photo_url, address, npwp, grade_id, religion_id, echelon_id,
+	employment_status, status_effective_date, status_reason, created_at
+from achmadnr.employees e
+where not exists (select 1 from achmadnr.employee_versions v where v.employee_id = e.id);
+
+commit;
@@ -0,0 +1,11 @@
+-- Migrasi achmadnr.refresh_tokens agar refresh token mencatat apakah sesi login dengan kode 2FA.
+-- Jalankan sekali pada database yang dibuat sebelum kolom two_factor_verified ada. Sesi lama tidak dapat
+-- dibuktikan login dengan 2FA, sehingga pemegang role yang mewajibkan 2FA harus login ulang pada refresh berikutnya.
+
+SET TIME ZONE 'Asia/Jakarta';
+
+begin;
+
+alter table achmadnr.refresh_tokens add column if not exists two_factor_verified boolean not null default false;
+
+commit;
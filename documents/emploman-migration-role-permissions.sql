@@ -0,0 +1,85 @@
+-- Migrasi kolom can_* pada achmadnr.roles ke katalog permission dan achmadnr.role_permissions.
+-- Jalankan sekali pada database yang dibuat sebelum tabel permission ada. Hasil konversi sama dengan
+-- wewenang sebelumnya: assign employee internal menjadi scope unit, assign employee global menjadi scope global.
+
+SET TIME ZONE 'Asia/Jakarta';
+
+begin;
+
+create table if not exists achmadnr.permissions(
+	id varchar(64) primary key,
+	description text not null,
+	unit_scoped boolean not null default false,
+	created_at timestamp default now()
+);
+
+create table if not exists achmadnr.role_permissions(
+	role_id char(3) not null,
+	permission_id varchar(64) not null,
+	scope varchar(10) not null default 'global' check (scope in ('unit', 'global')),
+	created_at timestamp default now(),
+	primary key (role_id, permission_id),
+	foreign key (role_id) references achmadnr.roles(id) on delete cascade,
+	foreign key (permission_id) references achmadnr.permissions(id) on delete cascade
+);
+
+-- katalog permission dibuat dari internal/authorization/catalogue.go, lihat emploman-permissions.sql
+\ir emploman-permissions.sql
+
+-- permission yang hanya bergantung pada satu kolom boolean
+insert into achmadnr.role_permissions(role_id, permission_id, scope)
+select r.id, m.permission_id, 'global'
+from achmadnr.roles r
+join (values
+	('employee.create', 'can_add_employee'),
+	('employee.photo', 'can_add_employee'),
+	('employee.status', 'can_add_employee'),
+	('employee.credential', 'can_add_employee'),
+	('change_request.review', 'can_add_employee'),
+	('employee.two_factor_reset', 'can_add_role'),
+	('unit_scope.read', 'can_add_role'),
+	('unit_scope.manage', 'can_add_role'),
+	('role.manage', 'can_add_role'),
+	('unit.manage', 'can_add_unit'),
+	('position.manage', 'can_add_position'),
+	('echelon.manage', 'can_add_echelon'),
+	('religion.manage', 'can_add_religion'),
+	('grade.manage', 'can_add_grade'),
+	('transfer.review', 'can_assign_employee_global'),
+	('audit.read', 'can_view_audit')
+) as m(permission_id, flag) on (to_jsonb(r) ->> m.flag)::boolean
+on conflict (role_id, permission_id) do nothing;
+
+-- data employee: pengelola employee dan assign global untuk seluruh unit, assign internal untuk unit scope
+insert into achmadnr.role_permissions(role_id, permission_id, scope)
+select r.id, p.id, case when r.can_add_employee or r.can_assign_employee_global then 'global' else 'unit' end
+from achmadnr.roles r
+cross join achmadnr.permissions p
+where p.id in ('employee.read', 'employee.update', 'employee.export')
+and (r.can_add_employee or r.can_assign_employee_global or r.can_assign_employee_internal)
+on conflict (role_id, permission_id) do nothing;
+
+-- assignment
+insert into achmadnr.role_permissions(role_id, permission_id, scope)
+select r.id, p.id, case when r.can_assign_employee_global then 'global' else 'unit' end
+from achmadnr.roles r
+cross join achmadnr.permissions p
+where p.id in ('assignment.read', 'assignment.manage')
+and (r.can_assign_employee_global or r.can_assign_employee_internal)
+on conflict (role_id, permission_id) do nothing;
+
+alter table achmadnr.roles
+	drop column can_add_role,
+	drop column can_add_employee,
+	drop column can_add_unit,
+	drop column can_add_position,
+	drop column can_add_echelon,
+	drop column can_add_religion,
+	drop column can_add_grade,
+	drop column can_assign_employee_internal,
+	drop column can_assign_employee_global,
+	drop column can_view_audit;
+
+commit;
+
+select * from achmadnr.role_permissions order by role_id, permission_id;
@@ -0,0 +1,31 @@
+-- Migrasi achmadnr.employee_unit_scopes agar unit scope dapat diberikan atas unit group.
+-- Jalankan sekali pada database yang dibuat sebelum unit group tersedia, grant yang sudah ada tetap berlaku
+-- sebagai grant atas satu unit.
+
+SET TIME ZONE 'Asia/Jakarta';
+
+begin;
+
+create table if not exists achmadnr.unit_groups(
+	id SERIAL primary key,
+	name varchar(255) unique not null,
+	description text null,
+	created_at timestamp default now(),
+	modified_at timestamp default now()
+);
+
+create table if not exists achmadnr.unit_group_members(
+	group_id int not null,
+	unit_id int not null,
+	primary key(group_id, unit_id),
+	foreign key(group_id) references achmadnr.unit_groups(id) on delete cascade,
+	foreign key(unit_id) references achmadnr.units(id) on delete cascade
+);
+
+alter table achmadnr.employee_unit_scopes
+	alter column unit_id drop not null,
+	add column unit_group_id int null references achmadnr.unit_groups(id),
+	add check ((unit_id is null) <> (unit_group_id is null)),
+	add unique(employee_id, unit_group_id);
+
+commit;
@@ -0,0 +1,30 @@
+-- Dibuat dari catalogue pada internal/authorization/catalogue.go dengan `go run ./cmd/permissions`, jangan diubah manual.
+-- Dijalankan oleh emploman-setup.sql dan migrasi permission, aman dijalankan ulang setelah catalogue berubah.
+
+insert into achmadnr.permissions(id, description, unit_scoped)
+values
+('employee.read', 'View employee data', true),
+('employee.create', 'Add and import employees', false),
+('employee.update', 'Update employee data', true),
+('employee.photo', 'Upload photos of other employees', true),
+('employee.status', 'Change employment status', false),
+('employee.export', 'Export and print employee data', true),
+('employee.credential', 'Reset passwords and unlock accounts', false),
+('employee.two_factor_reset', 'Reset two-factor authentication of other employees', false),
+('change_request.review', 'Review profile change requests', false),
+('assignment.read', 'View assignments of other employees', true),
+('assignment.manage', 'Assign employees and end assignments', true),
+('transfer.review', 'Decide and execute transfer requests as HR', false),
+('unit.manage', 'Manage units and formations', false),
+('unit_scope.read', 'View unit scopes of other employees', false),
+('unit_scope.manage', 'Grant and revoke unit scopes and unit groups', false),
+('position.manage', 'Manage positions', false),
+('echelon.manage', 'Manage echelons', false),
+('religion.manage', 'Manage religions', false),
+('grade.manage', 'Manage grades', false),
+('role.manage', 'Manage roles, role promotions and role permissions', false),
+('audit.read', 'View audit log', false)
+on conflict (id) do update set description = excluded.description, unit_scoped = excluded.unit_scoped;
+
+-- permission yang sudah tidak ada pada catalogue ikut dicabut dari seluruh role
+delete from achmadnr.permissions where id not in ('employee.read', 'employee.create', 'employee.update', 'employee.photo', 'employee.status', 'employee.export', 'employee.credential', 'employee.two_factor_reset', 'change_request.review', 'assignment.read', 'assignment.manage', 'transfer.review', 'unit.manage', 'unit_scope.read', 'unit_scope.manage', 'position.manage', 'echelon.manage', 'religion.manage', 'grade.manage', 'role.manage', 'audit.read');
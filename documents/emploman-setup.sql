@@ -2,16 +2,54 @@
 --SETUP INSERT DATA PADA TABLE
 
 --TABEL ROLE
-insert into achmadnr.roles(id, name, level, can_add_role, can_add_employee, can_add_unit, can_add_position, can_add_echelon, can_add_religion, can_add_grade, can_assign_employee_internal, can_assign_employee_global)
+-- role dengan wewenang luas (SUP, ADM, HRD) wajib login dengan 2FA
+insert into achmadnr.roles(id, name, level, require_2fa)
 values
-('SUP', 'SUPERADMIN', 5, true, true, true, true, true, true, true, true, true), -- Super user (Developer)
-('ADM', 'ADMIN', 4, true, true, true, true, true, true, true, true, false),  -- Admin dapat menambah semua kecuali assign employee global
-('MGR', 'MANAGER', 3, false, false, true, true, false, false, false, true, false),  -- Manager dapat menambah unit dan posisi serta assign employee internal
-('HRD', 'HUMAN RESOURCES', 2, false, true, false, false, false, false, false, true, true),  -- HRD dapat menambah user, assign employee internal dan global
-('USR', 'USER', 1, false, false, false, false, false, false, false, false, false);  -- User hanya bisa mengakses data tanpa kemampuan menambah apa pun
+('SUP', 'SUPERADMIN', 5, true), -- Super user (Developer)
+('ADM', 'ADMIN', 4, true),  -- Admin dapat mengelola semua kecuali assign employee global dan keputusan mutasi
+('MGR', 'MANAGER', 3, false),  -- Manager dapat mengelola unit dan posisi serta assign employee internal
+('HRD', 'HUMAN RESOURCES', 2, true),  -- HRD dapat mengelola employee, assign employee internal dan global
+('USR', 'USER', 1, false);  -- User hanya bisa mengakses data miliknya sendiri
 
 select * from achmadnr.roles;
 
+--TABEL PERMISSION
+-- katalog permission dibuat dari internal/authorization/catalogue.go, lihat emploman-permissions.sql
+\ir emploman-permissions.sql
+
+-- permission role, scope unit berarti terbatas pada unit scope pemegang role
+insert into achmadnr.role_permissions(role_id, permission_id, scope)
+select 'SUP', id, 'global' from achmadnr.permissions;
+
+insert into achmadnr.role_permissions(role_id, permission_id, scope)
+select 'ADM', id, 'global' from achmadnr.permissions
+where id not in ('assignment.read', 'assignment.manage', 'transfer.review');
+
+insert into achmadnr.role_permissions(role_id, permission_id, scope)
+values
+('ADM', 'assignment.read', 'unit'),
+('ADM', 'assignment.manage', 'unit'),
+('MGR', 'employee.read', 'unit'),
+('MGR', 'employee.update', 'unit'),
+('MGR', 'employee.export', 'unit'),
+('MGR', 'assignment.read', 'unit'),
+('MGR', 'assignment.manage', 'unit'),
+('MGR', 'unit.manage', 'global'),
+('MGR', 'position.manage', 'global'),
+('HRD', 'employee.read', 'global'),
+('HRD', 'employee.create', 'global'),
+('HRD', 'employee.update', 'global'),
+('HRD', 'employee.photo', 'global'),
+('HRD', 'employee.status', 'global'),
+('HRD', 'employee.export', 'global'),
+('HRD', 'employee.credential', 'global'),
+('HRD', 'change_request.review', 'global'),
+('HRD', 'assignment.read', 'global'),
+('HRD', 'assignment.manage', 'global'),
+('HRD', 'transfer.review', 'global');
+
+select * from achmadnr.role_permissions;
+
 
 insert into achmadnr.role_promotions(promoter_role_id, from_role_id, to_role_id)
 values
@@ -126,13 +164,13 @@ insert into achmadnr.units(name, address)
 values ('Kantor Pusat', 'Jl. Panglima Sudirman, Jakarta Pusat');
 select * from achmadnr.units;
 
-insert into achmadnr.positions(name)
+insert into achmadnr.positions(name, is_head)
 values
-('Kepala Sekretariat Utama'),
-('Penyusun Laporan Keuangan'),
-('Surveyor Pemetaan Pertama'),
-('Analis Data Survei dan Pemetaan'),
-('Perancang Per-UU-an Utama IV/e');
+('Kepala Sekretariat Utama', true),
+('Penyusun Laporan Keuangan', false),
+('Surveyor Pemetaan Pertama', false),
+('Analis Data Survei dan Pemetaan', false),
+('Perancang Per-UU-an Utama IV/e', false);
 
 select * from achmadnr.positions;
 
@@ -143,12 +181,14 @@ select * from achmadnr.positions;
 select * from achmadnr.employee_assignments;
 
 insert into achmadnr.employee_assignments(
-	employee_id, unit_id, position_id, is_active
+	employee_id, unit_id, position_id, start_date, decree_number, reason
 ) values
 ('fd0336b6-b32d-4b42-8e8a-7f2b2d7a779c',
 	1,
 	2,
-	true
+	current_date,
+	'SK-001/2025',
+	'mutasi'
 );
 select * from achmadnr.employee_assignments;
 
@@ -194,7 +234,7 @@ ar.name,
 COALESCE(au.name, '-') AS unit_name,
 ae.phone_number,  COALESCE(ae.npwp, '-') as npwp
 from achmadnr.employees ae
-left join achmadnr.employee_assignments aea on ae.id = aea.employee_id
+left join achmadnr.employee_assignments aea on ae.id = aea.employee_id and aea.end_date is null
 left join achmadnr.units au on aea.unit_id = au.id
 left join achmadnr.positions ap on aea.position_id = ap.id
 left join achmadnr.grades ag on ae.grade_id = ag.id
@@ -0,0 +1,101 @@
+package authorization
+
+import (
+	"github.com/achmadnr21/emploman/internal/domain"
+	"github.com/achmadnr21/emploman/internal/utils"
+)
+
+// UnitResolver menyediakan unit scope employee, diimplementasikan usecase_scope.Resolver
+type UnitResolver interface {
+	Units(employeeID string) (*domain.UnitScope, error)
+	EmployeeInScope(scope *domain.UnitScope, employeeID string) bool
+}
+
+// Authorizer menjalankan Can dengan permission terbaru dari role principal dan unit scope dari database
+type Authorizer struct {
+	roleRepo domain.RoleInterface
+	units    UnitResolver
+}
+
+func NewAuthorizer(roleRepo domain.RoleInterface, units UnitResolver) *Authorizer {
+	return &Authorizer{
+		roleRepo: roleRepo,
+		units:    units,
+	}
+}
+
+// Authorize mengembalikan UnauthorizedError jika principal tidak boleh menjalankan action terhadap resource
+func (a *Authorizer) Authorize(principal *domain.Principal, action Action, resource Resource) error {
+	current, err := a.refresh(principal)
+	if err != nil {
+		return err
+	}
+	scope := ScopeFor(current, action)
+	if scope == ScopeUnit && !resource.isCollection() && resource.EmployeeID != current.UserID {
+		units, err := a.units.Units(current.UserID)
+		if err != nil {
+			return err
+		}
+		if resource.EmployeeID != "" {
+			resource.InUnitScope = a.units.EmployeeInScope(units, resource.EmployeeID)
+		} else {
+			resource.InUnitScope = units.Contains(resource.UnitID)
+		}
+	}
+	if Can(current, action, resource) {
+		return nil
+	}
+	if scope == ScopeUnit && resource.EmployeeID != "" {
+		return &utils.UnauthorizedError{Message: "employee is outside your unit scope"}
+	}
+	if scope == ScopeUnit {
+		return &utils.UnauthorizedError{Message: "unit is outside your unit scope"}
+	}
+	return &utils.UnauthorizedError{Message: "not authorized to " + action.description()}
+}
+
+// Scope mengembalikan scope principal untuk action berdasarkan permission role saat ini,
+// dipakai jika usecase hanya perlu membedakan jenis akses tanpa resource tertentu
+func (a *Authorizer) Scope(principal *domain.Principal, action Action) (Scope, error) {
+	current, err := a.refresh(principal)
+	if err != nil {
+		return ScopeNone, err
+	}
+	return ScopeFor(current, action), nil
+}
+
+// UnitScope mengembalikan unit yang boleh diakses principal untuk action pada koleksi,
+// dipakai untuk menyaring hasil query. Principal tanpa ScopeUnit atau ScopeGlobal ditolak.
+func (a *Authorizer) UnitScope(principal *domain.Principal, action Action) (*domain.UnitScope, error) {
+	current, err := a.refresh(principal)
+	if err != nil {
+		return nil, err
+	}
+	switch ScopeFor(current, action) {
+	case ScopeGlobal:
+		return &domain.UnitScope{All: true}, nil
+	case ScopeUnit:
+		return a.units.Units(current.UserID)
+	default:
+		return nil, &utils.UnauthorizedError{Message: "not authorized to " + action.description()}
+	}
+}
+
+// refresh mengganti role dan permission dari token dengan role employee saat ini (lewat cache role),
+// sehingga promosi, penurunan role dan perubahan permission langsung berlaku tanpa menunggu token diperbarui
+func (a *Authorizer) refresh(principal *domain.Principal) (*domain.Principal, error) {
+	if principal == nil {
+		return nil, &utils.UnauthorizedError{Message: "not authenticated"}
+	}
+	if principal.Purpose != "" {
+		return principal, nil
+	}
+	role, err := utils.PrincipalRole(a.roleRepo, principal)
+	if err != nil {
+		return nil, &utils.UnauthorizedError{Message: "Failed to get user role"}
+	}
+	current := *principal
+	current.RoleID = role.ID
+	current.Permissions = role.PermissionSet()
+	return &current, nil
+}
@@ -0,0 +1,104 @@
+package authorization
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/achmadnr21/emploman/internal/domain"
+	"github.com/achmadnr21/emploman/internal/utils"
+)
+
+// fakeRoleRepo hanya mengimplementasikan FindByUserID yang dipakai Authorizer, users berisi role_id tiap employee
+type fakeRoleRepo struct {
+	domain.RoleInterface
+	roles map[string]domain.Role
+	users map[string]string
+}
+
+func (r *fakeRoleRepo) FindByUserID(id string) (*domain.Role, error) {
+	role, ok := r.roles[r.users[id]]
+	if !ok {
+		return nil, errors.New("role not found")
+	}
+	return &role, nil
+}
+
+// fakeUnits memberi unit scope tetap per employee dan unit assignment tiap employee
+type fakeUnits struct {
+	scopes      map[string][]int
+	assignments map[string]int
+}
+
+func (u *fakeUnits) Units(employeeID string) (*domain.UnitScope, error) {
+	return &domain.UnitScope{UnitIDs: u.scopes[employeeID]}, nil
+}
+
+func (u *fakeUnits) EmployeeInScope(scope *domain.UnitScope, employeeID string) bool {
+	unitID, ok := u.assignments[employeeID]
+	return ok && scope.Contains(unitID)
+}
+
+func TestAuthorize(t *testing.T) {
+	repo := &fakeRoleRepo{roles: roles, users: map[string]string{}}
+	units := &fakeUnits{
+		scopes:      map[string][]int{"self": {1, 2}},
+		assignments: map[string]int{"inside": 2, "outside": 3},
+	}
+	authz := NewAuthorizer(repo, units)
+
+	// current adalah role_id employee di database, boleh berbeda dengan role di token
+	tests := []struct {
+		name      string
+		principal *domain.Principal
+		current   string
+		action    Action
+		resource  Resource
+		wantErr   string
+	}{
+		{"unit employee inside scope", principalFor("MGR"), "MGR", EmployeeUpdate, Employee("inside"), ""},
+		{"unit employee outside scope", principalFor("MGR"), "MGR", EmployeeUpdate, Employee("outside"), "employee is outside your unit scope"},
+		{"unit unit inside scope", principalFor("MGR"), "MGR", AssignmentManage, Unit(1), ""},
+		{"unit unit outside scope", principalFor("MGR"), "MGR", AssignmentManage, Unit(3), "unit is outside your unit scope"},
+		{"global ignores unit scope", principalFor("HRD"), "HRD", EmployeeUpdate, Employee("outside"), ""},
+		{"missing permission", principalFor("MGR"), "MGR", GradeManage, Resource{}, "not authorized to manage grade"},
+		{"promoted after token issued", principalFor("MGR"), "HRD", TransferReview, Resource{}, ""},
+		{"demoted after token issued", principalFor("HRD"), "USR", EmployeeRead, Resource{}, "not authorized to view employee"},
+		{"unknown role", principalFor("USR"), "XXX", EmployeeRead, Resource{}, "Failed to get user role"},
+		{"nil principal", nil, "", EmployeeRead, Resource{}, "not authenticated"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo.users["self"] = tt.current
+			err := authz.Authorize(tt.principal, tt.action, tt.resource)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Authorize() error = %v, want nil", err)
+				}
+				return
+			}
+			var unauthorized *utils.UnauthorizedError
+			if !errors.As(err, &unauthorized) || unauthorized.Message != tt.wantErr {
+				t.Fatalf("Authorize() error = %v, want UnauthorizedError %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUnitScope(t *testing.T) {
+	repo := &fakeRoleRepo{roles: roles, users: map[string]string{"self": "HRD"}}
+	authz := NewAuthorizer(repo, &fakeUnits{scopes: map[string][]int{"self": {4}}})
+
+	scope, err := authz.UnitScope(principalFor("HRD"), EmployeeRead)
+	if err != nil || !scope.All {
+		t.Errorf("global UnitScope() = %+v, %v, want all units", scope, err)
+	}
+	repo.users["self"] = "MGR"
+	scope, err = authz.UnitScope(principalFor("MGR"), EmployeeRead)
+	if err != nil || scope.All || len(scope.UnitIDs) != 1 || scope.UnitIDs[0] != 4 {
+		t.Errorf("unit UnitScope() = %+v, %v, want unit 4", scope, err)
+	}
+	repo.users["self"] = "USR"
+	if _, err := authz.UnitScope(principalFor("USR"), EmployeeRead); err == nil {
+		t.Error("UnitScope() without permission should fail")
+	}
+}
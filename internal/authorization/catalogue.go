@@ -0,0 +1,110 @@
+package authorization
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/achmadnr21/emploman/internal/domain"
+)
+
+// permission adalah satu entri katalog achmadnr.permissions beserta aturan bawaannya. description dan
+// unitScoped disimpan ke katalog database, rule dipakai saat memeriksa action.
+type permission struct {
+	action      Action
+	description string
+	unitScoped  bool
+	rule
+}
+
+// catalogue adalah satu-satunya sumber katalog permission. Seed database (documents/emploman-permissions.sql)
+// dibuat dari daftar ini dengan `go run ./cmd/permissions` dan dicek terhadap tabel permissions saat startup.
+var catalogue = []permission{
+	{EmployeeRead, "View employee data", true, rule{"view employee", ScopeNone}},
+	{EmployeeCreate, "Add and import employees", false, rule{"add employee", ScopeNone}},
+	{EmployeeUpdate, "Update employee data", true, rule{"update employee", ScopeNone}},
+	{EmployeePhoto, "Upload photos of other employees", true, rule{"upload employee photo", ScopeSelf}},
+	{EmployeeStatus, "Change employment status", false, rule{"change employment status", ScopeNone}},
+	{EmployeeExport, "Export and print employee data", true, rule{"export employee", ScopeNone}},
+	{EmployeeCredential, "Reset passwords and unlock accounts", false, rule{"manage employee credential", ScopeNone}},
+	{EmployeeTwoFactorReset, "Reset two-factor authentication of other employees", false, rule{"reset two-factor authentication", ScopeNone}},
+	{ChangeRequestReview, "Review profile change requests", false, rule{"review change requests", ScopeNone}},
+	{AssignmentRead, "View assignments of other employees", true, rule{"view employee assignment", ScopeSelf}},
+	{AssignmentManage, "Assign employees and end assignments", true, rule{"manage employee assignment", ScopeNone}},
+	{TransferReview, "Decide and execute transfer requests as HR", false, rule{"review transfer as HR", ScopeNone}},
+	{UnitManage, "Manage units and formations", false, rule{"manage unit", ScopeNone}},
+	{UnitScopeRead, "View unit scopes of other employees", false, rule{"view unit scope", ScopeSelf}},
+	{UnitScopeManage, "Grant and revoke unit scopes and unit groups", false, rule{"manage unit scope", ScopeNone}},
+	{PositionManage, "Manage positions", false, rule{"manage position", ScopeNone}},
+	{EchelonManage, "Manage echelons", false, rule{"manage echelon", ScopeNone}},
+	{ReligionManage, "Manage religions", false, rule{"manage religion", ScopeNone}},
+	{GradeManage, "Manage grades", false, rule{"manage grade", ScopeNone}},
+	{RoleManage, "Manage roles, role promotions and role permissions", false, rule{"manage role", ScopeNone}},
+	{AuditRead, "View audit log", false, rule{"view audit log", ScopeNone}},
+}
+
+// Permissions mengembalikan katalog permission sesuai urutan catalogue
+func Permissions() []domain.Permission {
+	permissions := make([]domain.Permission, 0, len(catalogue))
+	for _, permission := range catalogue {
+		permissions = append(permissions, domain.Permission{
+			ID:          string(permission.action),
+			Description: permission.description,
+			UnitScoped:  permission.unitScoped,
+		})
+	}
+	return permissions
+}
+
+// CheckCatalogue membandingkan tabel permissions dengan catalogue. Permission yang hilang, berbeda, atau
+// tidak dikenal aplikasi berarti seed documents/emploman-permissions.sql belum dijalankan ulang.
+func CheckCatalogue(repo domain.PermissionInterface) error {
+	stored, err := repo.FindAll()
+	if err != nil {
+		return err
+	}
+	storedByID := make(map[string]domain.Permission, len(stored))
+	for _, permission := range stored {
+		storedByID[permission.ID] = permission
+	}
+	var problems []string
+	for _, permission := range Permissions() {
+		existing, ok := storedByID[permission.ID]
+		switch {
+		case !ok:
+			problems = append(problems, permission.ID+" missing")
+		case existing != permission:
+			problems = append(problems, permission.ID+" differs")
+		}
+		delete(storedByID, permission.ID)
+	}
+	for id := range storedByID {
+		problems = append(problems, id+" unknown")
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("permission catalogue out of date: %s", strings.Join(problems, ", "))
+	}
+	return nil
+}
+
+// CatalogueSQL menghasilkan seed katalog permission, aman dijalankan ulang setelah catalogue berubah
+func CatalogueSQL() string {
+	var b strings.Builder
+	b.WriteString("-- Dibuat dari catalogue pada internal/authorization/catalogue.go dengan `go run ./cmd/permissions`, jangan diubah manual.\n")
+	b.WriteString("-- Dijalankan oleh emploman-setup.sql dan migrasi permission, aman dijalankan ulang setelah catalogue berubah.\n\n")
+	b.WriteString("insert into achmadnr.permissions(id, description, unit_scoped)\nvalues\n")
+	for i, permission := range Permissions() {
+		separator := ","
+		if i == len(catalogue)-1 {
+			separator = ""
+		}
+		fmt.Fprintf(&b, "('%s', '%s', %t)%s\n", permission.ID, strings.ReplaceAll(permission.Description, "'", "''"), permission.UnitScoped, separator)
+	}
+	b.WriteString("on conflict (id) do update set description = excluded.description, unit_scoped = excluded.unit_scoped;\n\n")
+	ids := make([]string, 0, len(catalogue))
+	for _, permission := range catalogue {
+		ids = append(ids, "'"+string(permission.action)+"'")
+	}
+	b.WriteString("-- permission yang sudah tidak ada pada catalogue ikut dicabut dari seluruh role\n")
+	fmt.Fprintf(&b, "delete from achmadnr.permissions where id not in (%s);\n", strings.Join(ids, ", "))
+	return b.String()
+}
@@ -0,0 +1,54 @@
+package authorization
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/achmadnr21/emploman/internal/domain"
+)
+
+type fakePermissions []domain.Permission
+
+func (p fakePermissions) FindAll() ([]domain.Permission, error) {
+	return p, nil
+}
+
+func TestCatalogueSQLUpToDate(t *testing.T) {
+	seed, err := os.ReadFile("../../documents/emploman-permissions.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(seed) != CatalogueSQL() {
+		t.Error("documents/emploman-permissions.sql is out of date, run: go run ./cmd/permissions > documents/emploman-permissions.sql")
+	}
+}
+
+func TestCheckCatalogue(t *testing.T) {
+	changed := Permissions()
+	changed[0].UnitScoped = !changed[0].UnitScoped
+	tests := []struct {
+		name   string
+		stored fakePermissions
+		want   string
+	}{
+		{"in sync", Permissions(), ""},
+		{"missing permission", Permissions()[1:], string(EmployeeRead) + " missing"},
+		{"changed permission", changed, string(EmployeeRead) + " differs"},
+		{"unknown permission", append(Permissions(), domain.Permission{ID: "report.read"}), "report.read unknown"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckCatalogue(tt.stored)
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("CheckCatalogue() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("CheckCatalogue() = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
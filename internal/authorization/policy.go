@@ -0,0 +1,148 @@
+package authorization
+
+import "github.com/achmadnr21/emploman/internal/domain"
+
+// Action adalah permission bernama dengan format <resource>.<aksi>, sama dengan id pada katalog
+// achmadnr.permissions. Action baru ditambahkan ke catalogue, katalog database mengikuti catalogue.
+type Action string
+
+const (
+	EmployeeRead           Action = "employee.read"
+	EmployeeCreate         Action = "employee.create"
+	EmployeeUpdate         Action = "employee.update"
+	EmployeePhoto          Action = "employee.photo"
+	EmployeeStatus         Action = "employee.status"
+	EmployeeExport         Action = "employee.export"
+	EmployeeCredential     Action = "employee.credential" // reset password dan buka kunci akun
+	EmployeeTwoFactorReset Action = "employee.two_factor_reset"
+	ChangeRequestReview    Action = "change_request.review"
+	AssignmentRead         Action = "assignment.read"
+	AssignmentManage       Action = "assignment.manage"
+	TransferReview         Action = "transfer.review" // keputusan kepegawaian pada mutasi
+	UnitManage             Action = "unit.manage"     // termasuk formasi
+	UnitScopeRead          Action = "unit_scope.read"
+	UnitScopeManage        Action = "unit_scope.manage"
+	PositionManage         Action = "position.manage"
+	EchelonManage          Action = "echelon.manage"
+	ReligionManage         Action = "religion.manage"
+	GradeManage            Action = "grade.manage"
+	RoleManage             Action = "role.manage"
+	AuditRead              Action = "audit.read"
+)
+
+// Scope adalah jangkauan resource yang boleh diakses, urutan menunjukkan scope yang lebih luas
+type Scope int
+
+const (
+	ScopeNone   Scope = iota
+	ScopeSelf         // hanya data milik sendiri
+	ScopeUnit         // data pada unit scope principal, termasuk data milik sendiri
+	ScopeGlobal       // seluruh data
+)
+
+// Resource adalah target action. Resource tanpa EmployeeID dan UnitID berarti koleksi atau data referensi,
+// principal dengan ScopeUnit boleh mengakses koleksi tetapi hasilnya harus disaring dengan unit scope.
+type Resource struct {
+	EmployeeID string
+	UnitID     int
+	// InUnitScope diisi Authorizer, true jika resource berada di unit scope principal
+	InUnitScope bool
+}
+
+func Employee(id string) Resource {
+	return Resource{EmployeeID: id}
+}
+
+func Unit(id int) Resource {
+	return Resource{UnitID: id}
+}
+
+func (r Resource) isCollection() bool {
+	return r.EmployeeID == "" && r.UnitID == 0
+}
+
+// rule adalah aturan bawaan sebuah action. Scope lain hanya didapat dari permission role
+// (achmadnr.role_permissions) dengan id permission sama dengan nama action.
+type rule struct {
+	// description dipakai pada pesan error, contoh "not authorized to manage grade"
+	description string
+	// everyone adalah scope yang dimiliki semua user yang login tanpa permission apa pun
+	everyone Scope
+}
+
+// policy adalah aturan bawaan setiap action pada catalogue
+var policy = func() map[Action]rule {
+	rules := make(map[Action]rule, len(catalogue))
+	for _, permission := range catalogue {
+		rules[permission.action] = permission.rule
+	}
+	return rules
+}()
+
+// ParseScope mengubah scope pada role permission menjadi Scope, nilai tidak dikenal menjadi ScopeNone
+func ParseScope(scope string) Scope {
+	switch scope {
+	case domain.PermissionScopeGlobal:
+		return ScopeGlobal
+	case domain.PermissionScopeUnit:
+		return ScopeUnit
+	default:
+		return ScopeNone
+	}
+}
+
+func (s Scope) String() string {
+	switch s {
+	case ScopeGlobal:
+		return domain.PermissionScopeGlobal
+	case ScopeUnit:
+		return domain.PermissionScopeUnit
+	case ScopeSelf:
+		return "self"
+	default:
+		return "none"
+	}
+}
+
+// Default mengembalikan scope bawaan action untuk semua user yang login
+func Default(action Action) Scope {
+	return policy[action].everyone
+}
+
+func (a Action) description() string {
+	if rule, ok := policy[a]; ok {
+		return rule.description
+	}
+	return string(a)
+}
+
+// ScopeFor mengembalikan scope terluas yang dimiliki principal untuk action.
+// Token terbatas (ganti password, 2FA) tidak pernah mendapat scope.
+func ScopeFor(principal *domain.Principal, action Action) Scope {
+	if principal == nil || principal.Purpose != "" {
+		return ScopeNone
+	}
+	scope := Default(action)
+	if granted := ParseScope(principal.Permissions[string(action)]); granted > scope {
+		scope = granted
+	}
+	return scope
+}
+
+// Can memeriksa apakah principal boleh menjalankan action terhadap resource. Untuk ScopeUnit,
+// resource.InUnitScope harus sudah diisi, gunakan Authorizer jika unit scope belum diketahui.
+func Can(principal *domain.Principal, action Action, resource Resource) bool {
+	switch ScopeFor(principal, action) {
+	case ScopeGlobal:
+		return true
+	case ScopeUnit:
+		if resource.isCollection() || resource.EmployeeID == principal.UserID {
+			return true
+		}
+		return resource.InUnitScope
+	case ScopeSelf:
+		return resource.EmployeeID != "" && resource.EmployeeID == principal.UserID
+	default:
+		return false
+	}
+}
@@ -0,0 +1,134 @@
+package authorization
+
+import (
+	"testing"
+
+	"github.com/achmadnr21/emploman/internal/domain"
+	"github.com/achmadnr21/emploman/internal/utils"
+)
+
+// roles mengikuti seed role_permissions pada documents/emploman-setup.sql
+var roles = map[string]domain.Role{
+	"SUP": {ID: "SUP", Permissions: grants(domain.PermissionScopeGlobal, allActions()...)},
+	"ADM": {ID: "ADM", Permissions: append(
+		grants(domain.PermissionScopeGlobal, EmployeeRead, EmployeeCreate, EmployeeUpdate, EmployeePhoto, EmployeeStatus, EmployeeExport,
+			EmployeeCredential, EmployeeTwoFactorReset, ChangeRequestReview, UnitManage, UnitScopeRead, UnitScopeManage,
+			PositionManage, EchelonManage, ReligionManage, GradeManage, RoleManage, AuditRead),
+		grants(domain.PermissionScopeUnit, AssignmentRead, AssignmentManage)...)},
+	"MGR": {ID: "MGR", Permissions: append(
+		grants(domain.PermissionScopeUnit, EmployeeRead, EmployeeUpdate, EmployeeExport, AssignmentRead, AssignmentManage),
+		grants(domain.PermissionScopeGlobal, UnitManage, PositionManage)...)},
+	"HRD": {ID: "HRD", Permissions: grants(domain.PermissionScopeGlobal, EmployeeRead, EmployeeCreate, EmployeeUpdate, EmployeePhoto,
+		EmployeeStatus, EmployeeExport, EmployeeCredential, ChangeRequestReview, AssignmentRead, AssignmentManage, TransferReview)},
+	"USR": {ID: "USR"},
+}
+
+func grants(scope string, actions ...Action) []domain.RolePermission {
+	permissions := []domain.RolePermission{}
+	for _, action := range actions {
+		permissions = append(permissions, domain.RolePermission{PermissionID: string(action), Scope: scope})
+	}
+	return permissions
+}
+
+func allActions() []Action {
+	actions := []Action{}
+	for action := range policy {
+		actions = append(actions, action)
+	}
+	return actions
+}
+
+func principalFor(roleID string) *domain.Principal {
+	role := roles[roleID]
+	return &domain.Principal{UserID: "self", RoleID: role.ID, Permissions: role.PermissionSet()}
+}
+
+func TestScopeForMatrix(t *testing.T) {
+	const (
+		N = ScopeNone
+		S = ScopeSelf
+		U = ScopeUnit
+		G = ScopeGlobal
+	)
+	// kolom: SUP, ADM, MGR, HRD, USR
+	matrix := []struct {
+		action Action
+		scopes [5]Scope
+	}{
+		{EmployeeRead, [5]Scope{G, G, U, G, N}},
+		{EmployeeCreate, [5]Scope{G, G, N, G, N}},
+		{EmployeeUpdate, [5]Scope{G, G, U, G, N}},
+		{EmployeePhoto, [5]Scope{G, G, S, G, S}},
+		{EmployeeStatus, [5]Scope{G, G, N, G, N}},
+		{EmployeeExport, [5]Scope{G, G, U, G, N}},
+		{EmployeeCredential, [5]Scope{G, G, N, G, N}},
+		{EmployeeTwoFactorReset, [5]Scope{G, G, N, N, N}},
+		{ChangeRequestReview, [5]Scope{G, G, N, G, N}},
+		{AssignmentRead, [5]Scope{G, U, U, G, S}},
+		{AssignmentManage, [5]Scope{G, U, U, G, N}},
+		{TransferReview, [5]Scope{G, N, N, G, N}},
+		{UnitManage, [5]Scope{G, G, G, N, N}},
+		{UnitScopeRead, [5]Scope{G, G, S, S, S}},
+		{UnitScopeManage, [5]Scope{G, G, N, N, N}},
+		{PositionManage, [5]Scope{G, G, G, N, N}},
+		{EchelonManage, [5]Scope{G, G, N, N, N}},
+		{ReligionManage, [5]Scope{G, G, N, N, N}},
+		{GradeManage, [5]Scope{G, G, N, N, N}},
+		{RoleManage, [5]Scope{G, G, N, N, N}},
+		{AuditRead, [5]Scope{G, G, N, N, N}},
+	}
+	if len(matrix) != len(policy) {
+		t.Fatalf("matrix covers %d actions, policy has %d", len(matrix), len(policy))
+	}
+	for _, row := range matrix {
+		for i, roleID := range []string{"SUP", "ADM", "MGR", "HRD", "USR"} {
+			if got := ScopeFor(principalFor(roleID), row.action); got != row.scopes[i] {
+				t.Errorf("ScopeFor(%s, %s) = %d, want %d", roleID, row.action, got, row.scopes[i])
+			}
+		}
+	}
+}
+
+func TestCan(t *testing.T) {
+	restricted := &domain.Principal{UserID: "self", Purpose: utils.TokenPurposePasswordChange, Permissions: principalFor("SUP").Permissions}
+	tests := []struct {
+		name      string
+		principal *domain.Principal
+		action    Action
+		resource  Resource
+		want      bool
+	}{
+		{"global reads any employee", principalFor("HRD"), EmployeeRead, Employee("other"), true},
+		{"global reads collection", principalFor("HRD"), EmployeeRead, Resource{}, true},
+		{"unit reads collection", principalFor("MGR"), EmployeeRead, Resource{}, true},
+		{"unit reads employee in scope", principalFor("MGR"), EmployeeRead, Resource{EmployeeID: "other", InUnitScope: true}, true},
+		{"unit denied employee outside scope", principalFor("MGR"), EmployeeRead, Employee("other"), false},
+		{"unit reads self", principalFor("MGR"), EmployeeRead, Employee("self"), true},
+		{"unit updates employee in scope", principalFor("MGR"), EmployeeUpdate, Resource{EmployeeID: "other", InUnitScope: true}, true},
+		{"unit denied update outside scope", principalFor("MGR"), EmployeeUpdate, Employee("other"), false},
+		{"unit manages unit in scope", principalFor("ADM"), AssignmentManage, Resource{UnitID: 7, InUnitScope: true}, true},
+		{"unit denied unit outside scope", principalFor("ADM"), AssignmentManage, Unit(7), false},
+		{"self uploads own photo", principalFor("USR"), EmployeePhoto, Employee("self"), true},
+		{"self denied other photo", principalFor("USR"), EmployeePhoto, Employee("other"), false},
+		{"self denied photo without employee", principalFor("USR"), EmployeePhoto, Resource{}, false},
+		{"self reads own assignment", principalFor("USR"), AssignmentRead, Employee("self"), true},
+		{"self denied other assignment even in scope", principalFor("USR"), AssignmentRead, Resource{EmployeeID: "other", InUnitScope: true}, false},
+		{"self reads own unit scope", principalFor("HRD"), UnitScopeRead, Employee("self"), true},
+		{"none denied reference data", principalFor("HRD"), GradeManage, Resource{}, false},
+		{"none denied even for own data", principalFor("USR"), EmployeeRead, Employee("self"), false},
+		{"restricted token denied", restricted, EmployeeRead, Resource{}, false},
+		{"restricted token denied own photo", restricted, EmployeePhoto, Employee("self"), false},
+		{"nil principal denied", nil, UnitScopeRead, Resource{}, false},
+		{"unknown action denied", principalFor("SUP"), Action("unknown"), Resource{}, false},
+		{"invalid scope ignored", &domain.Principal{UserID: "self", Permissions: domain.PermissionSet{"audit.read": "self"}}, AuditRead, Resource{}, false},
+		{"permission outside policy granted by role", &domain.Principal{UserID: "self", Permissions: domain.PermissionSet{"report.read": "global"}}, Action("report.read"), Resource{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Can(tt.principal, tt.action, tt.resource); got != tt.want {
+				t.Errorf("Can() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,234 @@
+package document
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/jpeg"
+	"image/png"
+	"io"
+	"strings"
+
+	"github.com/achmadnr21/emploman/internal/domain"
+	"github.com/disintegration/imaging"
+	"github.com/go-pdf/fpdf"
+	"github.com/skip2/go-qrcode"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	CardSideFront = "front"
+	CardSideBack  = "back"
+
+	// ukuran kartu ID-1 (85.6 x 53.98 mm) pada 300 dpi
+	cardWidth    = 1011
+	cardHeight   = 638
+	cardWidthMM  = 85.6
+	cardHeightMM = 53.98
+)
+
+var (
+	cardPrimary = color.RGBA{0x1f, 0x3a, 0x68, 0xff}
+	cardText    = color.RGBA{0x22, 0x22, 0x22, 0xff}
+	cardMuted   = color.RGBA{0x66, 0x66, 0x66, 0xff}
+)
+
+// WriteEmployeeCardPNG menulis satu sisi kartu (front/back) sebagai PNG
+func WriteEmployeeCardPNG(w io.Writer, card *domain.PrintEmployeeCard, side string) error {
+	var img image.Image
+	var err error
+	if side == CardSideBack {
+		img, err = renderCardBack(card)
+	} else {
+		img, err = renderCardFront(card)
+	}
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, img)
+}
+
+// WriteEmployeeCardPDF menulis kartu dua halaman (depan dan belakang) seukuran kartu asli
+func WriteEmployeeCardPDF(w io.Writer, card *domain.PrintEmployeeCard) error {
+	pdf := fpdf.NewCustom(&fpdf.InitType{
+		OrientationStr: "L",
+		UnitStr:        "mm",
+		Size:           fpdf.SizeType{Wd: cardWidthMM, Ht: cardHeightMM},
+	})
+	pdf.SetMargins(0, 0, 0)
+	pdf.SetAutoPageBreak(false, 0)
+	for _, side := range []string{CardSideFront, CardSideBack} {
+		var buf bytes.Buffer
+		if err := WriteEmployeeCardPNG(&buf, card, side); err != nil {
+			return err
+		}
+		pdf.AddPage()
+		options := fpdf.ImageOptions{ImageType: "PNG"}
+		pdf.RegisterImageOptionsReader(side, options, &buf)
+		pdf.ImageOptions(side, 0, 0, cardWidthMM, cardHeightMM, false, options, 0, "")
+	}
+	return pdf.Output(w)
+}
+
+func renderCardFront(card *domain.PrintEmployeeCard) (image.Image, error) {
+	employee := card.Employee
+	img := newCard()
+	fill(img, image.Rect(0, 0, cardWidth, 120), cardPrimary)
+	if err := drawText(img, "KARTU TANDA PEGAWAI", true, 40, color.White, 40, 78); err != nil {
+		return nil, err
+	}
+
+	// foto 3x4, kotak abu-abu jika foto tidak tersedia
+	photoRect := image.Rect(40, 160, 280, 480)
+	fill(img, photoRect, color.RGBA{0xdd, 0xdd, 0xdd, 0xff})
+	if photo, _, err := image.Decode(bytes.NewReader(card.Photo)); err == nil {
+		photo = imaging.Fill(photo, photoRect.Dx(), photoRect.Dy(), imaging.Center, imaging.Lanczos)
+		draw.Draw(img, photoRect, photo, image.Point{}, draw.Src)
+	}
+
+	x, y := 320, 200
+	lines := []struct {
+		text  string
+		bold  bool
+		size  float64
+		color color.Color
+	}{
+		{employee.FullName, true, 40, cardText},
+		{"NIP. " + employee.NIP, false, 32, cardText},
+		{employee.Jabatan, false, 30, cardMuted},
+		{employee.Unit, false, 30, cardMuted},
+	}
+	for _, line := range lines {
+		face, err := cardFace(line.bold, line.size)
+		if err != nil {
+			return nil, err
+		}
+		for _, text := range wrapText(face, line.text, cardWidth-x-40, 2) {
+			drawWithFace(img, face, text, line.color, x, y)
+			y += int(line.size * 1.3)
+		}
+		y += 14
+	}
+	fill(img, image.Rect(0, cardHeight-24, cardWidth, cardHeight), cardPrimary)
+	return img, nil
+}
+
+func renderCardBack(card *domain.PrintEmployeeCard) (image.Image, error) {
+	img := newCard()
+	fill(img, image.Rect(0, 0, cardWidth, 24), cardPrimary)
+
+	qr, err := qrcode.New(card.Token, qrcode.Medium)
+	if err != nil {
+		return nil, err
+	}
+	qrImage := qr.Image(400)
+	qrRect := image.Rect(40, (cardHeight-400)/2, 440, (cardHeight+400)/2)
+	draw.Draw(img, qrRect, qrImage, image.Point{}, draw.Src)
+
+	x, y := 480, 170
+	face, err := cardFace(false, 28)
+	if err != nil {
+		return nil, err
+	}
+	notes := []string{
+		"Kartu ini adalah identitas resmi pegawai dan tidak dapat dipindahtangankan.",
+		"Pindai QR code untuk memastikan keaslian kartu dan status kepegawaian.",
+		"Apabila menemukan kartu ini harap dikembalikan ke bagian kepegawaian.",
+	}
+	for _, note := range notes {
+		for _, text := range wrapText(face, note, cardWidth-x-40, 3) {
+			drawWithFace(img, face, text, cardText, x, y)
+			y += 36
+		}
+		y += 16
+	}
+	if err := drawText(img, "Diterbitkan: "+FormatTanggal(card.IssuedAt), false, 24, cardMuted, x, cardHeight-70); err != nil {
+		return nil, err
+	}
+	fill(img, image.Rect(0, cardHeight-24, cardWidth, cardHeight), cardPrimary)
+	return img, nil
+}
+
+// ==================================================================== UTILITIES ====================================================================
+
+func newCard() *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, cardWidth, cardHeight))
+	fill(img, img.Bounds(), color.White)
+	return img
+}
+
+func fill(img *image.RGBA, rect image.Rectangle, c color.Color) {
+	draw.Draw(img, rect, image.NewUniform(c), image.Point{}, draw.Src)
+}
+
+func cardFace(bold bool, size float64) (font.Face, error) {
+	ttf := goregular.TTF
+	if bold {
+		ttf = gobold.TTF
+	}
+	parsed, err := opentype.Parse(ttf)
+	if err != nil {
+		return nil, err
+	}
+	return opentype.NewFace(parsed, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+}
+
+func drawText(img *image.RGBA, text string, bold bool, size float64, c color.Color, x, y int) error {
+	face, err := cardFace(bold, size)
+	if err != nil {
+		return err
+	}
+	drawWithFace(img, face, text, c, x, y)
+	return nil
+}
+
+// drawWithFace menulis teks dengan baseline pada koordinat y
+func drawWithFace(img *image.RGBA, face font.Face, text string, c color.Color, x, y int) {
+	drawer := &font.Drawer{Dst: img, Src: image.NewUniform(c), Face: face, Dot: fixed.P(x, y)}
+	drawer.DrawString(text)
+}
+
+// wrapText memecah teks per kata agar muat dalam lebar maxWidth, baris terakhir dipotong dengan "..."
+func wrapText(face font.Face, text string, maxWidth int, maxLines int) []string {
+	limit := fixed.I(maxWidth)
+	var lines []string
+	current := ""
+	for _, word := range strings.Fields(text) {
+		candidate := strings.TrimSpace(current + " " + word)
+		if current != "" && font.MeasureString(face, candidate) > limit {
+			lines = append(lines, current)
+			current = word
+			continue
+		}
+		current = candidate
+	}
+	if current != "" {
+		lines = append(lines, current)
+	}
+	truncated := len(lines) > maxLines
+	if truncated {
+		lines = lines[:maxLines]
+	}
+	for i, line := range lines {
+		suffix := ""
+		if truncated && i == len(lines)-1 {
+			suffix = "..."
+		}
+		if font.MeasureString(face, line+suffix) <= limit {
+			lines[i] = line + suffix
+			continue
+		}
+		// satu kata yang terlalu panjang tetap dipotong
+		runes := []rune(line)
+		for len(runes) > 0 && font.MeasureString(face, string(runes)+"...") > limit {
+			runes = runes[:len(runes)-1]
+		}
+		lines[i] = string(runes) + "..."
+	}
+	return lines
+}
@@ -0,0 +1,204 @@
+package document
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/achmadnr21/emploman/internal/domain"
+	"github.com/go-pdf/fpdf"
+)
+
+var bulan = []string{"Januari", "Februari", "Maret", "April", "Mei", "Juni",
+	"Juli", "Agustus", "September", "Oktober", "November", "Desember"}
+
+// FormatTanggal memformat tanggal dengan nama bulan bahasa Indonesia, contoh: 17 Agustus 1945
+func FormatTanggal(t time.Time) string {
+	return fmt.Sprintf("%d %s %d", t.Day(), bulan[t.Month()-1], t.Year())
+}
+
+// jabatanTitle menambahkan keterangan Plt atau tugas tambahan pada nama jabatan
+func jabatanTitle(assignment *domain.EmployeeAssignmentResponse) string {
+	switch assignment.Type {
+	case domain.AssignmentTypeActing:
+		return "Plt. " + assignment.PositionName
+	case domain.AssignmentTypeAdditional:
+		return assignment.PositionName + " (Tugas Tambahan)"
+	}
+	return assignment.PositionName
+}
+
+// WriteEmployeeCV menulis Daftar Riwayat Hidup satu employee dalam format PDF A4
+func WriteEmployeeCV(w io.Writer, cv *domain.PrintEmployeeCV, printedAt time.Time) error {
+	employee := cv.Employee
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(20, 15, 20)
+	pdf.SetAutoPageBreak(true, 20)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-12)
+		pdf.SetFont("Helvetica", "I", 8)
+		pdf.CellFormat(0, 5, fmt.Sprintf("Halaman %d", pdf.PageNo()), "", 0, "C", false, 0, "")
+	})
+	pdf.AddPage()
+
+	pdf.SetFont("Helvetica", "B", 14)
+	pdf.CellFormat(0, 8, "DAFTAR RIWAYAT HIDUP", "", 1, "C", false, 0, "")
+	pdf.Ln(4)
+
+	// foto 3x4 di kanan atas, kotak kosong jika foto tidak tersedia
+	photoX, photoY, photoW, photoH := 160.0, pdf.GetY(), 30.0, 40.0
+	if !drawPhoto(pdf, cv.Photo, photoX, photoY, photoW, photoH) {
+		pdf.Rect(photoX, photoY, photoW, photoH, "D")
+		pdf.SetFont("Helvetica", "", 8)
+		pdf.SetXY(photoX, photoY+photoH/2-2)
+		pdf.CellFormat(photoW, 4, "Pas Foto 3x4", "", 0, "C", false, 0, "")
+		pdf.SetXY(20, photoY)
+	}
+
+	pdf.SetFont("Helvetica", "B", 11)
+	pdf.CellFormat(0, 7, "I. KETERANGAN PERORANGAN", "", 1, "L", false, 0, "")
+	gender := map[string]string{"L": "Laki-laki", "P": "Perempuan"}[employee.Gender]
+	if gender == "" {
+		gender = employee.Gender
+	}
+	biodata := [][2]string{
+		{"Nama Lengkap", employee.FullName},
+		{"NIP", employee.NIP},
+		{"Tempat, Tanggal Lahir", employee.PlaceOfBirth + ", " + FormatTanggal(employee.DateOfBirth)},
+		{"Jenis Kelamin", gender},
+		{"Agama", employee.Religion},
+		{"Golongan", employee.Grade},
+		{"Eselon", employee.Echelon},
+		{"Jabatan", employee.Jabatan},
+		{"Unit Kerja", employee.Unit},
+		{"Tempat Tugas", employee.TempatTugas},
+	}
+	// Plt/Plh dan tugas tambahan yang sedang dijabat ditampilkan setelah jabatan definitif
+	var rangkap []string
+	for i := range employee.CurrentRoles {
+		role := &employee.CurrentRoles[i]
+		if role.Type != domain.AssignmentTypePrimary {
+			rangkap = append(rangkap, jabatanTitle(role)+" - "+role.UnitName)
+		}
+	}
+	if len(rangkap) > 0 {
+		biodata = append(biodata, [2]string{"Jabatan Rangkap", strings.Join(rangkap, "; ")})
+	}
+	biodata = append(biodata, [][2]string{
+		{"NPWP", employee.NPWP},
+		{"No. Telepon", employee.PhoneNumber},
+		{"Alamat", employee.Address},
+	}...)
+	pdf.SetFont("Helvetica", "", 10)
+	for i, item := range biodata {
+		// baris di samping foto dibuat lebih sempit agar tidak menimpa foto
+		valueWidth := 0.0
+		if pdf.GetY() < photoY+photoH {
+			valueWidth = photoX - 3 - 20 - 8 - 45 - 4
+		}
+		pdf.CellFormat(8, 6, fmt.Sprintf("%d.", i+1), "", 0, "L", false, 0, "")
+		pdf.CellFormat(45, 6, tr(item[0]), "", 0, "L", false, 0, "")
+		pdf.CellFormat(4, 6, ":", "", 0, "L", false, 0, "")
+		pdf.MultiCell(valueWidth, 6, tr(item[1]), "", "L", false)
+	}
+	if pdf.GetY() < photoY+photoH {
+		pdf.SetY(photoY + photoH)
+	}
+	pdf.Ln(6)
+
+	pdf.SetFont("Helvetica", "B", 11)
+	pdf.CellFormat(0, 7, "II. RIWAYAT JABATAN", "", 1, "L", false, 0, "")
+	widths := []float64{10, 50, 50, 30, 30}
+	headers := []string{"No", "Jabatan", "Unit Kerja", "TMT", "Sampai"}
+	pdf.SetFont("Helvetica", "B", 9)
+	pdf.SetFillColor(220, 220, 220)
+	for i, header := range headers {
+		pdf.CellFormat(widths[i], 7, header, "1", 0, "C", true, 0, "")
+	}
+	pdf.Ln(-1)
+	pdf.SetFont("Helvetica", "", 9)
+	if len(cv.Assignments) == 0 {
+		pdf.CellFormat(170, 7, "Belum ada riwayat jabatan", "1", 1, "C", false, 0, "")
+	}
+	for i, assignment := range cv.Assignments {
+		until := "Sekarang"
+		if assignment.EndDate != nil {
+			until = FormatTanggal(*assignment.EndDate)
+		}
+		row := []string{
+			fmt.Sprint(i + 1),
+			fitText(pdf, tr(jabatanTitle(&assignment)), widths[1]),
+			fitText(pdf, tr(assignment.UnitName), widths[2]),
+			FormatTanggal(assignment.StartDate),
+			until,
+		}
+		for j, value := range row {
+			align := "L"
+			if j == 0 {
+				align = "C"
+			}
+			pdf.CellFormat(widths[j], 7, value, "1", 0, align, false, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+	pdf.Ln(8)
+
+	// blok tanda tangan, dipindah ke halaman baru jika tidak cukup ruang
+	_, pageHeight := pdf.GetPageSize()
+	if pdf.GetY()+55 > pageHeight-20 {
+		pdf.AddPage()
+	}
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.MultiCell(0, 6, "Demikian daftar riwayat hidup ini saya buat dengan sesungguhnya dan apabila di kemudian hari terdapat keterangan yang tidak benar, saya bersedia dituntut di muka pengadilan.", "", "J", false)
+	pdf.Ln(6)
+	signX := 120.0
+	pdf.SetX(signX)
+	pdf.CellFormat(70, 6, "...................., "+FormatTanggal(printedAt), "", 1, "C", false, 0, "")
+	pdf.SetX(signX)
+	pdf.CellFormat(70, 6, "Yang membuat,", "", 1, "C", false, 0, "")
+	pdf.Ln(20)
+	pdf.SetX(signX)
+	pdf.SetFont("Helvetica", "BU", 10)
+	pdf.CellFormat(70, 6, tr(employee.FullName), "", 1, "C", false, 0, "")
+	pdf.SetX(signX)
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.CellFormat(70, 6, "NIP. "+employee.NIP, "", 1, "C", false, 0, "")
+
+	return pdf.Output(w)
+}
+
+// drawPhoto menggambar foto jpeg/png, mengembalikan false jika foto kosong atau tidak dikenali
+func drawPhoto(pdf *fpdf.Fpdf, photo []byte, x, y, w, h float64) bool {
+	if len(photo) == 0 {
+		return false
+	}
+	imageType := map[string]string{"image/jpeg": "JPG", "image/png": "PNG"}[http.DetectContentType(photo)]
+	if imageType == "" {
+		return false
+	}
+	pdf.RegisterImageOptionsReader("photo", fpdf.ImageOptions{ImageType: imageType}, bytes.NewReader(photo))
+	if pdf.Error() != nil {
+		// foto rusak tidak boleh menggagalkan seluruh dokumen
+		pdf.ClearError()
+		return false
+	}
+	pdf.ImageOptions("photo", x, y, w, h, false, fpdf.ImageOptions{ImageType: imageType}, 0, "")
+	return true
+}
+
+// fitText memotong teks agar tidak melewati lebar kolom
+func fitText(pdf *fpdf.Fpdf, text string, width float64) string {
+	limit := width - 2
+	if pdf.GetStringWidth(text) <= limit {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 && pdf.GetStringWidth(string(runes)+"...") > limit {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "..."
+}
@@ -0,0 +1,332 @@
+package document
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/achmadnr21/emploman/internal/domain"
+	"github.com/go-pdf/fpdf"
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+	FormatPDF  = "pdf"
+)
+
+// Formats berisi format yang didukung sesuai urutan prioritas saat negosiasi header Accept
+var Formats = []string{FormatCSV, FormatXLSX, FormatPDF}
+
+var ContentTypes = map[string]string{
+	FormatCSV:  "text/csv; charset=utf-8",
+	FormatXLSX: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	FormatPDF:  "application/pdf",
+}
+
+// TableOptions berisi informasi header dokumen
+type TableOptions struct {
+	Lang      string // "id" atau "en"
+	Title     string // biasanya nama unit
+	PrintedAt time.Time
+}
+
+// EmployeeTableWriter menulis daftar employee baris per baris lalu menutup dokumen
+type EmployeeTableWriter interface {
+	WriteRow(employee *domain.PrintEmployee) error
+	Close() error
+}
+
+// NewEmployeeTableWriter membuat writer sesuai format. Header kolom langsung ditulis.
+func NewEmployeeTableWriter(format string, w io.Writer, opts TableOptions) (EmployeeTableWriter, error) {
+	if opts.PrintedAt.IsZero() {
+		opts.PrintedAt = time.Now()
+	}
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w, opts)
+	case FormatXLSX:
+		return newXLSXWriter(w, opts)
+	case FormatPDF:
+		return newPDFWriter(w, opts), nil
+	}
+	return nil, fmt.Errorf("unsupported format %s", format)
+}
+
+// ==================================================================== COLUMNS ====================================================================
+
+type tableColumn struct {
+	key   string
+	width float64 // lebar kolom pada pdf (mm), 0 berarti tidak ditampilkan di pdf
+	value func(e *domain.PrintEmployee) string
+}
+
+var tableColumns = []tableColumn{
+	{"nip", 38, func(e *domain.PrintEmployee) string { return e.NIP }},
+	{"full_name", 48, func(e *domain.PrintEmployee) string { return e.FullName }},
+	{"place_of_birth", 0, func(e *domain.PrintEmployee) string { return e.PlaceOfBirth }},
+	{"date_of_birth", 22, func(e *domain.PrintEmployee) string { return e.DateOfBirth.Format("02-01-2006") }},
+	{"gender", 10, func(e *domain.PrintEmployee) string { return e.Gender }},
+	{"grade", 16, func(e *domain.PrintEmployee) string { return e.Grade }},
+	{"echelon", 14, func(e *domain.PrintEmployee) string { return e.Echelon }},
+	{"position", 40, func(e *domain.PrintEmployee) string { return e.Jabatan }},
+	{"unit", 40, func(e *domain.PrintEmployee) string { return e.Unit }},
+	{"workplace", 0, func(e *domain.PrintEmployee) string { return e.TempatTugas }},
+	{"religion", 20, func(e *domain.PrintEmployee) string { return e.Religion }},
+	{"phone_number", 29, func(e *domain.PrintEmployee) string { return e.PhoneNumber }},
+	{"npwp", 0, func(e *domain.PrintEmployee) string { return e.NPWP }},
+	{"address", 0, func(e *domain.PrintEmployee) string { return e.Address }},
+}
+
+var labels = map[string]map[string]string{
+	"id": {
+		"nip":            "NIP",
+		"full_name":      "Nama Lengkap",
+		"place_of_birth": "Tempat Lahir",
+		"date_of_birth":  "Tanggal Lahir",
+		"gender":         "L/P",
+		"grade":          "Golongan",
+		"echelon":        "Eselon",
+		"position":       "Jabatan",
+		"unit":           "Unit Kerja",
+		"workplace":      "Tempat Tugas",
+		"religion":       "Agama",
+		"phone_number":   "No. Telepon",
+		"npwp":           "NPWP",
+		"address":        "Alamat",
+		"title":          "Daftar Pegawai",
+		"printed_at":     "Tanggal Cetak",
+		"page":           "Halaman",
+		"all_units":      "Semua Unit Kerja",
+	},
+	"en": {
+		"nip":            "Employee ID (NIP)",
+		"full_name":      "Full Name",
+		"place_of_birth": "Place of Birth",
+		"date_of_birth":  "Date of Birth",
+		"gender":         "M/F",
+		"grade":          "Grade",
+		"echelon":        "Echelon",
+		"position":       "Position",
+		"unit":           "Unit",
+		"workplace":      "Workplace",
+		"religion":       "Religion",
+		"phone_number":   "Phone Number",
+		"npwp":           "Tax ID (NPWP)",
+		"address":        "Address",
+		"title":          "Employee List",
+		"printed_at":     "Printed At",
+		"page":           "Page",
+		"all_units":      "All Units",
+	},
+}
+
+// Label mengembalikan label terjemahan, bahasa yang tidak dikenal memakai bahasa Indonesia
+func Label(lang string, key string) string {
+	if l, ok := labels[lang]; ok {
+		return l[key]
+	}
+	return labels["id"][key]
+}
+
+func headerRow(lang string) []string {
+	header := make([]string, len(tableColumns))
+	for i, column := range tableColumns {
+		header[i] = Label(lang, column.key)
+	}
+	return header
+}
+
+// ==================================================================== CSV ====================================================================
+
+type csvWriter struct {
+	buf   *bufio.Writer
+	w     *csv.Writer
+	count int
+}
+
+func newCSVWriter(w io.Writer, opts TableOptions) (*csvWriter, error) {
+	buf := bufio.NewWriter(w)
+	// BOM agar Excel membaca file sebagai UTF-8
+	if _, err := buf.WriteString("\xEF\xBB\xBF"); err != nil {
+		return nil, err
+	}
+	writer := &csvWriter{buf: buf, w: csv.NewWriter(buf)}
+	if err := writer.w.Write(headerRow(opts.Lang)); err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+func (cw *csvWriter) WriteRow(employee *domain.PrintEmployee) error {
+	record := make([]string, len(tableColumns))
+	for i, column := range tableColumns {
+		record[i] = column.value(employee)
+	}
+	if err := cw.w.Write(record); err != nil {
+		return err
+	}
+	cw.count++
+	// flush berkala agar data langsung terkirim ke client
+	if cw.count%500 == 0 {
+		cw.w.Flush()
+		if err := cw.w.Error(); err != nil {
+			return err
+		}
+		return cw.buf.Flush()
+	}
+	return nil
+}
+
+func (cw *csvWriter) Close() error {
+	cw.w.Flush()
+	if err := cw.w.Error(); err != nil {
+		return err
+	}
+	return cw.buf.Flush()
+}
+
+// ==================================================================== XLSX ====================================================================
+
+type xlsxWriter struct {
+	out    io.Writer
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	row    int
+}
+
+func newXLSXWriter(w io.Writer, opts TableOptions) (*xlsxWriter, error) {
+	file := excelize.NewFile()
+	sheet := Label(opts.Lang, "title")
+	if err := file.SetSheetName("Sheet1", sheet); err != nil {
+		file.Close()
+		return nil, err
+	}
+	// StreamWriter menyimpan baris ke temporary file, bukan ke memory
+	stream, err := file.NewStreamWriter(sheet)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	writer := &xlsxWriter{out: w, file: file, stream: stream, row: 1}
+	title := Label(opts.Lang, "title")
+	if opts.Title != "" {
+		title += " - " + opts.Title
+	}
+	rows := [][]interface{}{
+		{title},
+		{Label(opts.Lang, "printed_at") + ": " + opts.PrintedAt.Format("02-01-2006 15:04")},
+		{},
+	}
+	header := make([]interface{}, len(tableColumns))
+	for i, label := range headerRow(opts.Lang) {
+		header[i] = label
+	}
+	rows = append(rows, header)
+	for _, row := range rows {
+		if err := writer.setRow(row); err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+	return writer, nil
+}
+
+func (xw *xlsxWriter) setRow(values []interface{}) error {
+	cell, err := excelize.CoordinatesToCellName(1, xw.row)
+	if err != nil {
+		return err
+	}
+	xw.row++
+	return xw.stream.SetRow(cell, values)
+}
+
+func (xw *xlsxWriter) WriteRow(employee *domain.PrintEmployee) error {
+	values := make([]interface{}, len(tableColumns))
+	for i, column := range tableColumns {
+		values[i] = column.value(employee)
+	}
+	return xw.setRow(values)
+}
+
+func (xw *xlsxWriter) Close() error {
+	defer xw.file.Close()
+	if err := xw.stream.Flush(); err != nil {
+		return err
+	}
+	return xw.file.Write(xw.out)
+}
+
+// ==================================================================== PDF ====================================================================
+
+type pdfWriter struct {
+	out     io.Writer
+	pdf     *fpdf.Fpdf
+	tr      func(string) string
+	columns []tableColumn
+	number  int
+}
+
+func newPDFWriter(w io.Writer, opts TableOptions) *pdfWriter {
+	pdf := fpdf.New("L", "mm", "A4", "")
+	pdf.SetMargins(10, 10, 10)
+	pdf.SetAutoPageBreak(true, 15)
+	writer := &pdfWriter{
+		out: w,
+		pdf: pdf,
+		tr:  pdf.UnicodeTranslatorFromDescriptor(""),
+	}
+	for _, column := range tableColumns {
+		if column.width > 0 {
+			writer.columns = append(writer.columns, column)
+		}
+	}
+	title := Label(opts.Lang, "title")
+	subtitle := opts.Title
+	if subtitle == "" {
+		subtitle = Label(opts.Lang, "all_units")
+	}
+	printedAt := Label(opts.Lang, "printed_at") + ": " + opts.PrintedAt.Format("02-01-2006 15:04")
+
+	// header diulang pada setiap halaman, termasuk header kolom tabel
+	pdf.SetHeaderFunc(func() {
+		pdf.SetFont("Helvetica", "B", 14)
+		pdf.CellFormat(0, 7, writer.tr(title), "", 1, "C", false, 0, "")
+		pdf.SetFont("Helvetica", "", 11)
+		pdf.CellFormat(0, 6, writer.tr(subtitle), "", 1, "C", false, 0, "")
+		pdf.SetFont("Helvetica", "", 8)
+		pdf.CellFormat(0, 5, writer.tr(printedAt), "", 1, "R", false, 0, "")
+		pdf.SetFont("Helvetica", "B", 8)
+		pdf.SetFillColor(220, 220, 220)
+		pdf.CellFormat(8, 6, "No", "1", 0, "C", true, 0, "")
+		for _, column := range writer.columns {
+			pdf.CellFormat(column.width, 6, writer.tr(Label(opts.Lang, column.key)), "1", 0, "C", true, 0, "")
+		}
+		pdf.Ln(-1)
+		pdf.SetFont("Helvetica", "", 8)
+	})
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-12)
+		pdf.SetFont("Helvetica", "I", 8)
+		pdf.CellFormat(0, 5, fmt.Sprintf("%s %d", writer.tr(Label(opts.Lang, "page")), pdf.PageNo()), "", 0, "C", false, 0, "")
+	})
+	pdf.AddPage()
+	return writer
+}
+
+func (pw *pdfWriter) WriteRow(employee *domain.PrintEmployee) error {
+	pw.number++
+	pw.pdf.CellFormat(8, 6, fmt.Sprint(pw.number), "1", 0, "C", false, 0, "")
+	for _, column := range pw.columns {
+		pw.pdf.CellFormat(column.width, 6, fitText(pw.pdf, pw.tr(column.value(employee)), column.width), "1", 0, "L", false, 0, "")
+	}
+	pw.pdf.Ln(-1)
+	return pw.pdf.Error()
+}
+
+func (pw *pdfWriter) Close() error {
+	return pw.pdf.Output(pw.out)
+}
@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+type AuditLog struct {
+	ID         int64           `json:"id" db:"id"`
+	ActorID    string          `json:"actor_id" db:"actor_id"`
+	ActorName  string          `json:"actor_name,omitempty"`
+	Action     string          `json:"action" db:"action"`
+	EntityType string          `json:"entity_type" db:"entity_type"`
+	EntityID   string          `json:"entity_id" db:"entity_id"`
+	Before     json.RawMessage `json:"before" db:"before"`
+	After      json.RawMessage `json:"after" db:"after"`
+	IPAddress  string          `json:"ip_address" db:"ip_address"`
+	UserAgent  string          `json:"user_agent" db:"user_agent"`
+	CreatedAt  time.Time       `json:"created_at" db:"created_at"`
+}
+
+const (
+	AuditActionCreate        = "create"
+	AuditActionUpdate        = "update"
+	AuditActionDelete        = "delete"
+	AuditActionPromote       = "promote"
+	AuditActionAssign        = "assign"
+	AuditActionStatus        = "change_status"
+	AuditActionImport        = "import"
+	AuditActionPhoto         = "upload_photo"
+	AuditActionDeactivate    = "deactivate"
+	AuditActionApprove       = "approve"
+	AuditActionReject        = "reject"
+	AuditActionPassword      = "change_password"
+	AuditActionResetPassword = "reset_password"
+	AuditActionUnlock        = "unlock"
+)
+
+const (
+	AuditEntityEmployee      = "employee"
+	AuditEntityRole          = "role"
+	AuditEntityRolePromotion = "role_promotion"
+	AuditEntityUnit          = "unit"
+	AuditEntityPosition      = "position"
+	AuditEntityAssignment    = "employee_assignment"
+	AuditEntityReligion      = "religion"
+	AuditEntityGrade         = "grade"
+	AuditEntityEchelon       = "echelon"
+	AuditEntityUnitScope     = "unit_scope"
+	AuditEntityUnitGroup     = "unit_group"
+	AuditEntityFormation     = "formation"
+	AuditEntityTransfer      = "transfer_request"
+	AuditEntityChangeRequest = "profile_change_request"
+	AuditEntityTwoFactor     = "two_factor"
+)
+
+// AuditMeta berisi informasi request yang tidak dimiliki usecase (ip dan user agent)
+type AuditMeta struct {
+	IPAddress string
+	UserAgent string
+}
+
+// Entry membuat audit log untuk actor dan entity tertentu, before/after diisi oleh pemanggil
+func (m AuditMeta) Entry(actorID string, action string, entityType string, entityID interface{}) *AuditLog {
+	return &AuditLog{
+		ActorID:    actorID,
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   fmt.Sprint(entityID),
+		IPAddress:  m.IPAddress,
+		UserAgent:  m.UserAgent,
+	}
+}
+
+type AuditFilter struct {
+	EntityType string
+	EntityID   string
+	ActorID    string
+	Action     string
+	From       *time.Time
+	To         *time.Time
+	Page       int
+	PageSize   int
+}
+
+type AuditInterface interface {
+	Save(log *AuditLog) error
+	FindAll(filter AuditFilter) ([]AuditLog, int, error)
+}
@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrChangeRequestReviewed dikembalikan saat change request sudah direview oleh permintaan lain
+var ErrChangeRequestReviewed = errors.New("change request has already been reviewed")
+
+const (
+	ChangeRequestPending  = "pending"
+	ChangeRequestApproved = "approved"
+	ChangeRequestRejected = "rejected"
+)
+
+// ProfileChanges adalah data diri yang tidak boleh diubah langsung lewat /me, field kosong berarti tidak diubah
+type ProfileChanges struct {
+	FullName     string     `json:"full_name,omitempty"`
+	PlaceOfBirth string     `json:"place_of_birth,omitempty"`
+	DateOfBirth  *time.Time `json:"date_of_birth,omitempty"`
+	Gender       string     `json:"gender,omitempty"`
+	GradeID      int        `json:"grade_id,omitempty"`
+	EchelonID    int        `json:"echelon_id,omitempty"`
+}
+
+func (c *ProfileChanges) IsEmpty() bool {
+	return c.FullName == "" && c.PlaceOfBirth == "" && c.DateOfBirth == nil && c.Gender == "" &&
+		c.GradeID == 0 && c.EchelonID == 0
+}
+
+// ProfileChangeRequest adalah pengajuan perubahan data diri oleh employee yang harus disetujui kepegawaian
+type ProfileChangeRequest struct {
+	ID            int                     `json:"id" db:"id"`
+	EmployeeID    string                  `json:"employee_id" db:"employee_id"`
+	NIP           string                  `json:"nip"`
+	EmployeeName  string                  `json:"employee_name"`
+	Changes       ProfileChanges          `json:"changes" db:"changes"`
+	Note          string                  `json:"note" db:"note"` // alasan dari employee
+	Status        string                  `json:"status" db:"status"`
+	ReviewedBy    *string                 `json:"reviewed_by" db:"reviewed_by"`
+	ReviewerName  string                  `json:"reviewer_name,omitempty"`
+	ReviewComment string                  `json:"review_comment" db:"review_comment"`
+	ReviewedAt    *time.Time              `json:"reviewed_at" db:"reviewed_at"`
+	CreatedAt     time.Time               `json:"created_at" db:"created_at"`
+	Documents     []ChangeRequestDocument `json:"documents,omitempty"`
+}
+
+// ChangeRequestDocument adalah dokumen pendukung (misal akta atau SK) yang disimpan di S3
+type ChangeRequestDocument struct {
+	ID          int       `json:"id" db:"id"`
+	RequestID   int       `json:"request_id" db:"request_id"`
+	FileName    string    `json:"file_name" db:"file_name"`
+	URL         string    `json:"url" db:"url"`
+	ContentType string    `json:"content_type" db:"content_type"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+type ChangeRequestFilter struct {
+	Status     string
+	EmployeeID string
+}
+
+type ChangeRequestInterface interface {
+	// Save menyimpan change request beserta dokumen pendukungnya
+	Save(request *ProfileChangeRequest) (*ProfileChangeRequest, error)
+	FindByID(id int) (*ProfileChangeRequest, error)
+	// FindAll mengembalikan change request tanpa dokumen, terlama lebih dulu agar antrean diproses berurutan
+	FindAll(filter ChangeRequestFilter) ([]ProfileChangeRequest, error)
+	// Review mengubah status pending menjadi approved atau rejected, ErrChangeRequestReviewed jika bukan pending
+	Review(request *ProfileChangeRequest) error
+	// Approve mengubah status pending menjadi approved lalu menyimpan employee dalam satu transaksi,
+	// ErrChangeRequestReviewed jika bukan pending dan employee tidak diubah
+	Approve(request *ProfileChangeRequest, employee *Employee, changedBy string) error
+}
@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+)
+
+// DateLayout adalah format tanggal tanpa jam pada request dan response
+const DateLayout = "2006-01-02"
+
+// Date adalah tanggal tanpa jam untuk kolom bertipe date. JSON menerima "2025-01-01" maupun
+// RFC3339 agar klien lama tetap bisa mengirim tanggal lengkap, dan selalu ditulis sebagai "2025-01-01".
+type Date struct {
+	time.Time
+}
+
+// NewDate membuang jam dari t
+func NewDate(t time.Time) Date {
+	return Date{time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)}
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(d.Format(DateLayout))
+}
+
+func (d *Date) UnmarshalJSON(data []byte) error {
+	var value *string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	if value == nil || *value == "" {
+		*d = Date{}
+		return nil
+	}
+	parsed, err := time.Parse(DateLayout, *value)
+	if err != nil {
+		parsed, err = time.Parse(time.RFC3339, *value)
+		if err != nil {
+			return err
+		}
+	}
+	*d = NewDate(parsed)
+	return nil
+}
+
+// Value menyimpan Date sebagai time.Time agar bisa dipakai langsung sebagai parameter query
+func (d Date) Value() (driver.Value, error) {
+	return d.Time, nil
+}
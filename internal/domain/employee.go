@@ -22,17 +22,130 @@ type Employee struct {
 	EchelonID    int       `json:"echelon_id" db:"echelon_id"`
 	CreatedAt    time.Time `json:"created_at" db:"created_at"`
 	ModifiedAt   time.Time `json:"modified_at" db:"modified_at"`
+
+	EmploymentStatus    string    `json:"employment_status" db:"employment_status"`
+	StatusEffectiveDate time.Time `json:"status_effective_date" db:"status_effective_date"`
+	StatusReason        string    `json:"status_reason" db:"status_reason"`
+}
+
+const (
+	EmploymentStatusActive    = "active"
+	EmploymentStatusOnLeave   = "on_leave"
+	EmploymentStatusRetired   = "retired"
+	EmploymentStatusResigned  = "resigned"
+	EmploymentStatusDismissed = "dismissed"
+	EmploymentStatusDeceased  = "deceased"
+)
+
+// ActiveEmploymentStatuses adalah status yang masih boleh login dan tampil di listing default
+var ActiveEmploymentStatuses = []string{EmploymentStatusActive, EmploymentStatusOnLeave}
+
+func IsValidEmploymentStatus(status string) bool {
+	switch status {
+	case EmploymentStatusActive, EmploymentStatusOnLeave, EmploymentStatusRetired,
+		EmploymentStatusResigned, EmploymentStatusDismissed, EmploymentStatusDeceased:
+		return true
+	}
+	return false
+}
+
+func (e *Employee) IsActive() bool {
+	return e.EmploymentStatus == EmploymentStatusActive || e.EmploymentStatus == EmploymentStatusOnLeave
+}
+
+type EmployeeStatusHistory struct {
+	ID            int       `json:"id" db:"id"`
+	EmployeeID    string    `json:"employee_id" db:"employee_id"`
+	FromStatus    string    `json:"from_status" db:"from_status"`
+	ToStatus      string    `json:"to_status" db:"to_status"`
+	EffectiveDate time.Time `json:"effective_date" db:"effective_date"`
+	Reason        string    `json:"reason" db:"reason"`
+	ChangedBy     string    `json:"changed_by" db:"changed_by"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+}
+
+// EmployeeVersion adalah snapshot employee yang berlaku sejak ValidFrom. ChangedBy kosong berarti
+// snapshot awal, yaitu kondisi employee sebelum history mulai dicatat.
+type EmployeeVersion struct {
+	ID            int64     `json:"id" db:"id"`
+	Version       int       `json:"version" db:"version"`
+	Employee      Employee  `json:"employee"`
+	ChangedBy     string    `json:"changed_by" db:"changed_by"`
+	ChangedByName string    `json:"changed_by_name,omitempty"`
+	ValidFrom     time.Time `json:"valid_from" db:"valid_from"`
+}
+
+type EmployeeFieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// EmployeeHistory adalah satu versi employee beserta field yang berubah dari versi sebelumnya
+type EmployeeHistory struct {
+	Version       int                   `json:"version"`
+	ChangedAt     time.Time             `json:"changed_at"`
+	ChangedBy     string                `json:"changed_by"`
+	ChangedByName string                `json:"changed_by_name,omitempty"`
+	Changes       []EmployeeFieldChange `json:"changes"`
+}
+
+// EmployeeFilter dipakai untuk listing employee dengan filter, sort dan pagination.
+// Jika CursorValues diisi maka pagination memakai keyset, Page diabaikan.
+type EmployeeFilter struct {
+	GradeID       int
+	EchelonID     int
+	ReligionID    string
+	Gender        string
+	UnitID        int
+	RoleID        string
+	BirthYearFrom int
+	BirthYearTo   int
+	Statuses      []string // kosong berarti semua status
+	ScopeUnitIDs  []int    // batasan unit scope proposer, kosong berarti tanpa batasan
+	Sort          []SortField
+	Page          int
+	PageSize      int
+	CursorValues  []string
+}
+
+// EmployeeSortFields adalah kolom yang boleh dipakai pada parameter sort
+var EmployeeSortFields = map[string]bool{
+	"nip":           true,
+	"full_name":     true,
+	"date_of_birth": true,
+	"gender":        true,
+	"grade_id":      true,
+	"echelon_id":    true,
+	"religion_id":   true,
+	"role_id":       true,
+	"created_at":    true,
+	"modified_at":   true,
 }
 
 type EmployeeInterface interface {
 	FindAll() ([]Employee, error)
+	// FindPage mengembalikan paling banyak PageSize+1 baris beserta total baris yang cocok dengan filter
+	FindPage(filter EmployeeFilter) ([]Employee, int, error)
 	FindByID(id string) (*Employee, error)
-	Save(employee *Employee) (*Employee, error)
-	Update(employee *Employee) (*Employee, error)
+	// Save, SaveBatch, Update dan UpdateStatus juga mencatat versi baru employee atas nama changedBy
+	Save(employee *Employee, changedBy string) (*Employee, error)
+	// SaveBatch menyimpan seluruh employee dalam satu transaksi, gagal satu berarti gagal semua
+	SaveBatch(employees []*Employee, changedBy string) error
+	Update(employee *Employee, changedBy string) (*Employee, error)
 	UploadProfileImage(id string, fileName string) (string, error)
 	Delete(id string) error
 	FindByNIP(nip string) (*Employee, error)
 	FindByName(name string) ([]Employee, error)
 	FindByUnit(unitID int) ([]Employee, error)
-	Search(input string) ([]Employee, error)
+	// Search mencari berdasarkan nip atau nama, scopeUnitIDs membatasi ke employee dengan assignment aktif
+	// di unit tersebut dan kosong berarti tanpa batasan
+	Search(input string, scopeUnitIDs []int) ([]Employee, error)
+	// UpdateStatus mengubah employment status dan mencatatnya ke tabel history dalam satu transaksi
+	UpdateStatus(history *EmployeeStatusHistory) error
+	FindStatusHistory(employeeID string) ([]EmployeeStatusHistory, error)
+	// FindVersions mengembalikan seluruh versi employee, versi terlama lebih dulu
+	FindVersions(employeeID string) ([]EmployeeVersion, error)
+	// FindVersionAsOf mengembalikan versi terakhir yang sudah berlaku sebelum asOf, sql.ErrNoRows jika tidak ada
+	FindVersionAsOf(employeeID string, asOf time.Time) (*EmployeeVersion, error)
 }
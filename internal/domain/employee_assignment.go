@@ -1,35 +1,101 @@
 package domain
 
 import (
+	"errors"
 	"time"
 )
 
+// ErrAssignmentOverlap dikembalikan saat periode jabatan definitif beririsan dengan periode lain milik employee yang sama
+var ErrAssignmentOverlap = errors.New("assignment period overlaps")
+
+// EmployeeAssignment adalah satu periode penempatan employee pada unit dan posisi.
+// Periode aktif selama hari ini berada di antara StartDate dan EndDate, EndDate nil berarti tanpa batas.
+// Hanya assignment primary yang tidak boleh dirangkap, acting (Plt/Plh) dan additional boleh berjalan bersamaan.
 type EmployeeAssignment struct {
-	EmployeeID string    `json:"employee_id" db:"employee_id"`
-	UnitID     int       `json:"unit_id" db:"unit_id"`
-	PositionID int       `json:"position_id" db:"position_id"`
-	IsActive   bool      `json:"is_active" db:"is_active"`
-	AssignedAt time.Time `json:"assigned_at" db:"assigned_at"`
-	CreatedAt  time.Time `json:"created_at" db:"created_at"`
-	ModifiedAt time.Time `json:"modified_at" db:"modified_at"`
+	ID           int    `json:"id" db:"id"`
+	EmployeeID   string `json:"employee_id" db:"employee_id"`
+	UnitID       int    `json:"unit_id" db:"unit_id"`
+	PositionID   int    `json:"position_id" db:"position_id"`
+	StartDate    Date   `json:"start_date" db:"start_date"`
+	EndDate      *Date  `json:"end_date" db:"end_date"`
+	DecreeNumber string `json:"decree_number" db:"decree_number"`
+	Reason       string `json:"reason" db:"reason"`
+	Type         string `json:"assignment_type" db:"assignment_type"`
+	IsActive     bool   `json:"is_active"`
+	// OverrideFormation mengizinkan assignment melebihi kuota formasi
+	OverrideFormation bool      `json:"override_formation"`
+	CreatedAt         time.Time `json:"created_at" db:"created_at"`
+	ModifiedAt        time.Time `json:"modified_at" db:"modified_at"`
 }
 
 type EmployeeAssignmentResponse struct {
-	EmployeeID   string    `json:"employee_id"`
-	UnitID       int       `json:"unit_id"`
-	PositionID   int       `json:"position_id"`
-	EmployeeName string    `json:"employee_name"`
-	UnitName     string    `json:"unit_name"`
-	PositionName string    `json:"position_name"`
-	IsActive     bool      `json:"is_active"`
-	AssignedAt   time.Time `json:"assigned_at"`
+	ID           int        `json:"id"`
+	EmployeeID   string     `json:"employee_id"`
+	UnitID       int        `json:"unit_id"`
+	PositionID   int        `json:"position_id"`
+	EmployeeName string     `json:"employee_name"`
+	UnitName     string     `json:"unit_name"`
+	PositionName string     `json:"position_name"`
+	IsHead       bool       `json:"is_head"` // posisi kepala unit
+	StartDate    time.Time  `json:"start_date"`
+	EndDate      *time.Time `json:"end_date"`
+	DecreeNumber string     `json:"decree_number"`
+	Reason       string     `json:"reason"`
+	Type         string     `json:"assignment_type"`
+	IsActive     bool       `json:"is_active"`
+}
+
+const (
+	AssignmentReasonMutation   = "mutasi"
+	AssignmentReasonPromotion  = "promotion"
+	AssignmentReasonSecondment = "secondment"
+)
+
+func IsValidAssignmentReason(reason string) bool {
+	switch reason {
+	case AssignmentReasonMutation, AssignmentReasonPromotion, AssignmentReasonSecondment:
+		return true
+	}
+	return false
+}
+
+const (
+	AssignmentTypePrimary    = "primary"
+	AssignmentTypeActing     = "acting"     // Plt/Plh, wajib memiliki tanggal berakhir
+	AssignmentTypeAdditional = "additional" // tugas tambahan
+)
+
+func IsValidAssignmentType(assignmentType string) bool {
+	switch assignmentType {
+	case AssignmentTypePrimary, AssignmentTypeActing, AssignmentTypeAdditional:
+		return true
+	}
+	return false
+}
+
+// Overlaps memeriksa apakah periode assignment beririsan dengan periode [start, end], end nil berarti tanpa batas
+func (a *EmployeeAssignmentResponse) Overlaps(start time.Time, end *time.Time) bool {
+	if a.EndDate != nil && a.EndDate.Before(start) {
+		return false
+	}
+	if end != nil && end.Before(a.StartDate) {
+		return false
+	}
+	return true
 }
 
 type EmployeeAssignmentInterface interface {
+	// TransactionalAssignment menyimpan periode baru. Assignment primary menutup periode primary yang masih
+	// terbuka sehari sebelum StartDate dan dibatasi kuota formasi (ErrFormationFull jika OverrideFormation tidak diset).
+	// ErrAssignmentOverlap dikembalikan jika periode primary masih beririsan, misal karena assignment bersamaan.
 	TransactionalAssignment(employeeAssignment *EmployeeAssignment) error
-	Deactivate(employeeID string, unitID int, positionID int) error
+	// Deactivate menutup periode aktif pada unit dan posisi tersebut dengan endDate
+	Deactivate(employeeID string, unitID int, positionID int, endDate time.Time) error
 	FindAll() ([]EmployeeAssignmentResponse, error)
 	FindByID(employeeID string, unitID int, positionID int) (*EmployeeAssignmentResponse, error)
-	FindByEmployeeID(employeeID string) (*EmployeeAssignmentResponse, error)
+	// FindByEmployeeID mengembalikan seluruh assignment aktif employee, primary lebih dulu
+	FindByEmployeeID(employeeID string) ([]EmployeeAssignmentResponse, error)
 	FindByUnitID(unitID int) ([]EmployeeAssignmentResponse, error)
+	// FindHistoryByEmployeeID mengembalikan seluruh periode assignment employee, terbaru lebih dulu
+	FindHistoryByEmployeeID(employeeID string) ([]EmployeeAssignmentResponse, error)
 }
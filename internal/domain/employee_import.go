@@ -0,0 +1,17 @@
+package domain
+
+type EmployeeImportRow struct {
+	Row    int      `json:"row"`
+	NIP    string   `json:"nip"`
+	Valid  bool     `json:"valid"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+type EmployeeImportReport struct {
+	DryRun      bool                `json:"dry_run"`
+	Committed   bool                `json:"committed"`
+	TotalRows   int                 `json:"total_rows"`
+	ValidRows   int                 `json:"valid_rows"`
+	InvalidRows int                 `json:"invalid_rows"`
+	Rows        []EmployeeImportRow `json:"rows"`
+}
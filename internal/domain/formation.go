@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrFormationFull dikembalikan saat assignment akan melebihi kuota formasi unit dan posisi
+var ErrFormationFull = errors.New("formation is full")
+
+// Formation adalah jumlah kursi (kuota) sebuah posisi pada sebuah unit.
+// Filled dihitung dari assignment aktif employee yang masih bekerja.
+type Formation struct {
+	ID           int       `json:"id" db:"id"`
+	UnitID       int       `json:"unit_id" db:"unit_id"`
+	UnitName     string    `json:"unit_name"`
+	PositionID   int       `json:"position_id" db:"position_id"`
+	PositionName string    `json:"position_name"`
+	Quota        int       `json:"quota" db:"quota"`
+	Filled       int       `json:"filled"`
+	Vacant       int       `json:"vacant"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	ModifiedAt   time.Time `json:"modified_at" db:"modified_at"`
+}
+
+// VacancyReport adalah rekap formasi yang masih memiliki kursi kosong
+type VacancyReport struct {
+	TotalVacant int         `json:"total_vacant"`
+	Vacancies   []Formation `json:"vacancies"`
+}
+
+type FormationInterface interface {
+	FindByUnitID(unitID int) ([]Formation, error)
+	// Save menyimpan kuota formasi, kuota lama pada unit dan posisi yang sama akan diganti
+	Save(formation *Formation) (*Formation, error)
+	Delete(unitID int, positionID int) error
+	// FindVacancies mengembalikan formasi dengan kursi kosong, unitID 0 berarti seluruh unit
+	FindVacancies(unitID int) ([]Formation, error)
+}
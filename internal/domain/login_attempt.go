@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"time"
+)
+
+// Prefix key login attempt, satu baris per nip dan satu baris per ip
+const (
+	LoginAttemptKeyNIP = "nip:"
+	LoginAttemptKeyIP  = "ip:"
+)
+
+// LoginAttempt mencatat kegagalan login berturut-turut untuk sebuah key.
+// SinceLastFailure dan LockRemaining dihitung oleh database agar tidak bergantung pada zona waktu server.
+type LoginAttempt struct {
+	Key              string        `json:"key" db:"key"`
+	FailedCount      int           `json:"failed_count" db:"failed_count"`
+	LastFailedAt     time.Time     `json:"last_failed_at" db:"last_failed_at"`
+	LockedUntil      *time.Time    `json:"locked_until" db:"locked_until"`
+	SinceLastFailure time.Duration `json:"-"`
+	LockRemaining    time.Duration `json:"-"`
+}
+
+type LoginAttemptInterface interface {
+	FindByKeys(keys []string) ([]LoginAttempt, error)
+	// RecordFailure menambah hitungan gagal secara atomik. Hitungan dimulai lagi dari 1
+	// jika kegagalan terakhir sudah lebih lama dari window.
+	RecordFailure(key string, window time.Duration) (*LoginAttempt, error)
+	Lock(key string, duration time.Duration) error
+	Reset(key string) error
+}
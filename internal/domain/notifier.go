@@ -0,0 +1,7 @@
+package domain
+
+// Notifier mengirim pesan ke employee. Implementasi bisa diganti (log, file, email, sms)
+// tanpa mengubah usecase.
+type Notifier interface {
+	Notify(employee *Employee, subject string, message string) error
+}
@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"time"
+)
+
+// PasswordResetToken adalah token lupa password yang hanya bisa dipakai sekali.
+// Token disimpan dalam bentuk hash, token asli hanya dikirim lewat notifier.
+type PasswordResetToken struct {
+	ID         int        `json:"id" db:"id"`
+	EmployeeID string     `json:"employee_id" db:"employee_id"`
+	TokenHash  string     `json:"-" db:"token_hash"`
+	ExpiresAt  time.Time  `json:"expires_at" db:"expires_at"`
+	UsedAt     *time.Time `json:"used_at" db:"used_at"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+}
+
+type PasswordChange struct {
+	OldPassword string `json:"old_password"`
+	NewPassword string `json:"new_password"`
+}
+
+type PasswordReset struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
+
+type PasswordInterface interface {
+	// UpdatePassword mengganti hash password dan flag wajib ganti password saat login berikutnya
+	UpdatePassword(employeeID string, hash string, mustChange bool) error
+	MustChangePassword(employeeID string) (bool, error)
+	// SaveResetToken menyimpan token baru yang berlaku selama ttl sekaligus membatalkan token lama
+	// yang belum terpakai. ExpiresAt dihitung dari now() database.
+	SaveResetToken(token *PasswordResetToken, ttl time.Duration) (*PasswordResetToken, error)
+	FindResetToken(tokenHash string) (*PasswordResetToken, error)
+	// ResetPassword menandai token terpakai dan mengganti password dalam satu transaksi.
+	// Mengembalikan false jika token sudah terpakai atau expired.
+	ResetPassword(tokenID int, hash string) (bool, error)
+}
@@ -0,0 +1,55 @@
+package domain
+
+/*
+create table achmadnr.permissions(
+
+	id varchar(64) primary key,
+	description text not null,
+	unit_scoped boolean not null default false,
+	created_at timestamp default now()
+
+);
+*/
+type Permission struct {
+	ID          string `json:"id" db:"id"` // format <resource>.<aksi>, contoh employee.export
+	Description string `json:"description" db:"description"`
+	// UnitScoped berarti permission boleh diberikan dengan scope unit
+	UnitScoped bool `json:"unit_scoped" db:"unit_scoped"`
+}
+
+// Scope permission pada role
+const (
+	PermissionScopeUnit   = "unit"   // terbatas pada unit scope pemegang role
+	PermissionScopeGlobal = "global" // seluruh data
+)
+
+// RolePermission adalah permission yang diberikan ke role beserta scope-nya
+type RolePermission struct {
+	PermissionID string `json:"permission_id" db:"permission_id"`
+	Scope        string `json:"scope" db:"scope"`
+}
+
+// EffectivePermission adalah permission yang benar-benar dimiliki pemegang role, termasuk
+// permission bawaan setiap user yang login seperti mengakses data miliknya sendiri
+type EffectivePermission struct {
+	PermissionID string `json:"permission_id"`
+	Description  string `json:"description"`
+	Scope        string `json:"scope"`
+	Inherited    bool   `json:"inherited"` // true jika berasal dari permission bawaan, bukan dari role
+}
+
+// PermissionSet memetakan id permission ke scope yang diberikan
+type PermissionSet map[string]string
+
+// PermissionSet mengubah permission role menjadi map id permission ke scope
+func (r *Role) PermissionSet() PermissionSet {
+	set := PermissionSet{}
+	for _, permission := range r.Permissions {
+		set[permission.PermissionID] = permission.Scope
+	}
+	return set
+}
+
+type PermissionInterface interface {
+	FindAll() ([]Permission, error)
+}
@@ -7,6 +7,7 @@ import (
 type Position struct {
 	ID         int       `json:"id" db:"id"`
 	Name       string    `json:"name" db:"name"`
+	IsHead     bool      `json:"is_head" db:"is_head"` // pemegang posisi ini adalah kepala unit
 	CreatedAt  time.Time `json:"created_at" db:"created_at"`
 	ModifiedAt time.Time `json:"modified_at" db:"modified_at"`
 }
@@ -0,0 +1,12 @@
+package domain
+
+// Principal adalah user yang sedang login menurut token akses. RoleID dan Permissions diambil saat
+// token terbit dan hanya informatif, pengecekan akses memakai role_id employee saat ini lewat cache role.
+// Token terbatas (ganti password, 2FA) tidak membawa role sehingga RoleID kosong.
+type Principal struct {
+	UserID      string        `json:"user_id"`
+	RoleID      string        `json:"role_id"`
+	Permissions PermissionSet `json:"permissions"`
+	// Purpose diisi jika token adalah token terbatas
+	Purpose string `json:"purpose,omitempty"`
+}
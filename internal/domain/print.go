@@ -18,9 +18,40 @@ type PrintEmployee struct {
 	PhoneNumber  string    `json:"phone_number"`
 	NPWP         string    `json:"npwp"`
 	PhotoURL     string    `json:"photo_url"`
+	// CurrentRoles berisi seluruh jabatan aktif, hanya diisi saat mencetak satu employee
+	CurrentRoles []EmployeeAssignmentResponse `json:"current_roles,omitempty"`
 }
+
+// PrintEmployeeCV adalah data untuk Daftar Riwayat Hidup satu employee
+type PrintEmployeeCV struct {
+	Employee    *PrintEmployee
+	Photo       []byte // kosong jika foto tidak dapat diambil
+	Assignments []EmployeeAssignmentResponse
+}
+
+// PrintEmployeeCard adalah data kartu pegawai, Token disimpan pada QR code
+type PrintEmployeeCard struct {
+	Employee *PrintEmployee
+	Photo    []byte
+	Token    string
+	IssuedAt time.Time
+}
+
+// CardVerification adalah hasil verifikasi publik QR code kartu pegawai
+type CardVerification struct {
+	NIP              string    `json:"nip"`
+	FullName         string    `json:"full_name"`
+	Jabatan          string    `json:"jabatan"`
+	Unit             string    `json:"unit_kerja"`
+	EmploymentStatus string    `json:"employment_status"`
+	IsActive         bool      `json:"is_active"`
+	IssuedAt         time.Time `json:"issued_at"`
+}
+
 type PrintInterface interface {
 	PrintAll() ([]PrintEmployee, error)
 	PrintByNIP(id string) (*PrintEmployee, error)
 	PrintByUnit(unitID int) ([]PrintEmployee, error)
+	StreamAll(fn func(*PrintEmployee) error) error
+	StreamByUnit(unitID int, fn func(*PrintEmployee) error) error
 }
@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"time"
+)
+
+// RefreshToken disimpan dalam bentuk hash. FamilyID mengelompokkan seluruh
+// token hasil rotasi dari satu kali login. TwoFactorVerified menandai login yang
+// melewati kode 2FA dan diwariskan ke token hasil rotasi.
+type RefreshToken struct {
+	ID                string     `json:"id" db:"id"`
+	FamilyID          string     `json:"family_id" db:"family_id"`
+	EmployeeID        string     `json:"employee_id" db:"employee_id"`
+	TokenHash         string     `json:"-" db:"token_hash"`
+	TwoFactorVerified bool       `json:"two_factor_verified" db:"two_factor_verified"`
+	ExpiresAt         time.Time  `json:"expires_at" db:"expires_at"`
+	RevokedAt         *time.Time `json:"revoked_at" db:"revoked_at"`
+	ReplacedBy        *string    `json:"replaced_by" db:"replaced_by"`
+	CreatedAt         time.Time  `json:"created_at" db:"created_at"`
+}
+
+type RefreshTokenInterface interface {
+	Save(token *RefreshToken) (*RefreshToken, error)
+	FindByID(id string) (*RefreshToken, error)
+	// Rotate menandai token lama sebagai terpakai dan menyimpan token baru dalam satu transaksi.
+	// Mengembalikan false jika token lama sudah tidak aktif (indikasi reuse).
+	Rotate(oldID string, newToken *RefreshToken) (bool, error)
+	RevokeFamily(familyID string) error
+	RevokeAllByEmployeeID(employeeID string) error
+}
+
+// AuthTokens adalah hasil login dan refresh token. Jika MustChangePassword atau TwoFactorSetupRequired true, AccessToken
+// hanya berlaku untuk mengganti password atau mengaktifkan 2FA dan RefreshToken kosong. Jika
+// TwoFactorRequired true, hanya TwoFactorToken yang diisi dan login dilanjutkan dengan kode 2FA.
+type AuthTokens struct {
+	AccessToken            string `json:"access_token,omitempty"`
+	RefreshToken           string `json:"refresh_token,omitempty"`
+	TwoFactorToken         string `json:"two_factor_token,omitempty"`
+	MustChangePassword     bool   `json:"must_change_password,omitempty"`
+	TwoFactorRequired      bool   `json:"two_factor_required,omitempty"`
+	TwoFactorSetupRequired bool   `json:"two_factor_setup_required,omitempty"`
+}
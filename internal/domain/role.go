@@ -1,25 +1,33 @@
 package domain
 
 import (
+	"errors"
 	"time"
 )
 
+var ErrRolePromotionExists = errors.New("role promotion already exists")
+
 type Role struct {
-	ID                        string    `json:"id" db:"id"`
-	Name                      string    `json:"name" db:"name"`
-	Level                     int       `json:"level" db:"level"`
-	Description               string    `json:"description" db:"description"`
-	CanAddRole                bool      `json:"can_add_role" db:"can_add_role"`
-	CanAddEmployee            bool      `json:"can_add_employee" db:"can_add_employee"`
-	CanAddUnit                bool      `json:"can_add_unit" db:"can_add_unit"`
-	CanAddPosition            bool      `json:"can_add_position" db:"can_add_position"`
-	CanAddEchelon             bool      `json:"can_add_echelon" db:"can_add_echelon"`
-	CanAddReligion            bool      `json:"can_add_religion" db:"can_add_religion"`
-	CanAddGrade               bool      `json:"can_add_grade" db:"can_add_grade"`
-	CanAssignEmployeeInternal bool      `json:"can_assign_employee_internal" db:"can_assign_employee_internal"`
-	CanAssignEmployeeGlobal   bool      `json:"can_assign_employee_global" db:"can_assign_employee_global"`
-	CreatedAt                 time.Time `json:"created_at" db:"created_at"`
-	ModifiedAt                time.Time `json:"modified_at" db:"modified_at"`
+	ID          string    `json:"id" db:"id"`
+	Name        string    `json:"name" db:"name"`
+	Level       int       `json:"level" db:"level"`
+	Description string    `json:"description" db:"description"`
+	Require2FA  bool      `json:"require_2fa" db:"require_2fa"` // pemegang role wajib login dengan 2FA
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	ModifiedAt  time.Time `json:"modified_at" db:"modified_at"`
+	// Permissions disimpan di achmadnr.role_permissions
+	Permissions []RolePermission `json:"permissions"`
+}
+
+// RoleUpdate adalah payload perubahan role, field yang tidak dikirim tidak diubah.
+// Permissions nil berarti permission role tetap, daftar kosong mencabut seluruh permission.
+type RoleUpdate struct {
+	ID          string            `json:"-"`
+	Name        string            `json:"name"`
+	Level       int               `json:"level"`
+	Description string            `json:"description"`
+	Require2FA  *bool             `json:"require_2fa"`
+	Permissions *[]RolePermission `json:"permissions"`
 }
 
 /*
@@ -49,5 +57,11 @@ type RoleInterface interface {
 	Update(role *Role) (*Role, error)
 	Delete(id string) error
 	FindByName(name string) (*Role, error)
+	CountEmployees(id string) (int, error)
 	FindPromoteRole(promoterRoleID string) ([]RolePromotion, error)
+	FindAllPromotion() ([]RolePromotion, error)
+	SavePromotion(rolePromotion *RolePromotion) (*RolePromotion, error)
+	DeletePromotion(rolePromotion *RolePromotion) error
+	// EmployeeRoleChanged dipanggil setelah role_id employee diubah agar cache role employee tidak basi
+	EmployeeRoleChanged(employeeID string)
 }
@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrTransferStatusChanged dikembalikan saat status transfer sudah diubah oleh permintaan lain
+var ErrTransferStatusChanged = errors.New("transfer status has changed")
+
+// ErrTransferOpenExists dikembalikan saat employee masih memiliki usulan mutasi yang berjalan
+var ErrTransferOpenExists = errors.New("employee already has an open transfer request")
+
+const (
+	TransferStatusDraft    = "draft"
+	TransferStatusProposed = "proposed"
+	TransferStatusAccepted = "accepted"
+	TransferStatusRejected = "rejected"
+	TransferStatusExecuted = "executed"
+)
+
+// TransferRequest adalah usulan mutasi employee: dibuat dan diusulkan kepala unit asal,
+// diterima kepala unit tujuan, lalu dieksekusi kepegawaian menjadi assignment baru
+type TransferRequest struct {
+	ID             int                `json:"id" db:"id"`
+	EmployeeID     string             `json:"employee_id" db:"employee_id"`
+	EmployeeName   string             `json:"employee_name"`
+	FromUnitID     int                `json:"from_unit_id" db:"from_unit_id"`
+	FromUnitName   string             `json:"from_unit_name"`
+	ToUnitID       int                `json:"to_unit_id" db:"to_unit_id"`
+	ToUnitName     string             `json:"to_unit_name"`
+	ToPositionID   int                `json:"to_position_id" db:"to_position_id"`
+	ToPositionName string             `json:"to_position_name"`
+	StartDate      time.Time          `json:"start_date" db:"start_date"`
+	Reason         string             `json:"reason" db:"reason"`
+	DecreeNumber   string             `json:"decree_number" db:"decree_number"` // diisi saat eksekusi
+	AssignmentID   *int               `json:"assignment_id" db:"assignment_id"` // assignment hasil eksekusi
+	Status         string             `json:"status" db:"status"`
+	CreatedBy      string             `json:"created_by" db:"created_by"`
+	CreatedAt      time.Time          `json:"created_at" db:"created_at"`
+	ModifiedAt     time.Time          `json:"modified_at" db:"modified_at"`
+	Decisions      []TransferDecision `json:"decisions,omitempty"`
+}
+
+// TransferDecision mencatat setiap perpindahan status beserta komentar pengambil keputusan
+type TransferDecision struct {
+	ID         int       `json:"id" db:"id"`
+	TransferID int       `json:"transfer_id" db:"transfer_id"`
+	FromStatus string    `json:"from_status" db:"from_status"` // kosong saat transfer dibuat
+	ToStatus   string    `json:"to_status" db:"to_status"`
+	ActorID    string    `json:"actor_id" db:"actor_id"`
+	ActorName  string    `json:"actor_name"`
+	Comment    string    `json:"comment" db:"comment"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+type TransferInterface interface {
+	// Save menyimpan transfer baru berstatus draft beserta keputusan pembuatannya.
+	// ErrTransferOpenExists dikembalikan jika employee masih memiliki transfer draft, proposed atau accepted.
+	Save(transfer *TransferRequest, decision *TransferDecision) (*TransferRequest, error)
+	FindByID(id int) (*TransferRequest, error)
+	// FindAll mengembalikan transfer terbaru lebih dulu, status kosong berarti semua status
+	FindAll(status string) ([]TransferRequest, error)
+	FindDecisions(transferID int) ([]TransferDecision, error)
+	// Transition mengubah status dari decision.FromStatus ke decision.ToStatus dan mencatat keputusannya.
+	// ErrTransferStatusChanged dikembalikan jika status saat ini bukan decision.FromStatus.
+	Transition(transfer *TransferRequest, decision *TransferDecision) error
+	// Execute mengklaim transfer berstatus decision.FromStatus, menyimpan assignment lewat langkah yang sama
+	// dengan TransactionalAssignment lalu mengubah status ke decision.ToStatus dalam satu transaksi.
+	// ErrTransferStatusChanged atau ErrFormationFull dikembalikan tanpa perubahan apa pun.
+	Execute(transfer *TransferRequest, decision *TransferDecision, assignment *EmployeeAssignment) error
+}
@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrTwoFactorEnabled dikembalikan saat enrolment dimulai ulang padahal 2FA sudah aktif
+var ErrTwoFactorEnabled = errors.New("two-factor authentication is already enabled")
+
+// TwoFactor menyimpan secret TOTP employee. Enabled false berarti enrolment belum dikonfirmasi.
+// LastUsedStep adalah time step terakhir yang diterima, kode dengan step yang sama atau lebih lama ditolak.
+type TwoFactor struct {
+	EmployeeID   string     `json:"employee_id" db:"employee_id"`
+	Secret       string     `json:"-" db:"secret"`
+	Enabled      bool       `json:"enabled" db:"enabled"`
+	LastUsedStep int64      `json:"-" db:"last_used_step"`
+	ConfirmedAt  *time.Time `json:"confirmed_at" db:"confirmed_at"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+}
+
+type TwoFactorStatus struct {
+	Enabled                bool       `json:"enabled"`
+	Required               bool       `json:"required"`
+	ConfirmedAt            *time.Time `json:"confirmed_at"`
+	RecoveryCodesRemaining int        `json:"recovery_codes_remaining"`
+}
+
+// TwoFactorEnrollment dikembalikan saat enrolment dimulai, QRCode berupa data URI png dari OtpauthURL
+type TwoFactorEnrollment struct {
+	Secret     string `json:"secret"`
+	OtpauthURL string `json:"otpauth_url"`
+	QRCode     string `json:"qr_code"`
+}
+
+// TwoFactorCode berisi kode TOTP atau salah satu recovery code
+type TwoFactorCode struct {
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+type TwoFactorInterface interface {
+	FindByEmployeeID(employeeID string) (*TwoFactor, error)
+	// SavePending menyimpan secret baru yang belum aktif, gagal jika 2FA sudah aktif
+	SavePending(employeeID string, secret string) error
+	// Enable mengaktifkan 2FA dan mengganti recovery code dalam satu transaksi.
+	// Mengembalikan false jika sudah aktif atau step sudah pernah dipakai.
+	Enable(employeeID string, step int64, recoveryCodeHashes []string) (bool, error)
+	// UseStep menandai time step terpakai, false jika step tidak lebih baru dari step terakhir
+	UseStep(employeeID string, step int64) (bool, error)
+	ReplaceRecoveryCodes(employeeID string, recoveryCodeHashes []string) error
+	// UseRecoveryCode menandai recovery code terpakai, false jika tidak ada atau sudah terpakai
+	UseRecoveryCode(employeeID string, codeHash string) (bool, error)
+	CountRecoveryCodes(employeeID string) (int, error)
+	// Delete menghapus secret beserta seluruh recovery code
+	Delete(employeeID string) error
+}
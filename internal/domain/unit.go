@@ -1,11 +1,15 @@
 package domain
 
 import (
+	"errors"
 	"time"
 )
 
+var ErrUnitCycle = errors.New("parent unit cannot be the unit itself or one of its sub units")
+
 type Unit struct {
 	ID          int       `json:"id" db:"id"`
+	ParentID    *int      `json:"parent_id" db:"parent_id"` // nil berarti unit teratas
 	Name        string    `json:"name" db:"name"`
 	Address     string    `json:"address" db:"address"`
 	Description string    `json:"description" db:"description"`
@@ -13,12 +17,35 @@ type Unit struct {
 	ModifiedAt  time.Time `json:"modified_at" db:"modified_at"`
 }
 
+// UnitHead adalah employee dengan assignment aktif pada posisi kepala unit
+type UnitHead struct {
+	EmployeeID   string `json:"employee_id"`
+	NIP          string `json:"nip"`
+	FullName     string `json:"full_name"`
+	PositionName string `json:"position_name"`
+}
+
+// UnitTree adalah unit beserta sub unit di bawahnya untuk bagan organisasi
+type UnitTree struct {
+	Unit
+	Depth          int         `json:"depth"`
+	Headcount      int         `json:"headcount"`       // employee aktif dengan assignment aktif langsung di unit ini
+	TotalHeadcount int         `json:"total_headcount"` // termasuk seluruh sub unit, employee dengan beberapa assignment dihitung sekali
+	Head           *UnitHead   `json:"head"`
+	Children       []*UnitTree `json:"children"`
+}
+
 type UnitInterface interface {
 	FindAll() ([]Unit, error)
 	FindByID(id int) (*Unit, error)
 	Save(unit *Unit) (*Unit, error)
+	// Update mengembalikan ErrUnitCycle jika parent baru adalah unit itu sendiri atau salah satu turunannya
 	Update(unit *Unit) (*Unit, error)
 	Delete(id int) error
 	FindByName(name string) ([]Unit, error)
 	Search(query string) ([]Unit, error)
+	// FindSubtreeIDs mengembalikan id unit beserta seluruh turunannya
+	FindSubtreeIDs(rootID int) ([]int, error)
+	// FindSubtree mengembalikan unit beserta seluruh turunannya secara datar, urut berdasarkan kedalaman
+	FindSubtree(rootID int) ([]UnitTree, error)
 }
@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrUnitGroupExists = errors.New("unit group name already exists")
+	ErrUnitGroupInUse  = errors.New("unit group is still used by unit scopes")
+)
+
+// UnitGroup adalah kumpulan unit dengan nama, misal seluruh unit di bawah satu biro yang tidak berada
+// dalam satu cabang pohon unit. Unit scope dapat diberikan atas seluruh unit dalam satu grup sekaligus.
+type UnitGroup struct {
+	ID          int       `json:"id" db:"id"`
+	Name        string    `json:"name" db:"name"`
+	Description string    `json:"description" db:"description"`
+	UnitIDs     []int     `json:"unit_ids"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	ModifiedAt  time.Time `json:"modified_at" db:"modified_at"`
+}
+
+type UnitGroupInterface interface {
+	FindAll() ([]UnitGroup, error)
+	FindByID(id int) (*UnitGroup, error)
+	// Save dan Update menyimpan grup beserta seluruh anggotanya, ErrUnitGroupExists jika nama sudah dipakai
+	Save(group *UnitGroup) (*UnitGroup, error)
+	Update(group *UnitGroup) (*UnitGroup, error)
+	// Delete mengembalikan ErrUnitGroupInUse jika grup masih dipakai unit scope
+	Delete(id int) error
+}
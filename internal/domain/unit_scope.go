@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"time"
+)
+
+// UnitScopeGrant memberi pemegang role assign internal wewenang atas unit tambahan atau seluruh unit dalam
+// satu unit group, tepat salah satu dari UnitID dan UnitGroupID yang diisi.
+// IncludeSubunits berarti seluruh unit di bawah unit tersebut ikut dikelola.
+type UnitScopeGrant struct {
+	ID              int       `json:"id" db:"id"`
+	EmployeeID      string    `json:"employee_id" db:"employee_id"`
+	UnitID          int       `json:"unit_id" db:"unit_id"`
+	UnitName        string    `json:"unit_name"`
+	UnitGroupID     int       `json:"unit_group_id" db:"unit_group_id"`
+	UnitGroupName   string    `json:"unit_group_name"`
+	IncludeSubunits bool      `json:"include_subunits" db:"include_subunits"`
+	GrantedBy       string    `json:"granted_by" db:"granted_by"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+}
+
+// UnitScope adalah hasil resolve unit yang boleh dikelola seorang employee
+type UnitScope struct {
+	All     bool  `json:"all"`
+	UnitIDs []int `json:"unit_ids"`
+}
+
+func (s *UnitScope) Contains(unitID int) bool {
+	if s.All {
+		return true
+	}
+	for _, id := range s.UnitIDs {
+		if id == unitID {
+			return true
+		}
+	}
+	return false
+}
+
+type UnitScopeInterface interface {
+	FindByEmployeeID(employeeID string) ([]UnitScopeGrant, error)
+	FindByID(id int) (*UnitScopeGrant, error)
+	Save(grant *UnitScopeGrant) (*UnitScopeGrant, error)
+	Delete(id int) error
+}
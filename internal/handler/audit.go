@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/achmadnr21/emploman/internal/domain"
+	"github.com/achmadnr21/emploman/internal/middleware"
+	"github.com/achmadnr21/emploman/internal/usecase"
+	"github.com/achmadnr21/emploman/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type AuditHandler struct {
+	uc *usecase.AuditUsecase
+}
+
+func NewAuditHandler(apiV *gin.RouterGroup, uc *usecase.AuditUsecase) {
+	auditHandler := &AuditHandler{
+		uc: uc,
+	}
+
+	audit := apiV.Group("/audit")
+	audit.Use(middleware.JWTAuthMiddleware)
+	{
+		audit.GET("", auditHandler.GetAll)
+	}
+}
+
+func (h *AuditHandler) GetAll(c *gin.Context) {
+	principal := middleware.GetPrincipal(c)
+	filter := domain.AuditFilter{
+		EntityType: c.Query("entity_type"),
+		EntityID:   c.Query("entity_id"),
+		ActorID:    c.Query("actor_id"),
+		Action:     c.Query("action"),
+	}
+	ints := map[string]*int{
+		"page":      &filter.Page,
+		"page_size": &filter.PageSize,
+	}
+	for key, target := range ints {
+		value := c.Query(key)
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid "+key))
+			return
+		}
+		*target = parsed
+	}
+	// from dan to berformat YYYY-MM-DD, to bersifat inklusif sampai akhir hari
+	if from := c.Query("from"); from != "" {
+		parsed, err := time.Parse("2006-01-02", from)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid from, use YYYY-MM-DD"))
+			return
+		}
+		filter.From = &parsed
+	}
+	if to := c.Query("to"); to != "" {
+		parsed, err := time.Parse("2006-01-02", to)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid to, use YYYY-MM-DD"))
+			return
+		}
+		parsed = parsed.AddDate(0, 0, 1)
+		filter.To = &parsed
+	}
+
+	logs, meta, err := h.uc.GetAll(principal, filter)
+	if err != nil {
+		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+		return
+	}
+	meta.Links.Self = c.Request.URL.RequestURI()
+	if meta.Page < meta.TotalPages {
+		next := c.Request.URL.Query()
+		next.Set("page", strconv.Itoa(meta.Page+1))
+		meta.Links.Next = c.Request.URL.Path + "?" + next.Encode()
+	}
+	c.JSON(http.StatusOK, utils.ResponseSuccessWithMeta("Get audit logs", logs, meta))
+}
+
+// auditMeta mengambil ip dan user agent request untuk dicatat pada audit log
+func auditMeta(c *gin.Context) domain.AuditMeta {
+	return domain.AuditMeta{
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+}
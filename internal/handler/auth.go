@@ -2,9 +2,11 @@ package handler
 
 import (
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/achmadnr21/emploman/internal/domain"
+	"github.com/achmadnr21/emploman/internal/middleware"
 	"github.com/achmadnr21/emploman/internal/usecase"
 	"github.com/achmadnr21/emploman/internal/utils"
 	"github.com/gin-gonic/gin"
@@ -24,6 +26,15 @@ func NewAuthHandler(apiV *gin.RouterGroup, uc *usecase.AuthUsecase) {
 	{
 		auth.POST("/login", AuthHandler.Login)
 		auth.POST("/refresh", AuthHandler.RefreshToken)
+		auth.POST("/logout", AuthHandler.Logout)
+		auth.POST("/logout-all", middleware.JWTAuthMiddleware, AuthHandler.LogoutAll)
+		auth.POST("/2fa", AuthHandler.VerifyTwoFactor) // POST /auth/2fa, Bearer two_factor_token dari login
+	}
+
+	employee := apiV.Group("/employee")
+	employee.Use(middleware.JWTAuthMiddleware)
+	{
+		employee.POST("/:nip/unlock", AuthHandler.UnlockAccount) // POST /employee/:nip/unlock
 	}
 }
 func (h *AuthHandler) Login(c *gin.Context) {
@@ -33,40 +44,110 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	token, refreshToken, err := h.uc.Login(employee.NIP, employee.Password)
+	tokens, err := h.uc.Login(employee.NIP, employee.Password, c.ClientIP())
 	if err != nil {
+		if throttled, ok := err.(*utils.TooManyRequestError); ok && throttled.RetryAfter > 0 {
+			c.Header("Retry-After", strconv.Itoa(throttled.RetryAfter))
+		}
 		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
 		return
 	}
+	c.JSON(http.StatusOK, utils.ResponseSuccess(loginMessage(tokens), tokens))
+}
 
-	var loginResponse = struct {
-		Token   string `json:"access_token"`
-		Refresh string `json:"refresh_token"`
-	}{
-		Token:   token,
-		Refresh: refreshToken,
-	}
-	c.JSON(http.StatusOK, utils.ResponseSuccess("Login successful", loginResponse))
+// VerifyTwoFactor menerima two_factor_token dari login pada header Authorization
+func (h *AuthHandler) VerifyTwoFactor(c *gin.Context) {
+	tokenString := bearerToken(c)
+	if tokenString == "" {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid request"))
+		return
+	}
+	var payload domain.TwoFactorCode
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid request"))
+		return
+	}
+	tokens, err := h.uc.VerifyTwoFactor(tokenString, payload, c.ClientIP())
+	if err != nil {
+		if throttled, ok := err.(*utils.TooManyRequestError); ok && throttled.RetryAfter > 0 {
+			c.Header("Retry-After", strconv.Itoa(throttled.RetryAfter))
+		}
+		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.ResponseSuccess(loginMessage(tokens), tokens))
 }
 
 func (h *AuthHandler) RefreshToken(c *gin.Context) {
-	tokenString := strings.Split(c.Request.Header.Get("Authorization"), "Bearer ")[1]
+	tokenString := bearerToken(c)
 	if tokenString == "" {
 		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid request"))
 		c.Abort()
 		return
 	}
-	token, refreshToken, err := h.uc.RefreshToken(tokenString)
+	tokens, err := h.uc.RefreshToken(tokenString)
 	if err != nil {
 		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
 		return
 	}
-	var refreshResponse = struct {
-		Token   string `json:"access_token"`
-		Refresh string `json:"refresh_token"`
-	}{
-		Token:   token,
-		Refresh: refreshToken,
+	// sesi role yang mewajibkan 2FA diturunkan menjadi token untuk mengaktifkan 2FA
+	if tokens.TwoFactorSetupRequired {
+		c.JSON(http.StatusOK, utils.ResponseSuccess(loginMessage(tokens), tokens))
+		return
+	}
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Token refreshed", tokens))
+}
+
+// Logout menerima refresh token pada header Authorization, sama seperti /refresh
+func (h *AuthHandler) Logout(c *gin.Context) {
+	tokenString := bearerToken(c)
+	if tokenString == "" {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid request"))
+		return
+	}
+	if err := h.uc.Logout(tokenString); err != nil {
+		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Logout successful", nil))
+}
+
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
+	principal := middleware.GetPrincipal(c)
+	if err := h.uc.LogoutAll(principal.UserID); err != nil {
+		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Logged out from all sessions", nil))
+}
+
+func (h *AuthHandler) UnlockAccount(c *gin.Context) {
+	principal := middleware.GetPrincipal(c)
+	if err := h.uc.UnlockAccount(principal, c.Param("nip"), auditMeta(c)); err != nil {
+		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Account unlocked", nil))
+}
+
+// loginMessage menjelaskan langkah berikutnya jika login belum selesai
+func loginMessage(tokens *domain.AuthTokens) string {
+	switch {
+	case tokens.TwoFactorRequired:
+		return "Two-factor code required"
+	case tokens.MustChangePassword:
+		return "Password must be changed before continuing"
+	case tokens.TwoFactorSetupRequired:
+		return "Two-factor authentication must be enabled before continuing"
+	default:
+		return "Login successful"
+	}
+}
+
+func bearerToken(c *gin.Context) string {
+	bearer := strings.Split(c.Request.Header.Get("Authorization"), "Bearer ")
+	if len(bearer) != 2 {
+		return ""
 	}
-	c.JSON(http.StatusOK, utils.ResponseSuccess("Token refreshed", refreshResponse))
+	return bearer[1]
 }
@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/achmadnr21/emploman/internal/domain"
+	"github.com/achmadnr21/emploman/internal/middleware"
+	"github.com/achmadnr21/emploman/internal/usecase"
+	"github.com/achmadnr21/emploman/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type ChangeRequestHandler struct {
+	uc *usecase.ChangeRequestUsecase
+}
+
+func NewChangeRequestHandler(apiV *gin.RouterGroup, uc *usecase.ChangeRequestUsecase) {
+	changeRequestHandler := &ChangeRequestHandler{
+		uc: uc,
+	}
+
+	me := apiV.Group("/me")
+	me.Use(middleware.JWTAuthMiddleware)
+	{
+		me.POST("/change-request", changeRequestHandler.Submit) // POST /me/change-request (multipart)
+		me.GET("/change-request", changeRequestHandler.GetMine) // GET /me/change-request
+	}
+
+	// antrean review untuk kepegawaian
+	changeRequest := apiV.Group("/change-request")
+	changeRequest.Use(middleware.JWTAuthMiddleware)
+	{
+		changeRequest.GET("", changeRequestHandler.GetQueue)             // GET /change-request?status=
+		changeRequest.GET("/:id", changeRequestHandler.GetByID)          // GET /change-request/:id
+		changeRequest.POST("/:id/approve", changeRequestHandler.Approve) // POST /change-request/:id/approve
+		changeRequest.POST("/:id/reject", changeRequestHandler.Reject)   // POST /change-request/:id/reject
+	}
+}
+
+func (h *ChangeRequestHandler) Submit(c *gin.Context) {
+	principal := middleware.GetPrincipal(c)
+	form, err := c.MultipartForm()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid form"))
+		return
+	}
+	changes := domain.ProfileChanges{
+		FullName:     c.PostForm("full_name"),
+		PlaceOfBirth: c.PostForm("place_of_birth"),
+		Gender:       c.PostForm("gender"),
+	}
+	if value := c.PostForm("date_of_birth"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid date_of_birth, use YYYY-MM-DD"))
+			return
+		}
+		changes.DateOfBirth = &parsed
+	}
+	ints := map[string]*int{
+		"grade_id":   &changes.GradeID,
+		"echelon_id": &changes.EchelonID,
+	}
+	for key, target := range ints {
+		value := c.PostForm(key)
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid "+key))
+			return
+		}
+		*target = parsed
+	}
+	request, err := h.uc.Submit(principal.UserID, changes, c.PostForm("note"), form.File["documents"], auditMeta(c))
+	if err != nil {
+		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Submit change request", request))
+}
+
+func (h *ChangeRequestHandler) GetMine(c *gin.Context) {
+	principal := middleware.GetPrincipal(c)
+	requests, err := h.uc.GetMine(principal.UserID)
+	if err != nil {
+		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Get my change requests", requests))
+}
+
+func (h *ChangeRequestHandler) GetQueue(c *gin.Context) {
+	principal := middleware.GetPrincipal(c)
+	requests, err := h.uc.GetQueue(principal, c.Query("status"))
+	if err != nil {
+		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Get change requests", requests))
+}
+
+func (h *ChangeRequestHandler) GetByID(c *gin.Context) {
+	principal := middleware.GetPrincipal(c)
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid ID"))
+		return
+	}
+	request, err := h.uc.GetByID(principal, idInt)
+	if err != nil {
+		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Get change request", request))
+}
+
+func (h *ChangeRequestHandler) Approve(c *gin.Context) {
+	principal := middleware.GetPrincipal(c)
+	idInt, comment, ok := reviewPayload(c)
+	if !ok {
+		return
+	}
+	request, err := h.uc.Approve(principal, idInt, comment, auditMeta(c))
+	if err != nil {
+		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Approve change request", request))
+}
+
+func (h *ChangeRequestHandler) Reject(c *gin.Context) {
+	principal := middleware.GetPrincipal(c)
+	idInt, comment, ok := reviewPayload(c)
+	if !ok {
+		return
+	}
+	request, err := h.uc.Reject(principal, idInt, comment, auditMeta(c))
+	if err != nil {
+		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Reject change request", request))
+}
+
+// reviewPayload membaca id dan komentar review, response error sudah ditulis jika ok false
+func reviewPayload(c *gin.Context) (int, string, bool) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid ID"))
+		return 0, "", false
+	}
+	var payload struct {
+		Comment string `json:"comment"`
+	}
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid input"))
+		return 0, "", false
+	}
+	return idInt, payload.Comment, true
+}
@@ -33,17 +33,13 @@ func (h *EchelonHandler) GetAll(c *gin.Context) {
 	c.JSON(200, utils.ResponseSuccess("Success", echelons))
 }
 func (h *EchelonHandler) AddEchelon(c *gin.Context) {
-	proposerId, exist := c.Get("user_id")
-	if !exist {
-		c.JSON(500, utils.ResponseError("User ID not found"))
-		return
-	}
+	principal := middleware.GetPrincipal(c)
 	var echelon domain.Echelon
 	if err := c.ShouldBindJSON(&echelon); err != nil {
 		c.JSON(400, utils.ResponseError("Invalid request"))
 		return
 	}
-	if err := h.uc.AddEchelon(proposerId.(string), &echelon); err != nil {
+	if err := h.uc.AddEchelon(principal, &echelon, auditMeta(c)); err != nil {
 		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
 		return
 	}
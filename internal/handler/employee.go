@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/achmadnr21/emploman/internal/domain"
 	"github.com/achmadnr21/emploman/internal/middleware"
@@ -27,6 +29,7 @@ func NewEmployeeHandler(apiV *gin.RouterGroup, uc *usecase.EmployeeUsecase) {
 		// Basic CRUD
 		employee.GET("", EmployeeHandler.GetAll)              // GET /employees
 		employee.POST("", EmployeeHandler.Add)                // POST /employees
+		employee.POST("/import", EmployeeHandler.Import)      // POST /employees/import?dry_run=false
 		employee.GET("/:nip", EmployeeHandler.GetByNIP)       // GET /employees/:nip
 		employee.PUT("/:nip", EmployeeHandler.UpdateEmployee) // PUT /employees/:nip
 
@@ -38,25 +41,100 @@ func NewEmployeeHandler(apiV *gin.RouterGroup, uc *usecase.EmployeeUsecase) {
 		employee.GET("/search", EmployeeHandler.Search)           // GET /employees/search
 
 		// Promotion
-		employee.PUT("/:nip/promote", EmployeeHandler.Promote) // PUT /employees/:nip/promote
+		employee.PUT("/:nip/promote", EmployeeHandler.Promote)        // PUT /employees/:nip/promote
+		employee.GET("/:nip/promote", EmployeeHandler.PromoteOptions) // GET /employees/:nip/promote
+
+		// Employment lifecycle
+		employee.PUT("/:nip/status", EmployeeHandler.ChangeStatus)             // PUT /employees/:nip/status
+		employee.POST("/:nip/status/restore", EmployeeHandler.RestoreStatus)   // POST /employees/:nip/status/restore
+		employee.GET("/:nip/status/history", EmployeeHandler.GetStatusHistory) // GET /employees/:nip/status/history
+
+		// Change history
+		employee.GET("/:nip/history", EmployeeHandler.GetHistory) // GET /employees/:nip/history
 
 	}
 }
 
+// GetAll contoh: /employee?page=2&page_size=50&grade_id=9&gender=P&birth_year_from=1980&sort=full_name,-date_of_birth
+// atau dengan cursor: /employee?cursor=<next_cursor>&page_size=50
 func (h *EmployeeHandler) GetAll(c *gin.Context) {
-	user_id, _ := c.Get("user_id")
-	employees, err := h.uc.GetAll(user_id.(string))
+	principal := middleware.GetPrincipal(c)
+	var filter domain.EmployeeFilter
+	ints := map[string]*int{
+		"page":            &filter.Page,
+		"page_size":       &filter.PageSize,
+		"grade_id":        &filter.GradeID,
+		"echelon_id":      &filter.EchelonID,
+		"unit_id":         &filter.UnitID,
+		"birth_year_from": &filter.BirthYearFrom,
+		"birth_year_to":   &filter.BirthYearTo,
+	}
+	for key, target := range ints {
+		value := c.Query(key)
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid "+key))
+			return
+		}
+		*target = parsed
+	}
+	filter.ReligionID = c.Query("religion_id")
+	filter.Gender = c.Query("gender")
+	filter.RoleID = c.Query("role_id")
+	if status := c.Query("status"); status != "" {
+		filter.Statuses = strings.Split(strings.ToLower(status), ",")
+	}
+	if sort := c.Query("sort"); sort != "" {
+		for _, field := range strings.Split(sort, ",") {
+			field = strings.TrimSpace(field)
+			if field == "" {
+				continue
+			}
+			filter.Sort = append(filter.Sort, domain.SortField{
+				Field: strings.TrimPrefix(field, "-"),
+				Desc:  strings.HasPrefix(field, "-"),
+			})
+		}
+	}
+
+	employees, meta, err := h.uc.GetAll(principal, filter, c.Query("cursor"))
 	if err != nil {
 		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
 		return
 	}
-	c.JSON(http.StatusOK, utils.ResponseSuccess("Get all employees", employees))
+	meta.Links.Self = c.Request.URL.RequestURI()
+	if meta.NextCursor != "" {
+		next := c.Request.URL.Query()
+		// cursor lebih stabil dibanding offset untuk halaman berikutnya
+		next.Del("page")
+		next.Set("cursor", meta.NextCursor)
+		meta.Links.Next = c.Request.URL.Path + "?" + next.Encode()
+	}
+	c.JSON(http.StatusOK, utils.ResponseSuccessWithMeta("Get all employees", employees, meta))
 }
 
 func (h *EmployeeHandler) GetByNIP(c *gin.Context) {
-	user_id, _ := c.Get("user_id")
+	principal := middleware.GetPrincipal(c)
 	nip := c.Param("nip")
-	employee, err := h.uc.GetByNIP(user_id.(string), nip)
+	// as_of berformat YYYY-MM-DD, data direkonstruksi dari history pada akhir tanggal tersebut
+	if asOf := c.Query("as_of"); asOf != "" {
+		date, err := time.Parse("2006-01-02", asOf)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid as_of, use YYYY-MM-DD"))
+			return
+		}
+		employee, err := h.uc.GetByNIPAsOf(principal, nip, date)
+		if err != nil {
+			c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+			return
+		}
+		c.JSON(http.StatusOK, utils.ResponseSuccess("Get employee by NIP as of "+asOf, employee))
+		return
+	}
+	employee, err := h.uc.GetByNIP(principal, nip)
 	if err != nil {
 		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
 		return
@@ -64,7 +142,7 @@ func (h *EmployeeHandler) GetByNIP(c *gin.Context) {
 	c.JSON(http.StatusOK, utils.ResponseSuccess("Get employee by NIP", employee))
 }
 func (h *EmployeeHandler) GetByUnit(c *gin.Context) {
-	user_id, _ := c.Get("user_id")
+	principal := middleware.GetPrincipal(c)
 	unit_id := c.Param("unit_id")
 	// convert unit id to int
 	unit_id_int, err := strconv.Atoi(unit_id)
@@ -72,7 +150,7 @@ func (h *EmployeeHandler) GetByUnit(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid unit id"))
 		return
 	}
-	employees, err := h.uc.GetByUnit(user_id.(string), unit_id_int)
+	employees, err := h.uc.GetByUnit(principal, unit_id_int)
 	if err != nil {
 		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
 		return
@@ -81,14 +159,14 @@ func (h *EmployeeHandler) GetByUnit(c *gin.Context) {
 }
 
 func (h *EmployeeHandler) Search(c *gin.Context) {
-	user_id, _ := c.Get("user_id")
+	principal := middleware.GetPrincipal(c)
 	// contoh endpoint: /employee/search?query=rudy traspac
 	query := c.Query("query")
 	if query == "" {
 		c.JSON(http.StatusBadRequest, utils.ResponseError("Query is required"))
 		return
 	}
-	employees, err := h.uc.Search(user_id.(string), query)
+	employees, err := h.uc.Search(principal, query)
 	if err != nil {
 		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
 		return
@@ -97,13 +175,13 @@ func (h *EmployeeHandler) Search(c *gin.Context) {
 }
 
 func (h *EmployeeHandler) Add(c *gin.Context) {
-	user_id, _ := c.Get("user_id")
+	principal := middleware.GetPrincipal(c)
 	var payload domain.Employee
 	if err := c.ShouldBindJSON(&payload); err != nil {
 		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid input"))
 		return
 	}
-	employee, err := h.uc.Add(user_id.(string), &payload)
+	employee, err := h.uc.Add(principal, &payload, auditMeta(c))
 	if err != nil {
 		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
 		return
@@ -112,14 +190,14 @@ func (h *EmployeeHandler) Add(c *gin.Context) {
 }
 
 func (h *EmployeeHandler) UploadPP(c *gin.Context) {
-	user_id, _ := c.Get("user_id")
+	principal := middleware.GetPrincipal(c)
 	nip := c.Param("nip")
 	file, err := c.FormFile("image")
 	if err != nil {
 		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid file"))
 		return
 	}
-	url, err := h.uc.UploadPP(user_id.(string), nip, file)
+	url, err := h.uc.UploadPP(principal, nip, file, auditMeta(c))
 	if err != nil {
 		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
 		return
@@ -132,7 +210,7 @@ func (h *EmployeeHandler) UploadPP(c *gin.Context) {
 }
 
 func (h *EmployeeHandler) UpdateEmployee(c *gin.Context) {
-	user_id, _ := c.Get("user_id")
+	principal := middleware.GetPrincipal(c)
 	nip := c.Param("nip")
 	var payload domain.Employee
 	if err := c.ShouldBindJSON(&payload); err != nil {
@@ -140,7 +218,7 @@ func (h *EmployeeHandler) UpdateEmployee(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid input"))
 		return
 	}
-	employee, err := h.uc.UpdateEmployee(user_id.(string), nip, &payload)
+	employee, err := h.uc.UpdateEmployee(principal, nip, &payload, auditMeta(c))
 	if err != nil {
 		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
 		return
@@ -149,17 +227,118 @@ func (h *EmployeeHandler) UpdateEmployee(c *gin.Context) {
 }
 
 func (h *EmployeeHandler) Promote(c *gin.Context) {
-	user_id, _ := c.Get("user_id")
+	principal := middleware.GetPrincipal(c)
 	nip := c.Param("nip")
 	var payload domain.Employee
 	if err := c.ShouldBindJSON(&payload); err != nil {
 		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid input"))
 		return
 	}
-	employee, err := h.uc.Promote(user_id.(string), nip, payload.RoleID)
+	employee, err := h.uc.Promote(principal, nip, payload.RoleID, auditMeta(c))
 	if err != nil {
 		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
 		return
 	}
 	c.JSON(http.StatusOK, utils.ResponseSuccess("Promote employee", employee))
 }
+
+func (h *EmployeeHandler) PromoteOptions(c *gin.Context) {
+	principal := middleware.GetPrincipal(c)
+	nip := c.Param("nip")
+	roles, err := h.uc.PromoteOptions(principal, nip)
+	if err != nil {
+		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Get promote options", roles))
+}
+
+func (h *EmployeeHandler) ChangeStatus(c *gin.Context) {
+	principal := middleware.GetPrincipal(c)
+	nip := c.Param("nip")
+	var payload struct {
+		Status        string    `json:"status" binding:"required"`
+		EffectiveDate time.Time `json:"effective_date"`
+		Reason        string    `json:"reason" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid input"))
+		return
+	}
+	history, err := h.uc.ChangeStatus(principal, nip, payload.Status, payload.EffectiveDate, payload.Reason, auditMeta(c))
+	if err != nil {
+		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Change employee status", history))
+}
+
+func (h *EmployeeHandler) RestoreStatus(c *gin.Context) {
+	principal := middleware.GetPrincipal(c)
+	nip := c.Param("nip")
+	var payload struct {
+		Reason string `json:"reason" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid input"))
+		return
+	}
+	history, err := h.uc.RestoreStatus(principal, nip, payload.Reason, auditMeta(c))
+	if err != nil {
+		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Restore employee status", history))
+}
+
+func (h *EmployeeHandler) GetStatusHistory(c *gin.Context) {
+	principal := middleware.GetPrincipal(c)
+	nip := c.Param("nip")
+	histories, err := h.uc.GetStatusHistory(principal, nip)
+	if err != nil {
+		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Get employee status history", histories))
+}
+
+func (h *EmployeeHandler) GetHistory(c *gin.Context) {
+	principal := middleware.GetPrincipal(c)
+	nip := c.Param("nip")
+	histories, err := h.uc.GetHistory(principal, nip)
+	if err != nil {
+		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Get employee change history", histories))
+}
+
+// Import menerima file CSV/XLSX pada field "file". Default dry_run=true, kirim dry_run=false untuk menyimpan.
+func (h *EmployeeHandler) Import(c *gin.Context) {
+	principal := middleware.GetPrincipal(c)
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid file"))
+		return
+	}
+	dryRun := true
+	if value := c.Query("dry_run"); value != "" {
+		dryRun, err = strconv.ParseBool(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid dry_run"))
+			return
+		}
+	}
+	report, err := h.uc.Import(principal, file, dryRun, auditMeta(c))
+	if err != nil {
+		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+		return
+	}
+	if !dryRun && !report.Committed {
+		response := utils.ResponseError("Import rejected, fix invalid rows and retry")
+		response.Data = report
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Import employee", report))
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"strconv"
+	"time"
 
 	"github.com/achmadnr21/emploman/internal/domain"
 	"github.com/achmadnr21/emploman/internal/middleware"
@@ -26,16 +27,13 @@ func NewEmployeeAssignmentHandler(apiV *gin.RouterGroup, uc *usecase.EmployeeAss
 		empAssign.POST("", empAssignHandler.AssignEmployee)
 		empAssign.POST("deactivate", empAssignHandler.DeactivateEmployee)
 		empAssign.GET("/:employee_id/:unit_id/:position_id", empAssignHandler.GetByAllID)
+		empAssign.GET("/:employee_id/history", empAssignHandler.GetHistory)
 		empAssign.GET("/:employee_id", empAssignHandler.GetByEmployeeID)
 	}
 }
 func (h *EmployeeAssignmentHandler) GetAll(c *gin.Context) {
-	user_id, exist := c.Get("user_id")
-	if !exist {
-		c.JSON(500, utils.ResponseError("User ID not found"))
-		return
-	}
-	empAssignments, err := h.uc.GetAll(user_id.(string))
+	principal := middleware.GetPrincipal(c)
+	empAssignments, err := h.uc.GetAll(principal)
 	if err != nil {
 		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
 		return
@@ -45,11 +43,7 @@ func (h *EmployeeAssignmentHandler) GetAll(c *gin.Context) {
 }
 
 func (h *EmployeeAssignmentHandler) GetByAllID(c *gin.Context) {
-	user_id, exist := c.Get("user_id")
-	if !exist {
-		c.JSON(500, utils.ResponseError("User ID not found"))
-		return
-	}
+	principal := middleware.GetPrincipal(c)
 	employeeID := c.Param("employee_id")
 	unitID := c.Param("unit_id")
 	positionID := c.Param("position_id")
@@ -64,7 +58,7 @@ func (h *EmployeeAssignmentHandler) GetByAllID(c *gin.Context) {
 		return
 	}
 
-	empAssign, err := h.uc.GetAssignmentByAllID(user_id.(string), employeeID, unitIDInt, positionIDInt)
+	empAssign, err := h.uc.GetAssignmentByAllID(principal, employeeID, unitIDInt, positionIDInt)
 	if err != nil {
 		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
 		return
@@ -74,18 +68,14 @@ func (h *EmployeeAssignmentHandler) GetByAllID(c *gin.Context) {
 }
 
 func (h *EmployeeAssignmentHandler) AssignEmployee(c *gin.Context) {
-	user_id, exist := c.Get("user_id")
-	if !exist {
-		c.JSON(500, utils.ResponseError("User ID not found"))
-		return
-	}
+	principal := middleware.GetPrincipal(c)
 	var empAssign *domain.EmployeeAssignment
 	if err := c.ShouldBindJSON(&empAssign); err != nil {
 		c.JSON(400, utils.ResponseError("Invalid request payload"))
 		return
 	}
 
-	err := h.uc.AssignEmployee(user_id.(string), empAssign)
+	err := h.uc.AssignEmployee(principal, empAssign, auditMeta(c))
 	if err != nil {
 		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
 		return
@@ -95,17 +85,17 @@ func (h *EmployeeAssignmentHandler) AssignEmployee(c *gin.Context) {
 }
 
 func (h *EmployeeAssignmentHandler) DeactivateEmployee(c *gin.Context) {
-	user_id, exist := c.Get("user_id")
-	if !exist {
-		c.JSON(500, utils.ResponseError("User ID not found"))
-		return
-	}
+	principal := middleware.GetPrincipal(c)
 	var empAssign *domain.EmployeeAssignment
 	if err := c.ShouldBindJSON(&empAssign); err != nil {
 		c.JSON(400, utils.ResponseError("Invalid request payload"))
 		return
 	}
-	err := h.uc.Deactivate(user_id.(string), empAssign.EmployeeID, empAssign.UnitID, empAssign.PositionID)
+	var endDate time.Time
+	if empAssign.EndDate != nil {
+		endDate = empAssign.EndDate.Time
+	}
+	err := h.uc.Deactivate(principal, empAssign.EmployeeID, empAssign.UnitID, empAssign.PositionID, endDate, auditMeta(c))
 	if err != nil {
 		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
 		return
@@ -114,17 +104,25 @@ func (h *EmployeeAssignmentHandler) DeactivateEmployee(c *gin.Context) {
 }
 
 func (h *EmployeeAssignmentHandler) GetByEmployeeID(c *gin.Context) {
-	user_id, exist := c.Get("user_id")
-	if !exist {
-		c.JSON(500, utils.ResponseError("User ID not found"))
+	principal := middleware.GetPrincipal(c)
+	employeeID := c.Param("employee_id")
+	empAssign, err := h.uc.GetAssignmentByEmployeeID(principal, employeeID)
+	if err != nil {
+		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
 		return
 	}
+
+	c.JSON(200, utils.ResponseSuccess("", empAssign))
+}
+
+func (h *EmployeeAssignmentHandler) GetHistory(c *gin.Context) {
+	principal := middleware.GetPrincipal(c)
 	employeeID := c.Param("employee_id")
-	empAssign, err := h.uc.GetAssignmentByEmployeeID(user_id.(string), employeeID)
+	history, err := h.uc.GetHistory(principal, employeeID)
 	if err != nil {
 		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
 		return
 	}
 
-	c.JSON(200, utils.ResponseSuccess("", empAssign))
+	c.JSON(200, utils.ResponseSuccess("Get employee assignment history", history))
 }
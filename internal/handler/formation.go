@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/achmadnr21/emploman/internal/domain"
+	"github.com/achmadnr21/emploman/internal/middleware"
+	"github.com/achmadnr21/emploman/internal/usecase"
+	"github.com/achmadnr21/emploman/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type FormationHandler struct {
+	uc *usecase.FormationUsecase
+}
+
+func NewFormationHandler(apiV *gin.RouterGroup, uc *usecase.FormationUsecase) {
+	formationHandler := &FormationHandler{
+		uc: uc,
+	}
+
+	unit := apiV.Group("/unit")
+	unit.Use(middleware.JWTAuthMiddleware)
+	{
+		unit.GET("/vacancies", formationHandler.GetVacancyReport)                    // GET /unit/vacancies
+		unit.GET("/:id/vacancies", formationHandler.GetUnitVacancies)                // GET /unit/:id/vacancies
+		unit.GET("/:id/formation", formationHandler.GetFormation)                    // GET /unit/:id/formation
+		unit.PUT("/:id/formation", formationHandler.SetFormation)                    // PUT /unit/:id/formation
+		unit.DELETE("/:id/formation/:position_id", formationHandler.DeleteFormation) // DELETE /unit/:id/formation/:position_id
+	}
+}
+
+func (h *FormationHandler) GetVacancyReport(c *gin.Context) {
+	report, err := h.uc.GetVacancies(0)
+	if err != nil {
+		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Get vacancy report", report))
+}
+
+func (h *FormationHandler) GetUnitVacancies(c *gin.Context) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil || idInt <= 0 {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid ID"))
+		return
+	}
+	report, err := h.uc.GetVacancies(idInt)
+	if err != nil {
+		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Get unit vacancies", report))
+}
+
+func (h *FormationHandler) GetFormation(c *gin.Context) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid ID"))
+		return
+	}
+	formations, err := h.uc.GetByUnitID(idInt)
+	if err != nil {
+		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Get unit formation", formations))
+}
+
+func (h *FormationHandler) SetFormation(c *gin.Context) {
+	principal := middleware.GetPrincipal(c)
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid ID"))
+		return
+	}
+	var payload domain.Formation
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid input"))
+		return
+	}
+	payload.UnitID = idInt
+	formation, err := h.uc.SetFormation(principal, &payload, auditMeta(c))
+	if err != nil {
+		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Set unit formation", formation))
+}
+
+func (h *FormationHandler) DeleteFormation(c *gin.Context) {
+	principal := middleware.GetPrincipal(c)
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid ID"))
+		return
+	}
+	positionID, err := strconv.Atoi(c.Param("position_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid position ID"))
+		return
+	}
+	if err := h.uc.DeleteFormation(principal, idInt, positionID, auditMeta(c)); err != nil {
+		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Delete unit formation", nil))
+}
@@ -33,17 +33,13 @@ func (h *GradeHandler) GetAll(c *gin.Context) {
 	c.JSON(200, utils.ResponseSuccess("Success", grades))
 }
 func (h *GradeHandler) AddGrade(c *gin.Context) {
-	proposerId, exist := c.Get("user_id")
-	if !exist {
-		c.JSON(500, utils.ResponseError("User ID not found"))
-		return
-	}
+	principal := middleware.GetPrincipal(c)
 	var grade domain.Grade
 	if err := c.ShouldBindJSON(&grade); err != nil {
 		c.JSON(400, utils.ResponseError("Invalid request"))
 		return
 	}
-	if err := h.uc.AddGrade(proposerId.(string), &grade); err != nil {
+	if err := h.uc.AddGrade(principal, &grade, auditMeta(c)); err != nil {
 		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
 		return
 	}
@@ -0,0 +1,20 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/achmadnr21/emploman/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// NewJWKSHandler mendaftarkan endpoint public key token akses pada root router (bukan /api/v1)
+// agar layanan lain bisa memverifikasi token RS256/EdDSA tanpa mengetahui secret.
+func NewJWKSHandler(router *gin.RouterGroup) {
+	router.GET("/.well-known/jwks.json", GetJWKS) // GET /.well-known/jwks.json
+}
+
+// GetJWKS mengembalikan JWKS mentah sesuai RFC 7517, bukan dibungkus ResponseSuccess
+func GetJWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, utils.AccessTokenJWKS())
+}
@@ -29,8 +29,8 @@ func NewMeHandler(apiV *gin.RouterGroup, uc *usecase.MeUsecase) {
 }
 
 func (h *MeHandler) GetMe(c *gin.Context) {
-	user_id, _ := c.Get("user_id")
-	employee, err := h.uc.GetMe(user_id.(string))
+	principal := middleware.GetPrincipal(c)
+	employee, err := h.uc.GetMe(principal.UserID)
 	if err != nil {
 		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
 		return
@@ -38,13 +38,13 @@ func (h *MeHandler) GetMe(c *gin.Context) {
 	c.JSON(http.StatusOK, utils.ResponseSuccess("Get employee by NIP", employee))
 }
 func (h *MeHandler) UpdateMe(c *gin.Context) {
-	user_id, _ := c.Get("user_id")
+	principal := middleware.GetPrincipal(c)
 	var payload domain.Employee
 	if err := c.ShouldBindJSON(&payload); err != nil {
 		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid input"))
 		return
 	}
-	employee, err := h.uc.UpdateMe(user_id.(string), &payload)
+	employee, err := h.uc.UpdateMe(principal.UserID, &payload, auditMeta(c))
 	if err != nil {
 		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
 		return
@@ -53,13 +53,13 @@ func (h *MeHandler) UpdateMe(c *gin.Context) {
 }
 
 func (h *MeHandler) UploadPPMe(c *gin.Context) {
-	user_id, _ := c.Get("user_id")
+	principal := middleware.GetPrincipal(c)
 	file, err := c.FormFile("image")
 	if err != nil {
 		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid file"))
 		return
 	}
-	url, err := h.uc.UploadPPMe(user_id.(string), file)
+	url, err := h.uc.UploadPPMe(principal.UserID, file, auditMeta(c))
 	if err != nil {
 		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
 		return
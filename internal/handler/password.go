@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/achmadnr21/emploman/internal/domain"
+	"github.com/achmadnr21/emploman/internal/middleware"
+	"github.com/achmadnr21/emploman/internal/usecase"
+	"github.com/achmadnr21/emploman/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type PasswordHandler struct {
+	uc *usecase.PasswordUsecase
+}
+
+func NewPasswordHandler(apiV *gin.RouterGroup, uc *usecase.PasswordUsecase) {
+	passwordHandler := &PasswordHandler{
+		uc: uc,
+	}
+
+	// ganti password juga menerima token terbatas dari login dengan password sementara
+	me := apiV.Group("/me")
+	me.Use(middleware.PasswordChangeAuthMiddleware)
+	{
+		me.PUT("/password", passwordHandler.ChangePassword) // PUT /me/password
+	}
+
+	auth := apiV.Group("/auth")
+	{
+		auth.POST("/forgot-password", passwordHandler.ForgotPassword) // POST /auth/forgot-password
+		auth.POST("/reset-password", passwordHandler.ResetPassword)   // POST /auth/reset-password
+	}
+
+	employee := apiV.Group("/employee")
+	employee.Use(middleware.JWTAuthMiddleware)
+	{
+		employee.POST("/:nip/reset-password", passwordHandler.ResetByAdmin) // POST /employee/:nip/reset-password
+	}
+}
+
+func (h *PasswordHandler) ChangePassword(c *gin.Context) {
+	principal := middleware.GetPrincipal(c)
+	var payload domain.PasswordChange
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid input"))
+		return
+	}
+	if err := h.uc.ChangePassword(principal.UserID, payload, auditMeta(c)); err != nil {
+		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Password changed, please login again", nil))
+}
+
+func (h *PasswordHandler) ResetByAdmin(c *gin.Context) {
+	principal := middleware.GetPrincipal(c)
+	if err := h.uc.ResetByAdmin(principal, c.Param("nip"), auditMeta(c)); err != nil {
+		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Password reset, temporary password sent to employee", nil))
+}
+
+func (h *PasswordHandler) ForgotPassword(c *gin.Context) {
+	var payload struct {
+		NIP string `json:"nip"`
+	}
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid input"))
+		return
+	}
+	if err := h.uc.ForgotPassword(payload.NIP); err != nil {
+		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.ResponseSuccess("If the account exists, a reset token has been sent", nil))
+}
+
+func (h *PasswordHandler) ResetPassword(c *gin.Context) {
+	var payload domain.PasswordReset
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid input"))
+		return
+	}
+	if err := h.uc.ResetPassword(payload, auditMeta(c)); err != nil {
+		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Password reset, please login with the new password", nil))
+}
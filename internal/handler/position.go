@@ -33,13 +33,13 @@ func NewPositionHandler(apiV *gin.RouterGroup, uc *usecase.PositionUsecase) {
 }
 
 func (h *PositionHandler) AddPosition(c *gin.Context) {
-	user_id := c.MustGet("user_id").(string)
+	principal := middleware.GetPrincipal(c)
 	var position domain.Position
 	if err := c.ShouldBindJSON(&position); err != nil {
 		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError("Invalid input"))
 		return
 	}
-	newposition, err := h.uc.AddPosition(user_id, &position)
+	newposition, err := h.uc.AddPosition(principal, &position, auditMeta(c))
 	if err != nil {
 		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
 		return
@@ -47,7 +47,7 @@ func (h *PositionHandler) AddPosition(c *gin.Context) {
 	c.JSON(http.StatusCreated, newposition)
 }
 func (h *PositionHandler) UpdatePosition(c *gin.Context) {
-	user_id := c.MustGet("user_id").(string)
+	principal := middleware.GetPrincipal(c)
 	id := c.Param("id")
 	// convert id to int
 	idInt, err := strconv.Atoi(id)
@@ -57,7 +57,7 @@ func (h *PositionHandler) UpdatePosition(c *gin.Context) {
 		return
 	}
 	position.ID = idInt
-	updatedPosition, err := h.uc.UpdatePosition(user_id, &position)
+	updatedPosition, err := h.uc.UpdatePosition(principal, &position, auditMeta(c))
 	if err != nil {
 		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
 		return
@@ -65,7 +65,7 @@ func (h *PositionHandler) UpdatePosition(c *gin.Context) {
 	c.JSON(http.StatusOK, updatedPosition)
 }
 func (h *PositionHandler) DeletePosition(c *gin.Context) {
-	user_id := c.MustGet("user_id").(string)
+	principal := middleware.GetPrincipal(c)
 	id := c.Param("id")
 	// convert id to int
 	idInt, err := strconv.Atoi(id)
@@ -73,7 +73,7 @@ func (h *PositionHandler) DeletePosition(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid id"))
 		return
 	}
-	err = h.uc.DeletePosition(user_id, idInt)
+	err = h.uc.DeletePosition(principal, idInt, auditMeta(c))
 	if err != nil {
 		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
 		return
@@ -1,9 +1,15 @@
 package handler
 
 import (
+	"bytes"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/achmadnr21/emploman/internal/document"
+	"github.com/achmadnr21/emploman/internal/domain"
 	"github.com/achmadnr21/emploman/internal/middleware"
 	usecase "github.com/achmadnr21/emploman/internal/usecase"
 	"github.com/achmadnr21/emploman/internal/utils"
@@ -25,15 +31,35 @@ func NewPrintHandler(apiV *gin.RouterGroup, uc *usecase.PrintUsecase) {
 		printEmp := print.Group("/employee")
 		{
 			printEmp.GET("/:nip", printHandler.PrintByNIP)
+			printEmp.GET("/:nip/cv.pdf", printHandler.PrintCV)
+			printEmp.GET("/:nip/card.png", printHandler.PrintCardPNG)
+			printEmp.GET("/:nip/card.pdf", printHandler.PrintCardPDF)
 			printEmp.GET("/unit/:unit_id", printHandler.PrintByUnitID)
 			printEmp.GET("/all", printHandler.PrintAll)
 		}
 	}
+
+	// verifikasi kartu pegawai bersifat publik karena dipindai dari QR code
+	apiV.GET("/verify/:token", printHandler.VerifyCard)
 }
 
 func (h *PrintHandler) PrintAll(c *gin.Context) {
-	user_id, _ := c.Get("user_id")
-	employees, err := h.uc.PrintAll(user_id.(string))
+	principal := middleware.GetPrincipal(c)
+	format, err := exportFormat(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError(err.Error()))
+		return
+	}
+	if format != "" {
+		export, err := h.uc.ExportAll(principal)
+		if err != nil {
+			c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+			return
+		}
+		writeExport(c, format, "employees", export)
+		return
+	}
+	employees, err := h.uc.PrintAll(principal)
 	if err != nil {
 		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
 		return
@@ -41,7 +67,7 @@ func (h *PrintHandler) PrintAll(c *gin.Context) {
 	c.JSON(http.StatusOK, utils.ResponseSuccess("Print all employees", employees))
 }
 func (h *PrintHandler) PrintByUnitID(c *gin.Context) {
-	user_id, _ := c.Get("user_id")
+	principal := middleware.GetPrincipal(c)
 	unit_id := c.Param("unit_id")
 	// convert unit id to int
 	unit_id_int, err := strconv.Atoi(unit_id)
@@ -49,7 +75,21 @@ func (h *PrintHandler) PrintByUnitID(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid unit id"))
 		return
 	}
-	employees, err := h.uc.PrintByUnitID(user_id.(string), unit_id_int)
+	format, err := exportFormat(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError(err.Error()))
+		return
+	}
+	if format != "" {
+		export, err := h.uc.ExportByUnitID(principal, unit_id_int)
+		if err != nil {
+			c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+			return
+		}
+		writeExport(c, format, "employees-unit-"+unit_id, export)
+		return
+	}
+	employees, err := h.uc.PrintByUnitID(principal, unit_id_int)
 	if err != nil {
 		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
 		return
@@ -57,12 +97,169 @@ func (h *PrintHandler) PrintByUnitID(c *gin.Context) {
 	c.JSON(http.StatusOK, utils.ResponseSuccess("Print employee by unit", employees))
 }
 func (h *PrintHandler) PrintByNIP(c *gin.Context) {
-	user_id, _ := c.Get("user_id")
+	principal := middleware.GetPrincipal(c)
 	nip := c.Param("nip")
-	employee, err := h.uc.PrintByNIP(user_id.(string), nip)
+	format, err := exportFormat(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError(err.Error()))
+		return
+	}
+	if format != "" {
+		export, err := h.uc.ExportByNIP(principal, nip)
+		if err != nil {
+			c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+			return
+		}
+		writeExport(c, format, "employee-"+nip, export)
+		return
+	}
+	employee, err := h.uc.PrintByNIP(principal, nip)
 	if err != nil {
 		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
 		return
 	}
 	c.JSON(http.StatusOK, utils.ResponseSuccess("Print employee by NIP", employee))
 }
+
+func (h *PrintHandler) PrintCV(c *gin.Context) {
+	principal := middleware.GetPrincipal(c)
+	nip := c.Param("nip")
+	cv, err := h.uc.PrintCV(principal, nip)
+	if err != nil {
+		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+		return
+	}
+	// dokumen dibuat di buffer agar error masih bisa dikembalikan sebagai JSON
+	var buf bytes.Buffer
+	if err := document.WriteEmployeeCV(&buf, cv, time.Now()); err != nil {
+		fmt.Println("Error generating cv:", err)
+		c.JSON(http.StatusInternalServerError, utils.ResponseError("failed to generate document"))
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="cv-%s.pdf"`, nip))
+	c.Data(http.StatusOK, document.ContentTypes[document.FormatPDF], buf.Bytes())
+}
+
+// PrintCardPNG mengembalikan satu sisi kartu, ?side=front|back (default front)
+func (h *PrintHandler) PrintCardPNG(c *gin.Context) {
+	principal := middleware.GetPrincipal(c)
+	nip := c.Param("nip")
+	side := c.DefaultQuery("side", document.CardSideFront)
+	if side != document.CardSideFront && side != document.CardSideBack {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("side must be front or back"))
+		return
+	}
+	card, err := h.uc.PrintCard(principal, nip)
+	if err != nil {
+		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+		return
+	}
+	var buf bytes.Buffer
+	if err := document.WriteEmployeeCardPNG(&buf, card, side); err != nil {
+		fmt.Println("Error generating card:", err)
+		c.JSON(http.StatusInternalServerError, utils.ResponseError("failed to generate card"))
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="card-%s-%s.png"`, nip, side))
+	c.Data(http.StatusOK, "image/png", buf.Bytes())
+}
+
+// PrintCardPDF mengembalikan kartu dua halaman (depan dan belakang) siap cetak
+func (h *PrintHandler) PrintCardPDF(c *gin.Context) {
+	principal := middleware.GetPrincipal(c)
+	nip := c.Param("nip")
+	card, err := h.uc.PrintCard(principal, nip)
+	if err != nil {
+		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+		return
+	}
+	var buf bytes.Buffer
+	if err := document.WriteEmployeeCardPDF(&buf, card); err != nil {
+		fmt.Println("Error generating card:", err)
+		c.JSON(http.StatusInternalServerError, utils.ResponseError("failed to generate card"))
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="card-%s.pdf"`, nip))
+	c.Data(http.StatusOK, document.ContentTypes[document.FormatPDF], buf.Bytes())
+}
+
+func (h *PrintHandler) VerifyCard(c *gin.Context) {
+	verification, err := h.uc.VerifyCard(c.Param("token"))
+	if err != nil {
+		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+		return
+	}
+	message := "Card is genuine and employee is active"
+	if !verification.IsActive {
+		message = "Card is genuine but employee is no longer active"
+	}
+	c.JSON(http.StatusOK, utils.ResponseSuccess(message, verification))
+}
+
+// ==================================================================== UTILITIES ====================================================================
+
+// exportFormat membaca format dari query "format" atau header Accept. String kosong berarti JSON.
+// Format pada query yang tidak dikenal ditolak agar klien tidak diam-diam menerima JSON.
+func exportFormat(c *gin.Context) (string, error) {
+	if format := strings.ToLower(c.Query("format")); format != "" {
+		if format == "json" {
+			return "", nil
+		}
+		if _, ok := document.ContentTypes[format]; ok {
+			return format, nil
+		}
+		return "", &utils.BadRequestError{Message: "unsupported format, use one of json, " + strings.Join(document.Formats, ", ")}
+	}
+	accept := c.GetHeader("Accept")
+	for _, format := range document.Formats {
+		if strings.Contains(accept, strings.Split(document.ContentTypes[format], ";")[0]) {
+			return format, nil
+		}
+	}
+	return "", nil
+}
+
+// exportLang memilih bahasa header kolom dari query "lang" atau Accept-Language, default bahasa Indonesia
+func exportLang(c *gin.Context) string {
+	lang := c.Query("lang")
+	if lang == "" {
+		lang = c.GetHeader("Accept-Language")
+	}
+	if strings.HasPrefix(strings.ToLower(strings.TrimSpace(lang)), "en") {
+		return "en"
+	}
+	return "id"
+}
+
+func writeExport(c *gin.Context, format string, filename string, export *usecase.EmployeeExport) {
+	writer, err := document.NewEmployeeTableWriter(format, c.Writer, document.TableOptions{
+		Lang:      exportLang(c),
+		Title:     export.Title,
+		PrintedAt: time.Now(),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ResponseError("failed to create document"))
+		return
+	}
+	c.Header("Content-Type", document.ContentTypes[format])
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, filename, format))
+	c.Status(http.StatusOK)
+
+	err = export.Stream(func(employee *domain.PrintEmployee) error {
+		return writer.WriteRow(employee)
+	})
+	if err == nil {
+		err = writer.Close()
+	}
+	if err != nil {
+		fmt.Println("Error exporting employees:", err)
+		// response belum terkirim sehingga masih bisa mengembalikan error JSON
+		if !c.Writer.Written() {
+			c.Writer.Header().Del("Content-Type")
+			c.Writer.Header().Del("Content-Disposition")
+			c.JSON(http.StatusInternalServerError, utils.ResponseError("failed to export employees"))
+			return
+		}
+		c.Abort()
+	}
+}
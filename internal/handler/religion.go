@@ -33,17 +33,13 @@ func (h *ReligionHandler) GetAll(c *gin.Context) {
 	c.JSON(200, utils.ResponseSuccess("Success", religions))
 }
 func (h *ReligionHandler) AddReligion(c *gin.Context) {
-	proposerId, exist := c.Get("user_id")
-	if !exist {
-		c.JSON(500, utils.ResponseError("User ID not found"))
-		return
-	}
+	principal := middleware.GetPrincipal(c)
 	var religion domain.Religion
 	if err := c.ShouldBindJSON(&religion); err != nil {
 		c.JSON(400, utils.ResponseError("Invalid request"))
 		return
 	}
-	if err := h.uc.AddReligion(proposerId.(string), &religion); err != nil {
+	if err := h.uc.AddReligion(principal, &religion, auditMeta(c)); err != nil {
 		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
 		return
 	}
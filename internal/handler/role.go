@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/achmadnr21/emploman/internal/domain"
+	"github.com/achmadnr21/emploman/internal/middleware"
+	"github.com/achmadnr21/emploman/internal/usecase"
+	"github.com/achmadnr21/emploman/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type RoleHandler struct {
+	uc *usecase.RoleUsecase
+}
+
+func NewRoleHandler(apiV *gin.RouterGroup, uc *usecase.RoleUsecase) {
+	roleHandler := &RoleHandler{
+		uc: uc,
+	}
+
+	role := apiV.Group("/role")
+	role.Use(middleware.JWTAuthMiddleware)
+	{
+		role.GET("", roleHandler.GetAllRole) // GET /role
+		role.POST("", roleHandler.AddRole)   // POST /role
+		role.GET("/:id", roleHandler.GetRoleByID)
+		role.GET("/:id/permissions", roleHandler.GetEffectivePermissions) // GET /role/:id/permissions
+		role.PUT("/:id", roleHandler.UpdateRole)
+		role.DELETE("/:id", roleHandler.DeleteRole)
+
+		// Promotion graph
+		role.GET("/promotion", roleHandler.GetAllPromotion)
+		role.POST("/promotion", roleHandler.AddPromotion)
+		role.DELETE("/promotion", roleHandler.DeletePromotion)
+
+		// Katalog permission yang dapat diberikan ke role
+		role.GET("/permissions", roleHandler.GetAllPermission) // GET /role/permissions
+	}
+}
+
+func (h *RoleHandler) GetAllRole(c *gin.Context) {
+	roles, err := h.uc.GetAllRole()
+	if err != nil {
+		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Get all role", roles))
+}
+
+func (h *RoleHandler) GetRoleByID(c *gin.Context) {
+	role, err := h.uc.GetRoleByID(c.Param("id"))
+	if err != nil {
+		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Get role by ID", role))
+}
+
+func (h *RoleHandler) GetEffectivePermissions(c *gin.Context) {
+	permissions, err := h.uc.GetEffectivePermissions(c.Param("id"))
+	if err != nil {
+		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Get role effective permissions", permissions))
+}
+
+func (h *RoleHandler) GetAllPermission(c *gin.Context) {
+	permissions, err := h.uc.GetAllPermission()
+	if err != nil {
+		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Get all permission", permissions))
+}
+
+func (h *RoleHandler) AddRole(c *gin.Context) {
+	principal := middleware.GetPrincipal(c)
+	var payload domain.Role
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid input"))
+		return
+	}
+	role, err := h.uc.AddRole(principal, &payload, auditMeta(c))
+	if err != nil {
+		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Add role", role))
+}
+
+func (h *RoleHandler) UpdateRole(c *gin.Context) {
+	principal := middleware.GetPrincipal(c)
+	var payload domain.RoleUpdate
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid input"))
+		return
+	}
+	payload.ID = c.Param("id")
+	role, err := h.uc.UpdateRole(principal, &payload, auditMeta(c))
+	if err != nil {
+		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Update role", role))
+}
+
+func (h *RoleHandler) DeleteRole(c *gin.Context) {
+	principal := middleware.GetPrincipal(c)
+	if err := h.uc.DeleteRole(principal, c.Param("id"), auditMeta(c)); err != nil {
+		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Delete role", nil))
+}
+
+func (h *RoleHandler) GetAllPromotion(c *gin.Context) {
+	principal := middleware.GetPrincipal(c)
+	promotions, err := h.uc.GetAllPromotion(principal)
+	if err != nil {
+		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Get all role promotion", promotions))
+}
+
+func (h *RoleHandler) AddPromotion(c *gin.Context) {
+	principal := middleware.GetPrincipal(c)
+	var payload domain.RolePromotion
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid input"))
+		return
+	}
+	promotion, err := h.uc.AddPromotion(principal, &payload, auditMeta(c))
+	if err != nil {
+		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Add role promotion", promotion))
+}
+
+func (h *RoleHandler) DeletePromotion(c *gin.Context) {
+	principal := middleware.GetPrincipal(c)
+	var payload domain.RolePromotion
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid input"))
+		return
+	}
+	if err := h.uc.DeletePromotion(principal, &payload, auditMeta(c)); err != nil {
+		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Delete role promotion", nil))
+}
@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/achmadnr21/emploman/internal/domain"
+	"github.com/achmadnr21/emploman/internal/middleware"
+	"github.com/achmadnr21/emploman/internal/usecase"
+	"github.com/achmadnr21/emploman/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type TransferHandler struct {
+	uc *usecase.TransferUsecase
+}
+
+// transferDecisionPayload adalah body untuk setiap perpindahan status transfer
+type transferDecisionPayload struct {
+	Comment           string `json:"comment"`
+	DecreeNumber      string `json:"decree_number"`      // hanya untuk execute
+	OverrideFormation bool   `json:"override_formation"` // hanya untuk execute
+}
+
+func NewTransferHandler(apiV *gin.RouterGroup, uc *usecase.TransferUsecase) {
+	transferHandler := &TransferHandler{
+		uc: uc,
+	}
+
+	transfer := apiV.Group("/transfer")
+	transfer.Use(middleware.JWTAuthMiddleware)
+	{
+		transfer.GET("", transferHandler.GetAll)               // GET /transfer?status=
+		transfer.POST("", transferHandler.Create)              // POST /transfer
+		transfer.GET("/:id", transferHandler.GetByID)          // GET /transfer/:id
+		transfer.POST("/:id/propose", transferHandler.Propose) // POST /transfer/:id/propose
+		transfer.POST("/:id/accept", transferHandler.Accept)   // POST /transfer/:id/accept
+		transfer.POST("/:id/reject", transferHandler.Reject)   // POST /transfer/:id/reject
+		transfer.POST("/:id/execute", transferHandler.Execute) // POST /transfer/:id/execute
+	}
+}
+
+func (h *TransferHandler) GetAll(c *gin.Context) {
+	principal := middleware.GetPrincipal(c)
+	transfers, err := h.uc.GetAll(principal, c.Query("status"))
+	if err != nil {
+		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Get transfer requests", transfers))
+}
+
+func (h *TransferHandler) Create(c *gin.Context) {
+	principal := middleware.GetPrincipal(c)
+	var payload struct {
+		domain.TransferRequest
+		Comment string `json:"comment"`
+	}
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid input"))
+		return
+	}
+	transfer, err := h.uc.Create(principal, &payload.TransferRequest, payload.Comment, auditMeta(c))
+	if err != nil {
+		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Create transfer request", transfer))
+}
+
+func (h *TransferHandler) GetByID(c *gin.Context) {
+	principal := middleware.GetPrincipal(c)
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid ID"))
+		return
+	}
+	transfer, err := h.uc.GetByID(principal, idInt)
+	if err != nil {
+		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Get transfer request", transfer))
+}
+
+func (h *TransferHandler) Propose(c *gin.Context) {
+	h.decide(c, "Propose transfer request", func(principal *domain.Principal, id int, payload transferDecisionPayload, meta domain.AuditMeta) (*domain.TransferRequest, error) {
+		return h.uc.Propose(principal, id, payload.Comment, meta)
+	})
+}
+
+func (h *TransferHandler) Accept(c *gin.Context) {
+	h.decide(c, "Accept transfer request", func(principal *domain.Principal, id int, payload transferDecisionPayload, meta domain.AuditMeta) (*domain.TransferRequest, error) {
+		return h.uc.Accept(principal, id, payload.Comment, meta)
+	})
+}
+
+func (h *TransferHandler) Reject(c *gin.Context) {
+	h.decide(c, "Reject transfer request", func(principal *domain.Principal, id int, payload transferDecisionPayload, meta domain.AuditMeta) (*domain.TransferRequest, error) {
+		return h.uc.Reject(principal, id, payload.Comment, meta)
+	})
+}
+
+func (h *TransferHandler) Execute(c *gin.Context) {
+	h.decide(c, "Execute transfer request", func(principal *domain.Principal, id int, payload transferDecisionPayload, meta domain.AuditMeta) (*domain.TransferRequest, error) {
+		return h.uc.Execute(principal, id, payload.DecreeNumber, payload.OverrideFormation, payload.Comment, meta)
+	})
+}
+
+// decide membaca id dan body keputusan yang sama untuk setiap endpoint perpindahan status
+func (h *TransferHandler) decide(c *gin.Context, message string, fn func(*domain.Principal, int, transferDecisionPayload, domain.AuditMeta) (*domain.TransferRequest, error)) {
+	principal := middleware.GetPrincipal(c)
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid ID"))
+		return
+	}
+	var payload transferDecisionPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid input"))
+		return
+	}
+	transfer, err := fn(principal, idInt, payload, auditMeta(c))
+	if err != nil {
+		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.ResponseSuccess(message, transfer))
+}
@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/achmadnr21/emploman/internal/domain"
+	"github.com/achmadnr21/emploman/internal/middleware"
+	"github.com/achmadnr21/emploman/internal/usecase"
+	"github.com/achmadnr21/emploman/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type TwoFactorHandler struct {
+	uc *usecase.TwoFactorUsecase
+}
+
+func NewTwoFactorHandler(apiV *gin.RouterGroup, uc *usecase.TwoFactorUsecase) {
+	twoFactorHandler := &TwoFactorHandler{
+		uc: uc,
+	}
+
+	// enrolment juga menerima token terbatas dari login role yang mewajibkan 2FA
+	me := apiV.Group("/me/2fa")
+	me.Use(middleware.TwoFactorSetupAuthMiddleware)
+	{
+		me.GET("", twoFactorHandler.GetStatus)                               // GET /me/2fa
+		me.POST("", twoFactorHandler.Enroll)                                 // POST /me/2fa
+		me.POST("/confirm", twoFactorHandler.Confirm)                        // POST /me/2fa/confirm
+		me.DELETE("", twoFactorHandler.Disable)                              // DELETE /me/2fa
+		me.POST("/recovery-codes", twoFactorHandler.RegenerateRecoveryCodes) // POST /me/2fa/recovery-codes
+	}
+
+	employee := apiV.Group("/employee")
+	employee.Use(middleware.JWTAuthMiddleware)
+	{
+		employee.DELETE("/:nip/2fa", twoFactorHandler.ResetByAdmin) // DELETE /employee/:nip/2fa
+	}
+}
+
+func (h *TwoFactorHandler) GetStatus(c *gin.Context) {
+	principal := middleware.GetPrincipal(c)
+	status, err := h.uc.GetStatus(principal.UserID)
+	if err != nil {
+		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Get two-factor status", status))
+}
+
+func (h *TwoFactorHandler) Enroll(c *gin.Context) {
+	principal := middleware.GetPrincipal(c)
+	enrollment, err := h.uc.Enroll(principal.UserID)
+	if err != nil {
+		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Scan the QR code and confirm with a code", enrollment))
+}
+
+func (h *TwoFactorHandler) Confirm(c *gin.Context) {
+	principal := middleware.GetPrincipal(c)
+	var payload domain.TwoFactorCode
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid input"))
+		return
+	}
+	codes, err := h.uc.Confirm(principal.UserID, payload.Code, auditMeta(c))
+	if err != nil {
+		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+		return
+	}
+	var response struct {
+		RecoveryCodes []string `json:"recovery_codes"`
+	}
+	response.RecoveryCodes = codes
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Two-factor authentication enabled, please login again", response))
+}
+
+func (h *TwoFactorHandler) Disable(c *gin.Context) {
+	principal := middleware.GetPrincipal(c)
+	var payload domain.TwoFactorCode
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid input"))
+		return
+	}
+	if err := h.uc.Disable(principal.UserID, payload, auditMeta(c)); err != nil {
+		if throttled, ok := err.(*utils.TooManyRequestError); ok && throttled.RetryAfter > 0 {
+			c.Header("Retry-After", strconv.Itoa(throttled.RetryAfter))
+		}
+		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Two-factor authentication disabled", nil))
+}
+
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	principal := middleware.GetPrincipal(c)
+	var payload domain.TwoFactorCode
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid input"))
+		return
+	}
+	codes, err := h.uc.RegenerateRecoveryCodes(principal.UserID, payload.Code, auditMeta(c))
+	if err != nil {
+		if throttled, ok := err.(*utils.TooManyRequestError); ok && throttled.RetryAfter > 0 {
+			c.Header("Retry-After", strconv.Itoa(throttled.RetryAfter))
+		}
+		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+		return
+	}
+	var response struct {
+		RecoveryCodes []string `json:"recovery_codes"`
+	}
+	response.RecoveryCodes = codes
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Recovery codes regenerated", response))
+}
+
+func (h *TwoFactorHandler) ResetByAdmin(c *gin.Context) {
+	principal := middleware.GetPrincipal(c)
+	if err := h.uc.ResetByAdmin(principal, c.Param("nip"), auditMeta(c)); err != nil {
+		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Two-factor authentication reset", nil))
+}
@@ -27,6 +27,7 @@ func NewUnitHandler(apiV *gin.RouterGroup, uc *usecase.UnitUsecase) {
 		unit.GET("", unitHandler.GetAllUnit) // GET /units
 		unit.POST("", unitHandler.AddUnit)   // POST /units
 		unit.GET("/:id", unitHandler.GetUnitByID)
+		unit.GET("/:id/tree", unitHandler.GetUnitTree) // GET /units/:id/tree
 		unit.PUT("/:id", unitHandler.UpdateUnit)
 		unit.DELETE("/:id", unitHandler.DeleteUnit)
 		unit.GET("/search", unitHandler.SearchUnit) // GET /units/search
@@ -41,14 +42,14 @@ func (h *UnitHandler) GetAllUnit(c *gin.Context) {
 	c.JSON(http.StatusOK, utils.ResponseSuccess("Get all unit", units))
 }
 func (h *UnitHandler) AddUnit(c *gin.Context) {
-	userId, _ := c.Get("user_id")
+	principal := middleware.GetPrincipal(c)
 	var payload domain.Unit
 	if err := c.ShouldBindJSON(&payload); err != nil {
 		fmt.Println("error bind json", err)
 		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid input"))
 		return
 	}
-	unit, err := h.uc.AddUnit(userId.(string), &payload)
+	unit, err := h.uc.AddUnit(principal, &payload, auditMeta(c))
 	if err != nil {
 		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
 		return
@@ -57,7 +58,7 @@ func (h *UnitHandler) AddUnit(c *gin.Context) {
 }
 
 func (h *UnitHandler) UpdateUnit(c *gin.Context) {
-	userId, _ := c.Get("user_id")
+	principal := middleware.GetPrincipal(c)
 	id := c.Param("id")
 	idInt, err := strconv.Atoi(id)
 	if err != nil {
@@ -70,7 +71,7 @@ func (h *UnitHandler) UpdateUnit(c *gin.Context) {
 		return
 	}
 	payload.ID = idInt
-	unit, err := h.uc.UpdateUnit(userId.(string), &payload)
+	unit, err := h.uc.UpdateUnit(principal, &payload, auditMeta(c))
 	if err != nil {
 		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
 		return
@@ -78,14 +79,14 @@ func (h *UnitHandler) UpdateUnit(c *gin.Context) {
 	c.JSON(http.StatusOK, utils.ResponseSuccess("Update unit", unit))
 }
 func (h *UnitHandler) DeleteUnit(c *gin.Context) {
-	userId, _ := c.Get("user_id")
+	principal := middleware.GetPrincipal(c)
 	id := c.Param("id")
 	idInt, err := strconv.Atoi(id)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid ID"))
 		return
 	}
-	err = h.uc.DeleteUnit(userId.(string), idInt)
+	err = h.uc.DeleteUnit(principal, idInt, auditMeta(c))
 	if err != nil {
 		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
 		return
@@ -123,3 +124,17 @@ func (h *UnitHandler) GetUnitByID(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, utils.ResponseSuccess("Get unit by ID", unit))
 }
+
+func (h *UnitHandler) GetUnitTree(c *gin.Context) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid ID"))
+		return
+	}
+	tree, err := h.uc.GetUnitTree(idInt)
+	if err != nil {
+		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Get unit tree", tree))
+}
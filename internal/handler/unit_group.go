@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/achmadnr21/emploman/internal/domain"
+	"github.com/achmadnr21/emploman/internal/middleware"
+	"github.com/achmadnr21/emploman/internal/usecase"
+	"github.com/achmadnr21/emploman/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type UnitGroupHandler struct {
+	uc *usecase.UnitGroupUsecase
+}
+
+func NewUnitGroupHandler(apiV *gin.RouterGroup, uc *usecase.UnitGroupUsecase) {
+	unitGroupHandler := &UnitGroupHandler{
+		uc: uc,
+	}
+
+	unitGroup := apiV.Group("/unit-group")
+	unitGroup.Use(middleware.JWTAuthMiddleware)
+	{
+		unitGroup.GET("", unitGroupHandler.GetAll)                 // GET /unit-group
+		unitGroup.GET("/:id", unitGroupHandler.GetByID)            // GET /unit-group/:id
+		unitGroup.POST("", unitGroupHandler.AddUnitGroup)          // POST /unit-group
+		unitGroup.PUT("/:id", unitGroupHandler.UpdateUnitGroup)    // PUT /unit-group/:id
+		unitGroup.DELETE("/:id", unitGroupHandler.DeleteUnitGroup) // DELETE /unit-group/:id
+	}
+}
+
+func (h *UnitGroupHandler) GetAll(c *gin.Context) {
+	principal := middleware.GetPrincipal(c)
+	groups, err := h.uc.GetAll(principal)
+	if err != nil {
+		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Get unit groups", groups))
+}
+
+func (h *UnitGroupHandler) GetByID(c *gin.Context) {
+	principal := middleware.GetPrincipal(c)
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid ID"))
+		return
+	}
+	group, err := h.uc.GetByID(principal, idInt)
+	if err != nil {
+		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Get unit group", group))
+}
+
+func (h *UnitGroupHandler) AddUnitGroup(c *gin.Context) {
+	principal := middleware.GetPrincipal(c)
+	var payload domain.UnitGroup
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid input"))
+		return
+	}
+	group, err := h.uc.AddUnitGroup(principal, &payload, auditMeta(c))
+	if err != nil {
+		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Add unit group", group))
+}
+
+func (h *UnitGroupHandler) UpdateUnitGroup(c *gin.Context) {
+	principal := middleware.GetPrincipal(c)
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid ID"))
+		return
+	}
+	var payload domain.UnitGroup
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid input"))
+		return
+	}
+	payload.ID = idInt
+	group, err := h.uc.UpdateUnitGroup(principal, &payload, auditMeta(c))
+	if err != nil {
+		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Update unit group", group))
+}
+
+func (h *UnitGroupHandler) DeleteUnitGroup(c *gin.Context) {
+	principal := middleware.GetPrincipal(c)
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid ID"))
+		return
+	}
+	if err := h.uc.DeleteUnitGroup(principal, idInt, auditMeta(c)); err != nil {
+		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Delete unit group", nil))
+}
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/achmadnr21/emploman/internal/domain"
+	"github.com/achmadnr21/emploman/internal/middleware"
+	"github.com/achmadnr21/emploman/internal/usecase"
+	"github.com/achmadnr21/emploman/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type UnitScopeHandler struct {
+	uc *usecase.UnitScopeUsecase
+}
+
+func NewUnitScopeHandler(apiV *gin.RouterGroup, uc *usecase.UnitScopeUsecase) {
+	unitScopeHandler := &UnitScopeHandler{
+		uc: uc,
+	}
+
+	unitScope := apiV.Group("/unit-scope")
+	unitScope.Use(middleware.JWTAuthMiddleware)
+	{
+		unitScope.GET("/:employee_id", unitScopeHandler.GetByEmployeeID) // GET /unit-scope/:employee_id
+		unitScope.POST("", unitScopeHandler.Grant)                       // POST /unit-scope
+		unitScope.DELETE("/:id", unitScopeHandler.Revoke)                // DELETE /unit-scope/:id
+	}
+}
+
+func (h *UnitScopeHandler) GetByEmployeeID(c *gin.Context) {
+	principal := middleware.GetPrincipal(c)
+	grants, err := h.uc.GetByEmployeeID(principal, c.Param("employee_id"))
+	if err != nil {
+		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Get unit scope", grants))
+}
+
+func (h *UnitScopeHandler) Grant(c *gin.Context) {
+	principal := middleware.GetPrincipal(c)
+	var payload domain.UnitScopeGrant
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid input"))
+		return
+	}
+	grant, err := h.uc.Grant(principal, &payload, auditMeta(c))
+	if err != nil {
+		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Grant unit scope", grant))
+}
+
+func (h *UnitScopeHandler) Revoke(c *gin.Context) {
+	principal := middleware.GetPrincipal(c)
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid ID"))
+		return
+	}
+	if err := h.uc.Revoke(principal, idInt, auditMeta(c)); err != nil {
+		c.JSON(utils.GetHTTPErrorCode(err), utils.ResponseError(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Revoke unit scope", nil))
+}
@@ -4,11 +4,30 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/achmadnr21/emploman/internal/domain"
 	"github.com/achmadnr21/emploman/internal/utils"
 	"github.com/gin-gonic/gin"
 )
 
+// PrincipalKey adalah key principal pada context Gin
+const PrincipalKey = "principal"
+
 func JWTAuthMiddleware(c *gin.Context) {
+	authenticate(c, "")
+}
+
+// PasswordChangeAuthMiddleware juga menerima token terbatas milik user yang wajib mengganti password
+func PasswordChangeAuthMiddleware(c *gin.Context) {
+	authenticate(c, utils.TokenPurposePasswordChange)
+}
+
+// TwoFactorSetupAuthMiddleware juga menerima token terbatas milik user yang wajib mengaktifkan 2FA
+func TwoFactorSetupAuthMiddleware(c *gin.Context) {
+	authenticate(c, utils.TokenPurposeTwoFactorSetup)
+}
+
+// authenticate menerima token akses biasa dan token terbatas dengan purpose allowedPurpose
+func authenticate(c *gin.Context, allowedPurpose string) {
 	bearerToken := strings.Split(c.Request.Header.Get("Authorization"), "Bearer ")
 	if len(bearerToken) != 2 {
 		c.JSON(http.StatusUnauthorized, utils.ResponseError("Token tidak valid!"))
@@ -31,8 +50,35 @@ func JWTAuthMiddleware(c *gin.Context) {
 		return
 	}
 
-	// Menyimpan user_id di context Gin
-	c.Set("user_id", claims.UserId)
-	c.Set("issuer", claims.Issuer)
+	// token terbatas hanya boleh dipakai pada endpoint sesuai purpose
+	if claims.Purpose != "" && claims.Purpose != allowedPurpose {
+		c.JSON(http.StatusForbidden, utils.ResponseError(restrictedTokenMessage(claims.Purpose)))
+		c.Abort()
+		return
+	}
+
+	// Menyimpan principal di context Gin
+	c.Set(PrincipalKey, &domain.Principal{
+		UserID:      claims.UserId,
+		RoleID:      claims.RoleID,
+		Permissions: claims.Permissions,
+		Purpose:     claims.Purpose,
+	})
 	c.Next()
 }
+
+// GetPrincipal mengambil principal yang disimpan middleware autentikasi, panic jika route tidak memakai middleware
+func GetPrincipal(c *gin.Context) *domain.Principal {
+	return c.MustGet(PrincipalKey).(*domain.Principal)
+}
+
+func restrictedTokenMessage(purpose string) string {
+	switch purpose {
+	case utils.TokenPurposePasswordChange:
+		return "Password harus diganti terlebih dahulu!"
+	case utils.TokenPurposeTwoFactorSetup:
+		return "Two-factor authentication harus diaktifkan terlebih dahulu!"
+	default:
+		return "Token tidak valid!"
+	}
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/achmadnr21/emploman/internal/domain"
+)
+
+type AuditRepository struct {
+	db *sql.DB
+}
+
+func NewAuditRepository(db *sql.DB) *AuditRepository {
+	return &AuditRepository{
+		db: db,
+	}
+}
+
+func (r *AuditRepository) Save(log *domain.AuditLog) error {
+	query := `INSERT INTO achmadnr.audit_log (actor_id, action, entity_type, entity_id, before, after, ip_address, user_agent)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	RETURNING id, created_at`
+	return r.db.QueryRow(query, log.ActorID, log.Action, log.EntityType, log.EntityID,
+		nullableJSON(log.Before), nullableJSON(log.After), log.IPAddress, log.UserAgent).Scan(&log.ID, &log.CreatedAt)
+}
+
+// FindAll mengembalikan audit log terbaru lebih dulu beserta total baris yang cocok dengan filter
+func (r *AuditRepository) FindAll(filter domain.AuditFilter) ([]domain.AuditLog, int, error) {
+	var conditions []string
+	var args []interface{}
+	param := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	if filter.EntityType != "" {
+		conditions = append(conditions, "a.entity_type = "+param(filter.EntityType))
+	}
+	if filter.EntityID != "" {
+		conditions = append(conditions, "a.entity_id = "+param(filter.EntityID))
+	}
+	if filter.ActorID != "" {
+		conditions = append(conditions, "a.actor_id = "+param(filter.ActorID))
+	}
+	if filter.Action != "" {
+		conditions = append(conditions, "a.action = "+param(filter.Action))
+	}
+	if filter.From != nil {
+		conditions = append(conditions, "a.created_at >= "+param(*filter.From))
+	}
+	if filter.To != nil {
+		conditions = append(conditions, "a.created_at < "+param(*filter.To))
+	}
+
+	countQuery := `SELECT COUNT(*) FROM achmadnr.audit_log a` + whereClause(conditions)
+	var total int
+	if err := r.db.QueryRow(countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT a.id, a.actor_id, coalesce(e.full_name, ''), a.action, a.entity_type, a.entity_id,
+	a.before, a.after, coalesce(a.ip_address, ''), coalesce(a.user_agent, ''), a.created_at
+	FROM achmadnr.audit_log a
+	LEFT JOIN achmadnr.employees e ON a.actor_id = e.id` + whereClause(conditions) +
+		` ORDER BY a.created_at DESC, a.id DESC LIMIT ` + param(filter.PageSize) + ` OFFSET ` + param((filter.Page-1)*filter.PageSize)
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	var logs []domain.AuditLog
+	for rows.Next() {
+		var log domain.AuditLog
+		var before, after []byte
+		if err := rows.Scan(&log.ID, &log.ActorID, &log.ActorName, &log.Action, &log.EntityType, &log.EntityID,
+			&before, &after, &log.IPAddress, &log.UserAgent, &log.CreatedAt); err != nil {
+			return nil, 0, err
+		}
+		log.Before = before
+		log.After = after
+		logs = append(logs, log)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	return logs, total, nil
+}
+
+// nullableJSON menyimpan NULL untuk json kosong agar kolom jsonb tidak berisi string kosong
+func nullableJSON(value []byte) interface{} {
+	if len(value) == 0 {
+		return nil
+	}
+	return string(value)
+}
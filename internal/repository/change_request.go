@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/achmadnr21/emploman/internal/domain"
+)
+
+type ChangeRequestRepository struct {
+	db *sql.DB
+}
+
+func NewChangeRequestRepository(db *sql.DB) *ChangeRequestRepository {
+	return &ChangeRequestRepository{
+		db: db,
+	}
+}
+
+func (r *ChangeRequestRepository) Save(request *domain.ProfileChangeRequest) (*domain.ProfileChangeRequest, error) {
+	changes, err := json.Marshal(request.Changes)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			tx.Commit()
+		}
+	}()
+
+	err = tx.QueryRow(`INSERT INTO achmadnr.profile_change_requests (employee_id, changes, note, status)
+		VALUES ($1, $2, $3, $4) RETURNING id, created_at`,
+		request.EmployeeID, string(changes), request.Note, request.Status).Scan(&request.ID, &request.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	for i := range request.Documents {
+		document := &request.Documents[i]
+		document.RequestID = request.ID
+		err = tx.QueryRow(`INSERT INTO achmadnr.profile_change_documents (request_id, file_name, url, content_type)
+			VALUES ($1, $2, $3, $4) RETURNING id, created_at`,
+			document.RequestID, document.FileName, document.URL, document.ContentType).Scan(&document.ID, &document.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return request, nil
+}
+
+func (r *ChangeRequestRepository) FindByID(id int) (*domain.ProfileChangeRequest, error) {
+	request, err := scanChangeRequest(r.db.QueryRow(changeRequestQuery+` WHERE c.id = $1`, id))
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.db.Query(`SELECT id, request_id, file_name, url, content_type, created_at
+	FROM achmadnr.profile_change_documents WHERE request_id = $1 ORDER BY id`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	request.Documents = []domain.ChangeRequestDocument{}
+	for rows.Next() {
+		var document domain.ChangeRequestDocument
+		if err := rows.Scan(&document.ID, &document.RequestID, &document.FileName, &document.URL,
+			&document.ContentType, &document.CreatedAt); err != nil {
+			return nil, err
+		}
+		request.Documents = append(request.Documents, document)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+func (r *ChangeRequestRepository) FindAll(filter domain.ChangeRequestFilter) ([]domain.ProfileChangeRequest, error) {
+	var conditions []string
+	var args []interface{}
+	param := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	if filter.Status != "" {
+		conditions = append(conditions, "c.status = "+param(filter.Status))
+	}
+	if filter.EmployeeID != "" {
+		conditions = append(conditions, "c.employee_id = "+param(filter.EmployeeID))
+	}
+	query := changeRequestQuery + whereClause(conditions) + ` ORDER BY c.created_at, c.id`
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	requests := []domain.ProfileChangeRequest{}
+	for rows.Next() {
+		request, err := scanChangeRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, *request)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+func (r *ChangeRequestRepository) Review(request *domain.ProfileChangeRequest) error {
+	// hanya change request yang masih pending yang dapat direview
+	err := r.db.QueryRow(`UPDATE achmadnr.profile_change_requests
+		SET status = $2, reviewed_by = $3, review_comment = $4, reviewed_at = NOW()
+		WHERE id = $1 AND status = 'pending'
+		RETURNING reviewed_at`,
+		request.ID, request.Status, request.ReviewedBy, request.ReviewComment).Scan(&request.ReviewedAt)
+	if err == sql.ErrNoRows {
+		return domain.ErrChangeRequestReviewed
+	}
+	return err
+}
+
+func (r *ChangeRequestRepository) Approve(request *domain.ProfileChangeRequest, employee *domain.Employee, changedBy string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			tx.Commit()
+		}
+	}()
+
+	// klaim change request lebih dulu agar persetujuan bersamaan tidak menerapkan perubahan dua kali
+	err = tx.QueryRow(`UPDATE achmadnr.profile_change_requests
+		SET status = 'approved', reviewed_by = $2, review_comment = $3, reviewed_at = NOW()
+		WHERE id = $1 AND status = 'pending'
+		RETURNING reviewed_at`,
+		request.ID, request.ReviewedBy, request.ReviewComment).Scan(&request.ReviewedAt)
+	if err == sql.ErrNoRows {
+		err = domain.ErrChangeRequestReviewed
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	err = updateEmployee(tx, employee, changedBy)
+	return err
+}
+
+// ==================================================================== UTILITIES ====================================================================
+
+const changeRequestQuery = `SELECT c.id, c.employee_id, e.nip, e.full_name, c.changes, coalesce(c.note, ''), c.status,
+	c.reviewed_by, coalesce(rv.full_name, ''), coalesce(c.review_comment, ''), c.reviewed_at, c.created_at
+	FROM achmadnr.profile_change_requests c
+	INNER JOIN achmadnr.employees e ON c.employee_id = e.id
+	LEFT JOIN achmadnr.employees rv ON c.reviewed_by = rv.id`
+
+func scanChangeRequest(row interface{ Scan(...interface{}) error }) (*domain.ProfileChangeRequest, error) {
+	var request domain.ProfileChangeRequest
+	var changes []byte
+	var reviewedBy sql.NullString
+	var reviewedAt sql.NullTime
+	if err := row.Scan(
+		&request.ID,
+		&request.EmployeeID,
+		&request.NIP,
+		&request.EmployeeName,
+		&changes,
+		&request.Note,
+		&request.Status,
+		&reviewedBy,
+		&request.ReviewerName,
+		&request.ReviewComment,
+		&reviewedAt,
+		&request.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(changes, &request.Changes); err != nil {
+		return nil, err
+	}
+	if reviewedBy.Valid {
+		request.ReviewedBy = &reviewedBy.String
+	}
+	if reviewedAt.Valid {
+		request.ReviewedAt = &reviewedAt.Time
+	}
+	return &request, nil
+}
@@ -3,6 +3,8 @@ package repository
 import (
 	"database/sql"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/achmadnr21/emploman/internal/domain"
 )
@@ -20,7 +22,8 @@ func NewEmployeeRepository(db *sql.DB) *EmployeeRepository {
 func (r *EmployeeRepository) FindAll() ([]domain.Employee, error) {
 	query := `SELECT id, role_id, nip, password, full_name, place_of_birth, date_of_birth,
 	gender, phone_number, photo_url, address, coalesce(npwp, '-') as npwp, grade_id, religion_id,
-	echelon_id, created_at, modified_at FROM achmadnr.employees`
+	echelon_id, created_at, modified_at, employment_status, status_effective_date,
+	coalesce(status_reason, '') as status_reason FROM achmadnr.employees`
 	rows, err := r.db.Query(query)
 	if err != nil {
 		return nil, err
@@ -34,7 +37,8 @@ func (r *EmployeeRepository) FindAll() ([]domain.Employee, error) {
 			&employee.Gender, &employee.PhoneNumber, &employee.PhotoURL,
 			&employee.Address, &employee.NPWP, &employee.GradeID,
 			&employee.ReligionID, &employee.EchelonID, &employee.CreatedAt,
-			&employee.ModifiedAt)
+			&employee.ModifiedAt, &employee.EmploymentStatus, &employee.StatusEffectiveDate,
+			&employee.StatusReason)
 		if err != nil {
 			return nil, err
 		}
@@ -43,10 +47,142 @@ func (r *EmployeeRepository) FindAll() ([]domain.Employee, error) {
 	}
 	return employees, nil
 }
+func (r *EmployeeRepository) FindPage(filter domain.EmployeeFilter) ([]domain.Employee, int, error) {
+	var conditions []string
+	var args []interface{}
+	param := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	if filter.GradeID > 0 {
+		conditions = append(conditions, "e.grade_id = "+param(filter.GradeID))
+	}
+	if filter.EchelonID > 0 {
+		conditions = append(conditions, "e.echelon_id = "+param(filter.EchelonID))
+	}
+	if filter.ReligionID != "" {
+		conditions = append(conditions, "e.religion_id = "+param(filter.ReligionID))
+	}
+	if filter.Gender != "" {
+		conditions = append(conditions, "e.gender = "+param(filter.Gender))
+	}
+	if filter.RoleID != "" {
+		conditions = append(conditions, "e.role_id = "+param(filter.RoleID))
+	}
+	if filter.UnitID > 0 {
+		conditions = append(conditions, `EXISTS (SELECT 1 FROM achmadnr.employee_assignments ea
+		WHERE ea.employee_id = e.id AND `+activeAssignment("ea")+` AND ea.unit_id = `+param(filter.UnitID)+`)`)
+	}
+	if len(filter.ScopeUnitIDs) > 0 {
+		var placeholders []string
+		for _, unitID := range filter.ScopeUnitIDs {
+			placeholders = append(placeholders, param(unitID))
+		}
+		conditions = append(conditions, `EXISTS (SELECT 1 FROM achmadnr.employee_assignments ea
+		WHERE ea.employee_id = e.id AND `+activeAssignment("ea")+` AND ea.unit_id IN (`+strings.Join(placeholders, ", ")+`))`)
+	}
+	if len(filter.Statuses) > 0 {
+		var placeholders []string
+		for _, status := range filter.Statuses {
+			placeholders = append(placeholders, param(status))
+		}
+		conditions = append(conditions, "e.employment_status IN ("+strings.Join(placeholders, ", ")+")")
+	}
+	if filter.BirthYearFrom > 0 {
+		conditions = append(conditions, "EXTRACT(YEAR FROM e.date_of_birth) >= "+param(filter.BirthYearFrom))
+	}
+	if filter.BirthYearTo > 0 {
+		conditions = append(conditions, "EXTRACT(YEAR FROM e.date_of_birth) <= "+param(filter.BirthYearTo))
+	}
+
+	// total dihitung sebelum kondisi cursor ditambahkan
+	countQuery := `SELECT COUNT(*) FROM achmadnr.employees e` + whereClause(conditions)
+	var total int
+	if err := r.db.QueryRow(countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	// id selalu dipakai sebagai tie breaker agar urutan stabil
+	sort := make([]domain.SortField, 0, len(filter.Sort)+1)
+	sort = append(sort, filter.Sort...)
+	sort = append(sort, domain.SortField{Field: "id"})
+	var orderBy []string
+	for _, field := range sort {
+		direction := "ASC"
+		if field.Desc {
+			direction = "DESC"
+		}
+		orderBy = append(orderBy, "e."+field.Field+" "+direction)
+	}
+
+	// keyset: (a > x) OR (a = x AND b > y) OR ...
+	if len(filter.CursorValues) > 0 {
+		if len(filter.CursorValues) != len(sort) {
+			return nil, 0, fmt.Errorf("cursor does not match sort")
+		}
+		var keyset []string
+		for i, field := range sort {
+			var parts []string
+			for j := 0; j < i; j++ {
+				parts = append(parts, "e."+sort[j].Field+" = "+param(filter.CursorValues[j]))
+			}
+			operator := " > "
+			if field.Desc {
+				operator = " < "
+			}
+			parts = append(parts, "e."+field.Field+operator+param(filter.CursorValues[i]))
+			keyset = append(keyset, "("+strings.Join(parts, " AND ")+")")
+		}
+		conditions = append(conditions, "("+strings.Join(keyset, " OR ")+")")
+	}
+
+	query := `SELECT e.id, e.role_id, e.nip, e.password, e.full_name, e.place_of_birth, e.date_of_birth,
+	e.gender, e.phone_number, e.photo_url, e.address, coalesce(e.npwp, '-') as npwp, e.grade_id, e.religion_id,
+	e.echelon_id, e.created_at, e.modified_at, e.employment_status, e.status_effective_date,
+	coalesce(e.status_reason, '') as status_reason FROM achmadnr.employees e` + whereClause(conditions) +
+		` ORDER BY ` + strings.Join(orderBy, ", ") + ` LIMIT ` + param(filter.PageSize+1)
+	if len(filter.CursorValues) == 0 && filter.Page > 1 {
+		query += ` OFFSET ` + param((filter.Page-1)*filter.PageSize)
+	}
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	employees := []domain.Employee{}
+	for rows.Next() {
+		var employee domain.Employee
+		err := rows.Scan(&employee.ID, &employee.RoleID, &employee.NIP, &employee.Password,
+			&employee.FullName, &employee.PlaceOfBirth, &employee.DateOfBirth,
+			&employee.Gender, &employee.PhoneNumber, &employee.PhotoURL,
+			&employee.Address, &employee.NPWP, &employee.GradeID,
+			&employee.ReligionID, &employee.EchelonID, &employee.CreatedAt,
+			&employee.ModifiedAt, &employee.EmploymentStatus, &employee.StatusEffectiveDate,
+			&employee.StatusReason)
+		if err != nil {
+			return nil, 0, err
+		}
+		employee.Password = "" // Clear password for security
+		employees = append(employees, employee)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	return employees, total, nil
+}
+
+func whereClause(conditions []string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(conditions, " AND ")
+}
+
 func (r *EmployeeRepository) FindByID(id string) (*domain.Employee, error) {
 	query := `SELECT id, role_id, nip, password, full_name, place_of_birth, date_of_birth,
 	gender, phone_number, photo_url, address, coalesce(npwp, '-') as npwp, grade_id, religion_id,
-	echelon_id, created_at, modified_at FROM achmadnr.employees WHERE id = $1`
+	echelon_id, created_at, modified_at, employment_status, status_effective_date,
+	coalesce(status_reason, '') as status_reason FROM achmadnr.employees WHERE id = $1`
 	row := r.db.QueryRow(query, id)
 	var employee *domain.Employee = &domain.Employee{}
 	err := row.Scan(&employee.ID, &employee.RoleID, &employee.NIP, &employee.Password,
@@ -54,7 +190,8 @@ func (r *EmployeeRepository) FindByID(id string) (*domain.Employee, error) {
 		&employee.Gender, &employee.PhoneNumber, &employee.PhotoURL,
 		&employee.Address, &employee.NPWP, &employee.GradeID,
 		&employee.ReligionID, &employee.EchelonID, &employee.CreatedAt,
-		&employee.ModifiedAt)
+		&employee.ModifiedAt, &employee.EmploymentStatus, &employee.StatusEffectiveDate,
+		&employee.StatusReason)
 	if err != nil {
 		// if err == sql.ErrNoRows {
 		// 	return nil, nil // Not found
@@ -63,38 +200,168 @@ func (r *EmployeeRepository) FindByID(id string) (*domain.Employee, error) {
 	}
 	return employee, nil
 }
-func (r *EmployeeRepository) Save(employee *domain.Employee) (*domain.Employee, error) {
+func (r *EmployeeRepository) Save(employee *domain.Employee, changedBy string) (*domain.Employee, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			tx.Commit()
+		}
+	}()
+
 	query := `INSERT INTO achmadnr.employees (role_id, nip, password, full_name, place_of_birth,
 	date_of_birth, gender, phone_number, photo_url, address, npwp, grade_id,
 	religion_id, echelon_id) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10,
-	$11, $12, $13, $14) RETURNING id, created_at, modified_at`
-	err := r.db.QueryRow(query, employee.RoleID, employee.NIP, employee.Password,
+	$11, $12, $13, $14) RETURNING id, created_at, modified_at, employment_status, status_effective_date`
+	err = tx.QueryRow(query, employee.RoleID, employee.NIP, employee.Password,
 		employee.FullName, employee.PlaceOfBirth, employee.DateOfBirth,
 		employee.Gender, employee.PhoneNumber, employee.PhotoURL,
 		employee.Address, employee.NPWP, employee.GradeID,
-		employee.ReligionID, employee.EchelonID).Scan(&employee.ID, &employee.CreatedAt, &employee.ModifiedAt)
+		employee.ReligionID, employee.EchelonID).Scan(&employee.ID, &employee.CreatedAt, &employee.ModifiedAt,
+		&employee.EmploymentStatus, &employee.StatusEffectiveDate)
 	if err != nil {
 		return nil, err
 	}
+	if err = saveEmployeeVersion(tx, employee.ID, changedBy); err != nil {
+		return nil, err
+	}
 	return employee, nil
 
 }
-func (r *EmployeeRepository) Update(employee *domain.Employee) (*domain.Employee, error) {
-	query := `UPDATE achmadnr.employees SET role_id = $1, nip = $2, password = $3,
-	full_name = $4, place_of_birth = $5, date_of_birth = $6, gender = $7,
-	phone_number = $8, photo_url = $9, address = $10, npwp = $11,
-	grade_id = $12, religion_id = $13, echelon_id = $14,
-	modified_at = now() WHERE id = $15`
-	_, err := r.db.Exec(query, employee.RoleID, employee.NIP, employee.Password,
-		employee.FullName, employee.PlaceOfBirth, employee.DateOfBirth,
-		employee.Gender, employee.PhoneNumber, employee.PhotoURL,
-		employee.Address, employee.NPWP, employee.GradeID,
-		employee.ReligionID, employee.EchelonID, employee.ID)
+func (r *EmployeeRepository) SaveBatch(employees []*domain.Employee, changedBy string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			tx.Commit()
+		}
+	}()
+
+	stmt, err := tx.Prepare(`INSERT INTO achmadnr.employees (role_id, nip, password, full_name, place_of_birth,
+	date_of_birth, gender, phone_number, photo_url, address, npwp, grade_id,
+	religion_id, echelon_id) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10,
+	$11, $12, $13, $14) RETURNING id, created_at, modified_at, employment_status, status_effective_date`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, employee := range employees {
+		err = stmt.QueryRow(employee.RoleID, employee.NIP, employee.Password,
+			employee.FullName, employee.PlaceOfBirth, employee.DateOfBirth,
+			employee.Gender, employee.PhoneNumber, employee.PhotoURL,
+			employee.Address, employee.NPWP, employee.GradeID,
+			employee.ReligionID, employee.EchelonID).Scan(&employee.ID, &employee.CreatedAt, &employee.ModifiedAt,
+			&employee.EmploymentStatus, &employee.StatusEffectiveDate)
+		if err != nil {
+			return fmt.Errorf("failed to insert employee %s: %w", employee.NIP, err)
+		}
+		if err = saveEmployeeVersion(tx, employee.ID, changedBy); err != nil {
+			return fmt.Errorf("failed to record version of employee %s: %w", employee.NIP, err)
+		}
+	}
+	return nil
+}
+func (r *EmployeeRepository) Update(employee *domain.Employee, changedBy string) (*domain.Employee, error) {
+	tx, err := r.db.Begin()
 	if err != nil {
 		return nil, err
 	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			tx.Commit()
+		}
+	}()
+
+	if err = updateEmployee(tx, employee, changedBy); err != nil {
+		return nil, err
+	}
 	return employee, nil
 }
+func (r *EmployeeRepository) UpdateStatus(history *domain.EmployeeStatusHistory) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			tx.Commit()
+		}
+	}()
+
+	// 1. Kunci baris employee dan ambil status saat ini
+	err = tx.QueryRow(`SELECT employment_status FROM achmadnr.employees WHERE id = $1 FOR UPDATE`,
+		history.EmployeeID).Scan(&history.FromStatus)
+	if err != nil {
+		return err
+	}
+	if err = lockEmployeeVersion(tx, history.EmployeeID); err != nil {
+		return err
+	}
+
+	// 2. Update status employee
+	_, err = tx.Exec(`
+		UPDATE achmadnr.employees
+		SET employment_status = $1, status_effective_date = $2, status_reason = $3, modified_at = NOW()
+		WHERE id = $4
+	`, history.ToStatus, history.EffectiveDate, history.Reason, history.EmployeeID)
+	if err != nil {
+		return err
+	}
+
+	// 3. Catat history
+	err = tx.QueryRow(`
+		INSERT INTO achmadnr.employee_status_histories (
+			employee_id, from_status, to_status, effective_date, reason, changed_by
+		) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at
+	`, history.EmployeeID, history.FromStatus, history.ToStatus, history.EffectiveDate,
+		history.Reason, history.ChangedBy).Scan(&history.ID, &history.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	// 4. Catat versi baru
+	if err = saveEmployeeVersion(tx, history.EmployeeID, history.ChangedBy); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *EmployeeRepository) FindStatusHistory(employeeID string) ([]domain.EmployeeStatusHistory, error) {
+	query := `SELECT id, employee_id, from_status, to_status, effective_date, reason, changed_by, created_at
+	FROM achmadnr.employee_status_histories WHERE employee_id = $1
+	ORDER BY created_at DESC, id DESC`
+	rows, err := r.db.Query(query, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	histories := []domain.EmployeeStatusHistory{}
+	for rows.Next() {
+		var history domain.EmployeeStatusHistory
+		if err := rows.Scan(&history.ID, &history.EmployeeID, &history.FromStatus, &history.ToStatus,
+			&history.EffectiveDate, &history.Reason, &history.ChangedBy, &history.CreatedAt); err != nil {
+			return nil, err
+		}
+		histories = append(histories, history)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return histories, nil
+}
 
 // UploadProfileImage akan menyimpan url foto ke database
 func (r *EmployeeRepository) UploadProfileImage(id string, fileName string) (string, error) {
@@ -123,7 +390,8 @@ func (r *EmployeeRepository) Delete(id string) error {
 func (r *EmployeeRepository) FindByNIP(nip string) (*domain.Employee, error) {
 	query := `SELECT id, role_id, nip, password, full_name, place_of_birth, date_of_birth, gender,
 	phone_number, photo_url, address, coalesce(npwp, '-') as npwp, grade_id, religion_id,
-	echelon_id, created_at, modified_at FROM achmadnr.employees WHERE nip = $1`
+	echelon_id, created_at, modified_at, employment_status, status_effective_date,
+	coalesce(status_reason, '') as status_reason FROM achmadnr.employees WHERE nip = $1`
 	row := r.db.QueryRow(query, nip)
 	var employee *domain.Employee = &domain.Employee{}
 	err := row.Scan(&employee.ID, &employee.RoleID, &employee.NIP, &employee.Password,
@@ -131,7 +399,8 @@ func (r *EmployeeRepository) FindByNIP(nip string) (*domain.Employee, error) {
 		&employee.Gender, &employee.PhoneNumber, &employee.PhotoURL,
 		&employee.Address, &employee.NPWP, &employee.GradeID,
 		&employee.ReligionID, &employee.EchelonID, &employee.CreatedAt,
-		&employee.ModifiedAt)
+		&employee.ModifiedAt, &employee.EmploymentStatus, &employee.StatusEffectiveDate,
+		&employee.StatusReason)
 	if err != nil {
 		// if err == sql.ErrNoRows {
 		// 	return nil, nil // Not found
@@ -144,7 +413,8 @@ func (r *EmployeeRepository) FindByNIP(nip string) (*domain.Employee, error) {
 func (r *EmployeeRepository) FindByName(name string) ([]domain.Employee, error) {
 	query := `SELECT id, role_id, nip, password, full_name, place_of_birth, date_of_birth, gender,
 	phone_number, photo_url, address, coalesce(npwp, '-') as npwp, grade_id, religion_id,
-	echelon_id, created_at, modified_at FROM achmadnr.employees WHERE full_name ILIKE '%' || $1 || '%'`
+	echelon_id, created_at, modified_at, employment_status, status_effective_date,
+	coalesce(status_reason, '') as status_reason FROM achmadnr.employees WHERE full_name ILIKE '%' || $1 || '%'`
 	rows, err := r.db.Query(query, name)
 	if err != nil {
 		return nil, err
@@ -158,7 +428,8 @@ func (r *EmployeeRepository) FindByName(name string) ([]domain.Employee, error)
 			&employee.Gender, &employee.PhoneNumber, &employee.PhotoURL,
 			&employee.Address, &employee.NPWP, &employee.GradeID,
 			&employee.ReligionID, &employee.EchelonID, &employee.CreatedAt,
-			&employee.ModifiedAt)
+			&employee.ModifiedAt, &employee.EmploymentStatus, &employee.StatusEffectiveDate,
+			&employee.StatusReason)
 		if err != nil {
 			return nil, err
 		}
@@ -171,10 +442,12 @@ func (r *EmployeeRepository) FindByName(name string) ([]domain.Employee, error)
 func (r *EmployeeRepository) FindByUnit(unitID int) ([]domain.Employee, error) {
 	query := `SELECT e.id, e.role_id, e.nip, e.password, e.full_name, e.place_of_birth, e.date_of_birth, e.gender,
 	e.phone_number, e.photo_url, e.address, coalesce(e.npwp, '-') as npwp, e.grade_id, e.religion_id,
-	e.echelon_id, e.created_at, e.modified_at
-	FROM achmadnr.employees e 
-	right join achmadnr.employee_assignments ea on e.id = ea.employee_id
-	where ea.unit_id = $1`
+	e.echelon_id, e.created_at, e.modified_at, e.employment_status, e.status_effective_date,
+	coalesce(e.status_reason, '') as status_reason
+	FROM achmadnr.employees e
+	where exists (select 1 from achmadnr.employee_assignments ea
+		where ea.employee_id = e.id and ea.unit_id = $1 and ` + activeAssignment("ea") + `)
+	and e.employment_status in ('active', 'on_leave')`
 	rows, err := r.db.Query(query, unitID)
 	if err != nil {
 		return nil, err
@@ -188,7 +461,8 @@ func (r *EmployeeRepository) FindByUnit(unitID int) ([]domain.Employee, error) {
 			&employee.Gender, &employee.PhoneNumber, &employee.PhotoURL,
 			&employee.Address, &employee.NPWP, &employee.GradeID,
 			&employee.ReligionID, &employee.EchelonID, &employee.CreatedAt,
-			&employee.ModifiedAt)
+			&employee.ModifiedAt, &employee.EmploymentStatus, &employee.StatusEffectiveDate,
+			&employee.StatusReason)
 		if err != nil {
 			return nil, err
 		}
@@ -197,11 +471,30 @@ func (r *EmployeeRepository) FindByUnit(unitID int) ([]domain.Employee, error) {
 	}
 	return employees, nil
 }
-func (r *EmployeeRepository) Search(input string) ([]domain.Employee, error) {
+func (r *EmployeeRepository) Search(input string, scopeUnitIDs []int) ([]domain.Employee, error) {
 	query := `SELECT id, role_id, nip, password, full_name, place_of_birth, date_of_birth, gender,
 	phone_number, photo_url, address, coalesce(npwp, '-') as npwp, grade_id, religion_id,
-	echelon_id, created_at, modified_at FROM achmadnr.employees WHERE nip = $1 OR full_name ILIKE '%' || $1 || '%'`
-	rows, err := r.db.Query(query, input)
+	echelon_id, created_at, modified_at, employment_status, status_effective_date,
+	coalesce(status_reason, '') as status_reason FROM achmadnr.employees e WHERE (nip = $1 OR full_name ILIKE '%' || $1 || '%')`
+	args := []interface{}{input}
+	// seperti listing default, pegawai yang sudah pensiun/berhenti tidak ikut dicari
+	var statuses []string
+	for _, status := range domain.ActiveEmploymentStatuses {
+		args = append(args, status)
+		statuses = append(statuses, fmt.Sprintf("$%d", len(args)))
+	}
+	query += ` AND e.employment_status IN (` + strings.Join(statuses, ", ") + `)`
+	// unit scope disaring di query seperti FindPage, bukan per baris di usecase
+	if len(scopeUnitIDs) > 0 {
+		var placeholders []string
+		for _, unitID := range scopeUnitIDs {
+			args = append(args, unitID)
+			placeholders = append(placeholders, fmt.Sprintf("$%d", len(args)))
+		}
+		query += ` AND EXISTS (SELECT 1 FROM achmadnr.employee_assignments ea
+		WHERE ea.employee_id = e.id AND ` + activeAssignment("ea") + ` AND ea.unit_id IN (` + strings.Join(placeholders, ", ") + `))`
+	}
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -214,7 +507,8 @@ func (r *EmployeeRepository) Search(input string) ([]domain.Employee, error) {
 			&employee.Gender, &employee.PhoneNumber, &employee.PhotoURL,
 			&employee.Address, &employee.NPWP, &employee.GradeID,
 			&employee.ReligionID, &employee.EchelonID, &employee.CreatedAt,
-			&employee.ModifiedAt)
+			&employee.ModifiedAt, &employee.EmploymentStatus, &employee.StatusEffectiveDate,
+			&employee.StatusReason)
 		if err != nil {
 			return nil, err
 		}
@@ -243,3 +537,115 @@ type PrintEmployee struct {
 	NPWP         string    `json:"npwp"`
 }
 */
+
+// FindVersions mengembalikan seluruh versi employee, versi terlama lebih dulu
+func (r *EmployeeRepository) FindVersions(employeeID string) ([]domain.EmployeeVersion, error) {
+	query := employeeVersionQuery + ` WHERE v.employee_id = $1 ORDER BY v.version ASC`
+	rows, err := r.db.Query(query, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	versions := []domain.EmployeeVersion{}
+	for rows.Next() {
+		version, err := scanEmployeeVersion(rows)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, *version)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// FindVersionAsOf mengembalikan versi terakhir yang sudah berlaku sebelum asOf
+func (r *EmployeeRepository) FindVersionAsOf(employeeID string, asOf time.Time) (*domain.EmployeeVersion, error) {
+	query := employeeVersionQuery + ` WHERE v.employee_id = $1 AND v.valid_from < $2
+	ORDER BY v.version DESC LIMIT 1`
+	return scanEmployeeVersion(r.db.QueryRow(query, employeeID, asOf))
+}
+
+// ==================================================================== UTILITIES ====================================================================
+
+// employeeVersionColumns adalah kolom employee yang disalin ke setiap versi (tanpa password)
+const employeeVersionColumns = `role_id, nip, full_name, place_of_birth, date_of_birth, gender,
+	phone_number, photo_url, address, npwp, grade_id, religion_id, echelon_id,
+	employment_status, status_effective_date, status_reason, created_at`
+
+const employeeVersionQuery = `SELECT v.id, v.version, v.employee_id, v.role_id, v.nip, v.full_name,
+	v.place_of_birth, v.date_of_birth, v.gender, v.phone_number, v.photo_url, v.address,
+	coalesce(v.npwp, '-'), v.grade_id, v.religion_id, v.echelon_id, v.employment_status,
+	v.status_effective_date, coalesce(v.status_reason, ''), v.created_at,
+	coalesce(v.changed_by::text, ''), coalesce(e.full_name, ''), v.valid_from
+	FROM achmadnr.employee_versions v
+	LEFT JOIN achmadnr.employees e ON v.changed_by = e.id`
+
+func scanEmployeeVersion(row interface{ Scan(...interface{}) error }) (*domain.EmployeeVersion, error) {
+	var version domain.EmployeeVersion
+	employee := &version.Employee
+	err := row.Scan(&version.ID, &version.Version, &employee.ID, &employee.RoleID, &employee.NIP,
+		&employee.FullName, &employee.PlaceOfBirth, &employee.DateOfBirth, &employee.Gender,
+		&employee.PhoneNumber, &employee.PhotoURL, &employee.Address, &employee.NPWP,
+		&employee.GradeID, &employee.ReligionID, &employee.EchelonID, &employee.EmploymentStatus,
+		&employee.StatusEffectiveDate, &employee.StatusReason, &employee.CreatedAt,
+		&version.ChangedBy, &version.ChangedByName, &version.ValidFrom)
+	if err != nil {
+		return nil, err
+	}
+	employee.ModifiedAt = version.ValidFrom
+	return &version, nil
+}
+
+// lockEmployeeVersion mengunci baris employee lalu menyimpan kondisi saat ini sebagai versi awal
+// jika employee belum memiliki history (misal data lama sebelum history dicatat). Versi awal berlaku
+// sejak employee dibuat agar GetByNIPAsOf tetap menemukan data sebelum perubahan pertama.
+func lockEmployeeVersion(tx *sql.Tx, employeeID string) error {
+	if _, err := tx.Exec(`SELECT id FROM achmadnr.employees WHERE id = $1 FOR UPDATE`, employeeID); err != nil {
+		return err
+	}
+	_, err := tx.Exec(`INSERT INTO achmadnr.employee_versions (employee_id, version, changed_by, valid_from, `+employeeVersionColumns+`)
+	SELECT id, 1, NULL, coalesce(created_at, modified_at), `+employeeVersionColumns+` FROM achmadnr.employees
+	WHERE id = $1 AND NOT EXISTS (SELECT 1 FROM achmadnr.employee_versions WHERE employee_id = $1)`, employeeID)
+	return err
+}
+
+// updateEmployee menjalankan langkah Update di dalam tx milik caller,
+// dipakai juga saat change request disetujui agar klaim dan perubahan employee tersimpan bersama
+func updateEmployee(tx *sql.Tx, employee *domain.Employee, changedBy string) error {
+	// 1. Kunci baris employee dan simpan snapshot awal jika belum punya history
+	if err := lockEmployeeVersion(tx, employee.ID); err != nil {
+		return err
+	}
+
+	// 2. Update employee, password hanya diubah lewat PasswordRepository
+	query := `UPDATE achmadnr.employees SET role_id = $1, nip = $2,
+	full_name = $3, place_of_birth = $4, date_of_birth = $5, gender = $6,
+	phone_number = $7, photo_url = $8, address = $9, npwp = $10,
+	grade_id = $11, religion_id = $12, echelon_id = $13,
+	modified_at = now() WHERE id = $14`
+	_, err := tx.Exec(query, employee.RoleID, employee.NIP,
+		employee.FullName, employee.PlaceOfBirth, employee.DateOfBirth,
+		employee.Gender, employee.PhoneNumber, employee.PhotoURL,
+		employee.Address, employee.NPWP, employee.GradeID,
+		employee.ReligionID, employee.EchelonID, employee.ID)
+	if err != nil {
+		return err
+	}
+
+	// 3. Catat versi baru
+	return saveEmployeeVersion(tx, employee.ID, changedBy)
+}
+
+// saveEmployeeVersion menyalin kondisi employee saat ini sebagai versi berikutnya
+func saveEmployeeVersion(tx *sql.Tx, employeeID string, changedBy string) error {
+	var actor interface{}
+	if changedBy != "" {
+		actor = changedBy
+	}
+	_, err := tx.Exec(`INSERT INTO achmadnr.employee_versions (employee_id, version, changed_by, valid_from, `+employeeVersionColumns+`)
+	SELECT id, coalesce((SELECT max(version) FROM achmadnr.employee_versions WHERE employee_id = $1), 0) + 1,
+	$2, modified_at, `+employeeVersionColumns+` FROM achmadnr.employees WHERE id = $1`, employeeID, actor)
+	return err
+}
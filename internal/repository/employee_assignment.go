@@ -3,6 +3,7 @@ package repository
 import (
 	"database/sql"
 	"fmt"
+	"time"
 
 	"github.com/achmadnr21/emploman/internal/domain"
 )
@@ -31,50 +32,84 @@ func (r *EmployeeAssignmentRepository) TransactionalAssignment(employeeAssignmen
 		}
 	}()
 
-	// 1. Nonaktifkan assignment aktif (jika ada)
-	_, err = tx.Exec(`
-		UPDATE achmadnr.employee_assignments
-		SET is_active = FALSE, assigned_at = NOW()
-		WHERE employee_id = $1 AND is_active = TRUE
-	`, employeeAssignment.EmployeeID)
+	err = insertAssignment(tx, employeeAssignment)
+	return err
+}
+
+// insertAssignment menjalankan langkah TransactionalAssignment di dalam tx milik caller,
+// dipakai juga oleh eksekusi mutasi agar assignment dan status mutasi tersimpan bersama
+func insertAssignment(tx *sql.Tx, employeeAssignment *domain.EmployeeAssignment) error {
+	// 1. Kunci employee agar periode tidak diubah bersamaan
+	_, err := tx.Exec(`SELECT id FROM achmadnr.employees WHERE id = $1 FOR UPDATE`, employeeAssignment.EmployeeID)
 	if err != nil {
 		return err
 	}
 
-	// 2. Coba aktifkan kembali assignment lama
-	var dummy int
-	err = tx.QueryRow(`
-		WITH updated AS (
-			UPDATE achmadnr.employee_assignments
-			SET is_active = TRUE, assigned_at = NOW()
-			WHERE employee_id = $1 AND unit_id = $2 AND position_id = $3
-			RETURNING 1
-		)
-		SELECT 1 FROM updated LIMIT 1
-	`, employeeAssignment.EmployeeID, employeeAssignment.UnitID, employeeAssignment.PositionID).Scan(&dummy)
-
-	if err == sql.ErrNoRows {
-		// 3. Tidak ditemukan, insert baru
+	// Plt/Plh dan tugas tambahan dirangkap, tidak menutup jabatan definitif dan tidak memakai kursi formasi
+	primary := employeeAssignment.Type == domain.AssignmentTypePrimary
+
+	// 2. Tutup periode primary yang masih terbuka sehari sebelum periode baru dimulai
+	if primary {
 		_, err = tx.Exec(`
-			INSERT INTO achmadnr.employee_assignments (
-				employee_id, unit_id, position_id, is_active, assigned_at
-			) VALUES ($1, $2, $3, TRUE, NOW())
-		`, employeeAssignment.EmployeeID, employeeAssignment.UnitID, employeeAssignment.PositionID)
+			UPDATE achmadnr.employee_assignments
+			SET end_date = $2::date - 1, modified_at = NOW()
+			WHERE employee_id = $1 AND assignment_type = 'primary' AND end_date IS NULL
+		`, employeeAssignment.EmployeeID, employeeAssignment.StartDate)
 		if err != nil {
 			return err
 		}
-	} else if err != nil {
+	}
+
+	// 3. Cek kuota formasi, baris formasi dikunci agar assignment bersamaan tidak melebihi kuota
+	if primary && !employeeAssignment.OverrideFormation {
+		var quota, filled int
+		err = tx.QueryRow(`
+			SELECT f.quota, (SELECT COUNT(*) FROM achmadnr.employee_assignments ea
+				INNER JOIN achmadnr.employees e ON ea.employee_id = e.id
+				WHERE ea.unit_id = f.unit_id AND ea.position_id = f.position_id AND ea.assignment_type = 'primary'
+				AND `+activeAssignment("ea")+` AND e.employment_status IN ('active', 'on_leave'))
+			FROM achmadnr.formations f
+			WHERE f.unit_id = $1 AND f.position_id = $2
+			FOR UPDATE OF f
+		`, employeeAssignment.UnitID, employeeAssignment.PositionID).Scan(&quota, &filled)
+		switch {
+		case err == sql.ErrNoRows:
+			// formasi belum ditetapkan, tidak ada batas kuota
+			err = nil
+		case err != nil:
+			return err
+		case filled >= quota:
+			return domain.ErrFormationFull
+		}
+	}
+
+	// 4. Simpan periode baru, riwayat periode lama tetap tersimpan
+	err = tx.QueryRow(`
+		INSERT INTO achmadnr.employee_assignments (
+			employee_id, unit_id, position_id, start_date, end_date, decree_number, reason, assignment_type
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, `+activeAssignment("employee_assignments")+`, created_at, modified_at
+	`, employeeAssignment.EmployeeID, employeeAssignment.UnitID, employeeAssignment.PositionID,
+		employeeAssignment.StartDate, employeeAssignment.EndDate, employeeAssignment.DecreeNumber,
+		employeeAssignment.Reason, employeeAssignment.Type).Scan(&employeeAssignment.ID, &employeeAssignment.IsActive,
+		&employeeAssignment.CreatedAt, &employeeAssignment.ModifiedAt)
+	// exclusion constraint periode primary
+	if isPgError(err, pgExclusionViolation) {
+		return domain.ErrAssignmentOverlap
+	}
+	if err != nil {
 		return err
 	}
 
 	return nil
-
 }
 
-func (r *EmployeeAssignmentRepository) Deactivate(employeeID string, unitID int, positionID int) error {
-	// update is_active to false
-	query := `UPDATE achmadnr.employee_assignments SET is_active = FALSE, assigned_at = NOW() WHERE employee_id = $1 AND unit_id = $2 AND position_id = $3`
-	_, err := r.db.Exec(query, employeeID, unitID, positionID)
+func (r *EmployeeAssignmentRepository) Deactivate(employeeID string, unitID int, positionID int, endDate time.Time) error {
+	// tutup periode yang masih berjalan pada endDate, termasuk Plt yang belum mencapai tanggal berakhirnya
+	query := `UPDATE achmadnr.employee_assignments SET end_date = $4, modified_at = NOW()
+	WHERE employee_id = $1 AND unit_id = $2 AND position_id = $3 AND start_date <= $4
+	AND (end_date IS NULL OR end_date > $4)`
+	_, err := r.db.Exec(query, employeeID, unitID, positionID, endDate)
 	if err != nil {
 		return fmt.Errorf("failed to deactivate employee assignment: %w", err)
 	}
@@ -83,118 +118,97 @@ func (r *EmployeeAssignmentRepository) Deactivate(employeeID string, unitID int,
 }
 
 func (r *EmployeeAssignmentRepository) FindAll() ([]domain.EmployeeAssignmentResponse, error) {
-	query := `SELECT ea.employee_id, ea.unit_id, ea.position_id, ea.is_active, ea.assigned_at, e.full_name as employee_name, u.name as unit_name, p.name as position_name
+	query := employeeAssignmentQuery + ` ORDER BY ea.start_date DESC, ea.id DESC`
+	return r.findMany(query)
+}
+func (r *EmployeeAssignmentRepository) FindByID(employeeID string, unitID int, positionID int) (*domain.EmployeeAssignmentResponse, error) {
+	query := employeeAssignmentQuery + `
+	WHERE ea.employee_id = $1 AND ea.unit_id = $2 AND ea.position_id = $3
+	ORDER BY ea.start_date DESC, ea.id DESC
+	LIMIT 1`
+	return scanEmployeeAssignment(r.db.QueryRow(query, employeeID, unitID, positionID))
+}
+
+// FindByEmployeeID mengembalikan seluruh assignment aktif employee, jabatan definitif lebih dulu
+func (r *EmployeeAssignmentRepository) FindByEmployeeID(employeeID string) ([]domain.EmployeeAssignmentResponse, error) {
+	query := employeeAssignmentQuery + `
+	WHERE ea.employee_id = $1 AND ` + activeAssignment("ea") + `
+	ORDER BY (ea.assignment_type = 'primary') DESC, ea.start_date DESC, ea.id DESC`
+	return r.findMany(query, employeeID)
+}
+func (r *EmployeeAssignmentRepository) FindByUnitID(unitID int) ([]domain.EmployeeAssignmentResponse, error) {
+	query := employeeAssignmentQuery + `
+	WHERE ea.unit_id = $1
+	ORDER BY ea.start_date DESC, ea.id DESC`
+	return r.findMany(query, unitID)
+}
+
+// FindHistoryByEmployeeID mengembalikan seluruh periode assignment employee, terbaru lebih dulu
+func (r *EmployeeAssignmentRepository) FindHistoryByEmployeeID(employeeID string) ([]domain.EmployeeAssignmentResponse, error) {
+	query := employeeAssignmentQuery + `
+	WHERE ea.employee_id = $1
+	ORDER BY ea.start_date DESC, ea.id DESC`
+	return r.findMany(query, employeeID)
+}
+
+// ==================================================================== UTILITIES ====================================================================
+
+// activeAssignment adalah kondisi periode yang sedang berjalan hari ini. Periode dengan end_date (misal Plt)
+// otomatis tidak aktif lagi setelah end_date terlewati.
+func activeAssignment(alias string) string {
+	return fmt.Sprintf("(%[1]s.start_date <= CURRENT_DATE AND (%[1]s.end_date IS NULL OR %[1]s.end_date >= CURRENT_DATE))", alias)
+}
+
+var employeeAssignmentQuery = `SELECT ea.id, ea.employee_id, ea.unit_id, ea.position_id, ea.start_date, ea.end_date,
+	coalesce(ea.decree_number, ''), ea.reason, ea.assignment_type, ` + activeAssignment("ea") + ` as is_active,
+	e.full_name as employee_name, u.name as unit_name, p.name as position_name, p.is_head
 	FROM achmadnr.employee_assignments ea
 	INNER JOIN achmadnr.employees e ON ea.employee_id = e.id
 	INNER JOIN achmadnr.units u ON ea.unit_id = u.id
 	INNER JOIN achmadnr.positions p ON ea.position_id = p.id`
-	rows, err := r.db.Query(query)
+
+func (r *EmployeeAssignmentRepository) findMany(query string, args ...interface{}) ([]domain.EmployeeAssignmentResponse, error) {
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-
 	var employeeAssignments []domain.EmployeeAssignmentResponse
 	for rows.Next() {
-		var employeeAssignment domain.EmployeeAssignmentResponse
-		if err := rows.Scan(
-			&employeeAssignment.EmployeeID,
-			&employeeAssignment.UnitID,
-			&employeeAssignment.PositionID,
-			&employeeAssignment.IsActive,
-			&employeeAssignment.AssignedAt,
-			&employeeAssignment.EmployeeName,
-			&employeeAssignment.UnitName,
-			&employeeAssignment.PositionName); err != nil {
+		employeeAssignment, err := scanEmployeeAssignment(rows)
+		if err != nil {
 			return nil, err
 		}
-		employeeAssignments = append(employeeAssignments, employeeAssignment)
+		employeeAssignments = append(employeeAssignments, *employeeAssignment)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
 	return employeeAssignments, nil
 }
-func (r *EmployeeAssignmentRepository) FindByID(employeeID string, unitID int, positionID int) (*domain.EmployeeAssignmentResponse, error) {
-	query := `SELECT ea.employee_id, ea.unit_id, ea.position_id, ea.is_active, ea.assigned_at, ea.created_at, ea.modified_at, e.full_name as employee_name, u.name as unit_name, p.name as position_name
-	FROM achmadnr.employee_assignments ea
-	INNER JOIN achmadnr.employees e ON ea.employee_id = e.id
-	INNER JOIN achmadnr.units u ON ea.unit_id = u.id
-	INNER JOIN achmadnr.positions p ON ea.position_id = p.id
-	WHERE ea.employee_id = $1 AND ea.unit_id = $2 AND ea.position_id = $3
-	ORDER BY ea.assigned_at DESC
-	LIMIT 1`
-	row := r.db.QueryRow(query, employeeID, unitID, positionID)
-	var employeeAssignment domain.EmployeeAssignmentResponse
-	if err := row.Scan(
-		&employeeAssignment.EmployeeID,
-		&employeeAssignment.UnitID,
-		&employeeAssignment.PositionID,
-		&employeeAssignment.IsActive,
-		&employeeAssignment.AssignedAt,
-		&employeeAssignment.EmployeeName,
-		&employeeAssignment.UnitName,
-		&employeeAssignment.PositionName); err != nil {
-		return nil, err
-	}
-	return &employeeAssignment, nil
-}
 
-func (r *EmployeeAssignmentRepository) FindByEmployeeID(employeeID string) (*domain.EmployeeAssignmentResponse, error) {
-	query := `SELECT ea.employee_id, ea.unit_id, ea.position_id, ea.is_active, ea.assigned_at, e.full_name as employee_name, u.name as unit_name, p.name as position_name
-	FROM achmadnr.employee_assignments ea
-	INNER JOIN achmadnr.employees e ON ea.employee_id = e.id
-	INNER JOIN achmadnr.units u ON ea.unit_id = u.id
-	INNER JOIN achmadnr.positions p ON ea.position_id = p.id
-	WHERE ea.employee_id = $1 
-	ORDER BY ea.assigned_at DESC
-	LIMIT 1`
-	row := r.db.QueryRow(query, employeeID)
+func scanEmployeeAssignment(row interface{ Scan(...interface{}) error }) (*domain.EmployeeAssignmentResponse, error) {
 	var employeeAssignment domain.EmployeeAssignmentResponse
+	var endDate sql.NullTime
 	if err := row.Scan(
+		&employeeAssignment.ID,
 		&employeeAssignment.EmployeeID,
 		&employeeAssignment.UnitID,
 		&employeeAssignment.PositionID,
+		&employeeAssignment.StartDate,
+		&endDate,
+		&employeeAssignment.DecreeNumber,
+		&employeeAssignment.Reason,
+		&employeeAssignment.Type,
 		&employeeAssignment.IsActive,
-		&employeeAssignment.AssignedAt,
 		&employeeAssignment.EmployeeName,
 		&employeeAssignment.UnitName,
-		&employeeAssignment.PositionName); err != nil {
-		return nil, err
-	}
-	return &employeeAssignment, nil
-}
-func (r *EmployeeAssignmentRepository) FindByUnitID(unitID int) ([]domain.EmployeeAssignmentResponse, error) {
-	query := `SELECT ea.employee_id, ea.unit_id, ea.position_id, ea.is_active, ea.assigned_at, e.full_name as employee_name, u.name as unit_name, p.name as position_name
-	FROM achmadnr.employee_assignments ea
-	INNER JOIN achmadnr.employees e ON ea.employee_id = e.id
-	INNER JOIN achmadnr.units u ON ea.unit_id = u.id
-	INNER JOIN achmadnr.positions p ON ea.position_id = p.id
-	WHERE ea.unit_id = $1
-	ORDER BY ea.assigned_at DESC`
-	rows, err := r.db.Query(query, unitID)
-	if err != nil {
+		&employeeAssignment.PositionName,
+		&employeeAssignment.IsHead); err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-	var employeeAssignments []domain.EmployeeAssignmentResponse
-	for rows.Next() {
-		var employeeAssignment domain.EmployeeAssignmentResponse
-		if err := rows.Scan(
-			&employeeAssignment.EmployeeID,
-			&employeeAssignment.UnitID,
-			&employeeAssignment.PositionID,
-			&employeeAssignment.IsActive,
-			&employeeAssignment.AssignedAt,
-			&employeeAssignment.EmployeeName,
-			&employeeAssignment.UnitName,
-			&employeeAssignment.PositionName); err != nil {
-			return nil, err
-		}
-		employeeAssignments = append(employeeAssignments, employeeAssignment)
+	if endDate.Valid {
+		employeeAssignment.EndDate = &endDate.Time
 	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return employeeAssignments, nil
+	return &employeeAssignment, nil
 }
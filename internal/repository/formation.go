@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/achmadnr21/emploman/internal/domain"
+)
+
+type FormationRepository struct {
+	db *sql.DB
+}
+
+func NewFormationRepository(db *sql.DB) *FormationRepository {
+	return &FormationRepository{
+		db: db,
+	}
+}
+
+func (r *FormationRepository) FindByUnitID(unitID int) ([]domain.Formation, error) {
+	query := formationQuery + ` WHERE f.unit_id = $1) f ORDER BY f.position_name`
+	return r.findMany(query, unitID)
+}
+
+func (r *FormationRepository) Save(formation *domain.Formation) (*domain.Formation, error) {
+	query := `INSERT INTO achmadnr.formations (unit_id, position_id, quota) VALUES ($1, $2, $3)
+	ON CONFLICT (unit_id, position_id) DO UPDATE SET quota = EXCLUDED.quota, modified_at = NOW()
+	RETURNING id, created_at, modified_at`
+	err := r.db.QueryRow(query, formation.UnitID, formation.PositionID, formation.Quota).Scan(&formation.ID, &formation.CreatedAt, &formation.ModifiedAt)
+	if err != nil {
+		return nil, err
+	}
+	return formation, nil
+}
+
+func (r *FormationRepository) Delete(unitID int, positionID int) error {
+	query := `DELETE FROM achmadnr.formations WHERE unit_id = $1 AND position_id = $2`
+	res, err := r.db.Exec(query, unitID, positionID)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("no rows deleted")
+	}
+	return nil
+}
+
+func (r *FormationRepository) FindVacancies(unitID int) ([]domain.Formation, error) {
+	query := formationQuery + ` WHERE $1 = 0 OR f.unit_id = $1) f
+	WHERE f.filled < f.quota
+	ORDER BY f.unit_name, f.position_name`
+	return r.findMany(query, unitID)
+}
+
+// ==================================================================== UTILITIES ====================================================================
+
+// formationQuery dibungkus subquery agar filled dapat difilter, pemanggil menambahkan WHERE dan penutup ") f".
+// Plt/Plh tidak dihitung karena tidak mengisi kursi formasi.
+var formationQuery = `SELECT f.id, f.unit_id, f.unit_name, f.position_id, f.position_name, f.quota, f.filled, f.created_at, f.modified_at
+	FROM (SELECT f.id, f.unit_id, u.name AS unit_name, f.position_id, p.name AS position_name, f.quota, f.created_at, f.modified_at,
+		(SELECT COUNT(*) FROM achmadnr.employee_assignments ea
+			INNER JOIN achmadnr.employees e ON ea.employee_id = e.id
+			WHERE ea.unit_id = f.unit_id AND ea.position_id = f.position_id AND ea.assignment_type = 'primary'
+			AND ` + activeAssignment("ea") + ` AND e.employment_status IN ('active', 'on_leave')) AS filled
+		FROM achmadnr.formations f
+		INNER JOIN achmadnr.units u ON f.unit_id = u.id
+		INNER JOIN achmadnr.positions p ON f.position_id = p.id`
+
+func (r *FormationRepository) findMany(query string, args ...interface{}) ([]domain.Formation, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	formations := []domain.Formation{}
+	for rows.Next() {
+		var formation domain.Formation
+		if err := rows.Scan(
+			&formation.ID,
+			&formation.UnitID,
+			&formation.UnitName,
+			&formation.PositionID,
+			&formation.PositionName,
+			&formation.Quota,
+			&formation.Filled,
+			&formation.CreatedAt,
+			&formation.ModifiedAt,
+		); err != nil {
+			return nil, err
+		}
+		formation.Vacant = formation.Quota - formation.Filled
+		if formation.Vacant < 0 {
+			formation.Vacant = 0
+		}
+		formations = append(formations, formation)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return formations, nil
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/achmadnr21/emploman/internal/domain"
+)
+
+type LoginAttemptRepository struct {
+	db *sql.DB
+}
+
+func NewLoginAttemptRepository(db *sql.DB) *LoginAttemptRepository {
+	return &LoginAttemptRepository{
+		db: db,
+	}
+}
+
+// loginAttemptColumns juga menghitung selisih waktu di database, sama dengan now() yang dipakai saat menulis
+const loginAttemptColumns = `key, failed_count, last_failed_at, locked_until,
+	extract(epoch from now() - last_failed_at)::float8,
+	coalesce(greatest(extract(epoch from locked_until - now()), 0), 0)::float8`
+
+func (r *LoginAttemptRepository) FindByKeys(keys []string) ([]domain.LoginAttempt, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	placeholders := make([]string, len(keys))
+	args := make([]interface{}, len(keys))
+	for i, key := range keys {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+		args[i] = key
+	}
+	query := `SELECT ` + loginAttemptColumns + `
+	FROM achmadnr.login_attempts WHERE key IN (` + strings.Join(placeholders, ", ") + `)`
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var attempts []domain.LoginAttempt
+	for rows.Next() {
+		attempt, err := scanLoginAttempt(rows)
+		if err != nil {
+			return nil, err
+		}
+		attempts = append(attempts, *attempt)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return attempts, nil
+}
+
+func (r *LoginAttemptRepository) RecordFailure(key string, window time.Duration) (*domain.LoginAttempt, error) {
+	query := `INSERT INTO achmadnr.login_attempts AS login_attempts (key, failed_count, last_failed_at)
+	VALUES ($1, 1, now())
+	ON CONFLICT (key) DO UPDATE SET
+		failed_count = CASE WHEN login_attempts.last_failed_at < now() - make_interval(secs => $2)
+			THEN 1 ELSE login_attempts.failed_count + 1 END,
+		last_failed_at = now()
+	RETURNING ` + loginAttemptColumns
+	return scanLoginAttempt(r.db.QueryRow(query, key, window.Seconds()))
+}
+
+func (r *LoginAttemptRepository) Lock(key string, duration time.Duration) error {
+	query := `UPDATE achmadnr.login_attempts SET locked_until = now() + make_interval(secs => $2) WHERE key = $1`
+	_, err := r.db.Exec(query, key, duration.Seconds())
+	return err
+}
+
+func (r *LoginAttemptRepository) Reset(key string) error {
+	query := `DELETE FROM achmadnr.login_attempts WHERE key = $1`
+	_, err := r.db.Exec(query, key)
+	return err
+}
+
+func scanLoginAttempt(row interface{ Scan(...interface{}) error }) (*domain.LoginAttempt, error) {
+	var attempt domain.LoginAttempt
+	var since, remaining float64
+	if err := row.Scan(&attempt.Key, &attempt.FailedCount, &attempt.LastFailedAt, &attempt.LockedUntil,
+		&since, &remaining); err != nil {
+		return nil, err
+	}
+	attempt.SinceLastFailure = time.Duration(since * float64(time.Second))
+	attempt.LockRemaining = time.Duration(remaining * float64(time.Second))
+	return &attempt, nil
+}
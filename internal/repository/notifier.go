@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/achmadnr21/emploman/internal/domain"
+)
+
+// LogNotifier hanya mencetak pesan ke stdout, cukup untuk development lokal
+type LogNotifier struct{}
+
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+func (n *LogNotifier) Notify(employee *domain.Employee, subject string, message string) error {
+	fmt.Printf("[Notify] to %s (%s): %s\n%s\n", employee.NIP, employee.PhoneNumber, subject, message)
+	return nil
+}
+
+// FileNotifier menambahkan setiap pesan ke akhir file, seperti outbox sederhana
+type FileNotifier struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewFileNotifier(path string) *FileNotifier {
+	return &FileNotifier{
+		path: path,
+	}
+}
+
+func (n *FileNotifier) Notify(employee *domain.Employee, subject string, message string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	file, err := os.OpenFile(n.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open notifier file: %w", err)
+	}
+	defer file.Close()
+	_, err = fmt.Fprintf(file, "==== %s\nto: %s (%s)\nsubject: %s\n\n%s\n\n",
+		time.Now().Format(time.RFC3339), employee.NIP, employee.PhoneNumber, subject, message)
+	return err
+}
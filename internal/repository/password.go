@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/achmadnr21/emploman/internal/domain"
+)
+
+type PasswordRepository struct {
+	db *sql.DB
+}
+
+func NewPasswordRepository(db *sql.DB) *PasswordRepository {
+	return &PasswordRepository{
+		db: db,
+	}
+}
+
+// UpdatePassword tidak mencatat versi employee karena password bukan bagian dari data diri
+func (r *PasswordRepository) UpdatePassword(employeeID string, hash string, mustChange bool) error {
+	query := `UPDATE achmadnr.employees SET password = $2, must_change_password = $3, password_changed_at = now()
+	WHERE id = $1`
+	res, err := r.db.Exec(query, employeeID, hash, mustChange)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *PasswordRepository) MustChangePassword(employeeID string) (bool, error) {
+	query := `SELECT must_change_password FROM achmadnr.employees WHERE id = $1`
+	var mustChange bool
+	err := r.db.QueryRow(query, employeeID).Scan(&mustChange)
+	return mustChange, err
+}
+
+func (r *PasswordRepository) SaveResetToken(token *domain.PasswordResetToken, ttl time.Duration) (*domain.PasswordResetToken, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			tx.Commit()
+		}
+	}()
+
+	// 1. Token lama yang belum terpakai tidak berlaku lagi, hanya token terbaru yang bisa dipakai
+	_, err = tx.Exec(`
+		UPDATE achmadnr.password_reset_tokens SET used_at = now()
+		WHERE employee_id = $1 AND used_at IS NULL
+	`, token.EmployeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Simpan token baru
+	err = tx.QueryRow(`
+		INSERT INTO achmadnr.password_reset_tokens (employee_id, token_hash, expires_at)
+		VALUES ($1, $2, now() + make_interval(secs => $3)) RETURNING id, expires_at, created_at
+	`, token.EmployeeID, token.TokenHash, ttl.Seconds()).Scan(&token.ID, &token.ExpiresAt, &token.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+func (r *PasswordRepository) FindResetToken(tokenHash string) (*domain.PasswordResetToken, error) {
+	query := `SELECT id, employee_id, token_hash, expires_at, used_at, created_at
+	FROM achmadnr.password_reset_tokens WHERE token_hash = $1`
+	var token domain.PasswordResetToken
+	err := r.db.QueryRow(query, tokenHash).Scan(&token.ID, &token.EmployeeID, &token.TokenHash,
+		&token.ExpiresAt, &token.UsedAt, &token.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *PasswordRepository) ResetPassword(tokenID int, hash string) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			tx.Commit()
+		}
+	}()
+
+	// 1. Tandai token terpakai, hanya jika belum terpakai dan belum expired
+	var employeeID string
+	err = tx.QueryRow(`
+		UPDATE achmadnr.password_reset_tokens SET used_at = now()
+		WHERE id = $1 AND used_at IS NULL AND expires_at > now()
+		RETURNING employee_id
+	`, tokenID).Scan(&employeeID)
+	if err == sql.ErrNoRows {
+		// token sudah dipakai request lain, tidak ada yang perlu di-commit
+		err = nil
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	// 2. Ganti password, reset lewat token juga memenuhi kewajiban ganti password
+	_, err = tx.Exec(`
+		UPDATE achmadnr.employees SET password = $2, must_change_password = false, password_changed_at = now()
+		WHERE id = $1
+	`, employeeID, hash)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
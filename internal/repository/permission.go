@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/achmadnr21/emploman/internal/domain"
+)
+
+type PermissionRepository struct {
+	db *sql.DB
+}
+
+func NewPermissionRepository(db *sql.DB) *PermissionRepository {
+	return &PermissionRepository{
+		db: db,
+	}
+}
+
+func (r *PermissionRepository) FindAll() ([]domain.Permission, error) {
+	query := `SELECT id, description, unit_scoped FROM achmadnr.permissions ORDER BY id`
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	permissions := []domain.Permission{}
+	for rows.Next() {
+		var permission domain.Permission
+		if err := rows.Scan(&permission.ID, &permission.Description, &permission.UnitScoped); err != nil {
+			return nil, err
+		}
+		permissions = append(permissions, permission)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+// SQLSTATE PostgreSQL yang diterjemahkan menjadi error domain
+const (
+	pgForeignKeyViolation = "23503"
+	pgUniqueViolation     = "23505"
+	pgExclusionViolation  = "23P01"
+)
+
+// isPgError memeriksa kode SQLSTATE dari error driver lib/pq
+func isPgError(err error, code string) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && string(pqErr.Code) == code
+}
@@ -18,7 +18,7 @@ func NewPositionRepository(db *sql.DB) *PositionRepository {
 }
 
 func (r *PositionRepository) FindAll() ([]domain.Position, error) {
-	query := `SELECT id, name, created_at, modified_at FROM achmadnr.positions`
+	query := `SELECT id, name, is_head, created_at, modified_at FROM achmadnr.positions`
 	rows, err := r.db.Query(query)
 	if err != nil {
 		return nil, err
@@ -31,6 +31,7 @@ func (r *PositionRepository) FindAll() ([]domain.Position, error) {
 		if err := rows.Scan(
 			&position.ID,
 			&position.Name,
+			&position.IsHead,
 			&position.CreatedAt,
 			&position.ModifiedAt,
 		); err != nil {
@@ -41,11 +42,12 @@ func (r *PositionRepository) FindAll() ([]domain.Position, error) {
 	return positions, nil
 }
 func (r *PositionRepository) FindByID(id int) (*domain.Position, error) {
-	query := `SELECT id, name, created_at, modified_at FROM achmadnr.positions WHERE id = $1`
+	query := `SELECT id, name, is_head, created_at, modified_at FROM achmadnr.positions WHERE id = $1`
 	position := &domain.Position{}
 	err := r.db.QueryRow(query, id).Scan(
 		&position.ID,
 		&position.Name,
+		&position.IsHead,
 		&position.CreatedAt,
 		&position.ModifiedAt,
 	)
@@ -55,16 +57,16 @@ func (r *PositionRepository) FindByID(id int) (*domain.Position, error) {
 	return position, nil
 }
 func (r *PositionRepository) Save(position *domain.Position) (*domain.Position, error) {
-	query := `INSERT INTO achmadnr.positions (name) VALUES ($1) RETURNING id`
-	err := r.db.QueryRow(query, position.Name).Scan(&position.ID)
+	query := `INSERT INTO achmadnr.positions (name, is_head) VALUES ($1, $2) RETURNING id`
+	err := r.db.QueryRow(query, position.Name, position.IsHead).Scan(&position.ID)
 	if err != nil {
 		return nil, err
 	}
 	return position, nil
 }
 func (r *PositionRepository) Update(position *domain.Position) (*domain.Position, error) {
-	query := `UPDATE achmadnr.positions SET name = $1, modified_at = now() WHERE id = $2`
-	_, err := r.db.Exec(query, position.Name, position.ID)
+	query := `UPDATE achmadnr.positions SET name = $1, is_head = $2, modified_at = now() WHERE id = $3`
+	_, err := r.db.Exec(query, position.Name, position.IsHead, position.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -86,7 +88,7 @@ func (r *PositionRepository) Delete(id int) error {
 	return nil
 }
 func (r *PositionRepository) FindByName(name string) ([]domain.Position, error) {
-	query := `SELECT id, name, created_at, modified_at FROM achmadnr.positions WHERE name ILIKE $1`
+	query := `SELECT id, name, is_head, created_at, modified_at FROM achmadnr.positions WHERE name ILIKE $1`
 	rows, err := r.db.Query(query, "%"+name+"%")
 	if err != nil {
 		return nil, err
@@ -98,6 +100,7 @@ func (r *PositionRepository) FindByName(name string) ([]domain.Position, error)
 		if err := rows.Scan(
 			&position.ID,
 			&position.Name,
+			&position.IsHead,
 			&position.CreatedAt,
 			&position.ModifiedAt,
 		); err != nil {
@@ -111,7 +114,7 @@ func (r *PositionRepository) FindByName(name string) ([]domain.Position, error)
 
 func (r *PositionRepository) Search(query string) ([]domain.Position, error) {
 	query = fmt.Sprintf("%%%s%%", query)
-	sqlQuery := `SELECT id, name, created_at, modified_at FROM achmadnr.positions WHERE name ILIKE $1`
+	sqlQuery := `SELECT id, name, is_head, created_at, modified_at FROM achmadnr.positions WHERE name ILIKE $1`
 	rows, err := r.db.Query(sqlQuery, query)
 	if err != nil {
 		return nil, err
@@ -124,6 +127,7 @@ func (r *PositionRepository) Search(query string) ([]domain.Position, error) {
 		if err := rows.Scan(
 			&position.ID,
 			&position.Name,
+			&position.IsHead,
 			&position.CreatedAt,
 			&position.ModifiedAt,
 		); err != nil {
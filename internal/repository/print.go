@@ -16,8 +16,8 @@ func NewPrintRepository(db *sql.DB) *PrintRepository {
 	}
 }
 
-func (r *PrintRepository) PrintAll() ([]domain.PrintEmployee, error) {
-	query := `select 
+// printEmployeeQuery dipakai bersama oleh semua query print, hanya jabatan definitif yang aktif yang di-join
+var printEmployeeQuery = `select
 		ae.nip, ae.full_name, ae.place_of_birth, ae.address, ae.date_of_birth, ae.gender, ag.code, aec.code,
 		COALESCE(ap.name, '-') as position_name,
 		coalesce(au.address, '-') as tempat_kerja,
@@ -25,55 +25,31 @@ func (r *PrintRepository) PrintAll() ([]domain.PrintEmployee, error) {
 		COALESCE(au.name, '-') AS unit_name,
 		ae.phone_number,  ae.photo_url, COALESCE(ae.npwp, '-') as npwp
 		from achmadnr.employees ae
-		left join achmadnr.employee_assignments aea on ae.id = aea.employee_id
+		left join achmadnr.employee_assignments aea on ae.id = aea.employee_id and aea.assignment_type = 'primary'
+			and ` + activeAssignment("aea") + `
 		left join achmadnr.units au on aea.unit_id = au.id
 		left join achmadnr.positions ap on aea.position_id = ap.id
 		left join achmadnr.grades ag on ae.grade_id = ag.id
 		left join achmadnr.echelons aec on ae.echelon_id = aec.id
-		left join achmadnr.religions ar on ae.religion_id = ar.id;
+		left join achmadnr.religions ar on ae.religion_id = ar.id
 		`
-	rows, err := r.db.Query(query)
+
+func (r *PrintRepository) PrintAll() ([]domain.PrintEmployee, error) {
+	var employees []domain.PrintEmployee
+	err := r.stream(func(employee *domain.PrintEmployee) error {
+		employees = append(employees, *employee)
+		return nil
+	}, printEmployeeQuery)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-	var employees []domain.PrintEmployee
-	for rows.Next() {
-		var employee domain.PrintEmployee
-		err := rows.Scan(&employee.NIP, &employee.FullName, &employee.PlaceOfBirth, &employee.Address,
-			&employee.DateOfBirth, &employee.Gender, &employee.Grade, &employee.Echelon,
-			&employee.Jabatan, &employee.TempatTugas, &employee.Religion, &employee.Unit,
-			&employee.PhoneNumber, &employee.PhotoURL, &employee.NPWP)
-		if err != nil {
-			return nil, err
-		}
-		employees = append(employees, employee)
-	}
 	return employees, nil
 }
 func (r *PrintRepository) PrintByNIP(id string) (*domain.PrintEmployee, error) {
-	query := `select
-		ae.nip, ae.full_name, ae.place_of_birth, ae.address, ae.date_of_birth,
-		gender, ag.code, aec.code,
-		COALESCE(ap.name, '-') as position_name,
-		coalesce(au.address, '-') as tempat_kerja,
-		ar.name,
-		COALESCE(au.name, '-') AS unit_name,
-		ae.phone_number,  ae.photo_url, COALESCE(ae.npwp, '-') as npwp
-		from achmadnr.employees ae
-		left join achmadnr.employee_assignments aea on ae.id = aea.employee_id
-		left join achmadnr.units au on aea.unit_id = au.id
-		left join achmadnr.positions ap on aea.position_id = ap.id
-		left join achmadnr.grades ag on ae.grade_id = ag.id
-		left join achmadnr.echelons aec on ae.echelon_id = aec.id
-		left join achmadnr.religions ar on ae.religion_id = ar.id
-		where ae.nip = $1`
+	query := printEmployeeQuery + `where ae.nip = $1`
 	row := r.db.QueryRow(query, id)
 	var employee *domain.PrintEmployee = &domain.PrintEmployee{}
-	err := row.Scan(&employee.NIP, &employee.FullName, &employee.PlaceOfBirth, &employee.Address,
-		&employee.DateOfBirth, &employee.Gender, &employee.Grade, &employee.Echelon,
-		&employee.Jabatan, &employee.TempatTugas, &employee.Religion, &employee.Unit,
-		&employee.PhoneNumber, &employee.PhotoURL, &employee.NPWP)
+	err := scanPrintEmployee(row, employee)
 	if err != nil {
 		// if err == sql.ErrNoRows {
 		// 	return nil, nil // Not found
@@ -83,37 +59,52 @@ func (r *PrintRepository) PrintByNIP(id string) (*domain.PrintEmployee, error) {
 	return employee, nil
 }
 func (r *PrintRepository) PrintByUnit(unitID int) ([]domain.PrintEmployee, error) {
-	query := `select
-		ae.nip, ae.full_name, ae.place_of_birth, ae.address, ae.date_of_birth, ae.gender, ag.code, aec.code,
-		COALESCE(ap.name, '-') as position_name,
-		coalesce(au.address, '-') as tempat_kerja,
-		ar.name,
-		COALESCE(au.name, '-') AS unit_name,
-		ae.phone_number,  ae.photo_url, COALESCE(ae.npwp, '-') as npwp
-		from achmadnr.employees ae
-		left join achmadnr.employee_assignments aea on ae.id = aea.employee_id
-		left join achmadnr.units au on aea.unit_id = au.id
-		left join achmadnr.positions ap on aea.position_id = ap.id
-		left join achmadnr.grades ag on ae.grade_id = ag.id
-		left join achmadnr.echelons aec on ae.echelon_id = aec.id
-		left join achmadnr.religions ar on ae.religion_id = ar.id
-		where aea.unit_id = $1`
-	rows, err := r.db.Query(query, unitID)
+	query := printEmployeeQuery + `where aea.unit_id = $1`
+	var employees []domain.PrintEmployee
+	err := r.stream(func(employee *domain.PrintEmployee) error {
+		employees = append(employees, *employee)
+		return nil
+	}, query, unitID)
 	if err != nil {
 		return nil, err
 	}
+	return employees, nil
+}
+
+// StreamAll memanggil fn untuk setiap employee aktif tanpa menampung seluruh hasil di memory, dipakai untuk export
+func (r *PrintRepository) StreamAll(fn func(*domain.PrintEmployee) error) error {
+	query := printEmployeeQuery + `where ae.employment_status in ('active', 'on_leave')
+		order by unit_name, ae.full_name`
+	return r.stream(fn, query)
+}
+
+func (r *PrintRepository) StreamByUnit(unitID int, fn func(*domain.PrintEmployee) error) error {
+	query := printEmployeeQuery + `where aea.unit_id = $1 and ae.employment_status in ('active', 'on_leave')
+		order by ae.full_name`
+	return r.stream(fn, query, unitID)
+}
+
+func (r *PrintRepository) stream(fn func(*domain.PrintEmployee) error, query string, args ...interface{}) error {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return err
+	}
 	defer rows.Close()
-	var employees []domain.PrintEmployee
 	for rows.Next() {
 		var employee domain.PrintEmployee
-		err := rows.Scan(&employee.NIP, &employee.FullName, &employee.PlaceOfBirth, &employee.Address,
-			&employee.DateOfBirth, &employee.Gender, &employee.Grade, &employee.Echelon,
-			&employee.Jabatan, &employee.TempatTugas, &employee.Religion, &employee.Unit,
-			&employee.PhoneNumber, &employee.PhotoURL, &employee.NPWP)
-		if err != nil {
-			return nil, err
+		if err := scanPrintEmployee(rows, &employee); err != nil {
+			return err
+		}
+		if err := fn(&employee); err != nil {
+			return err
 		}
-		employees = append(employees, employee)
 	}
-	return employees, nil
+	return rows.Err()
+}
+
+func scanPrintEmployee(row interface{ Scan(...interface{}) error }, employee *domain.PrintEmployee) error {
+	return row.Scan(&employee.NIP, &employee.FullName, &employee.PlaceOfBirth, &employee.Address,
+		&employee.DateOfBirth, &employee.Gender, &employee.Grade, &employee.Echelon,
+		&employee.Jabatan, &employee.TempatTugas, &employee.Religion, &employee.Unit,
+		&employee.PhoneNumber, &employee.PhotoURL, &employee.NPWP)
 }
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/achmadnr21/emploman/internal/domain"
+)
+
+type RefreshTokenRepository struct {
+	db *sql.DB
+}
+
+func NewRefreshTokenRepository(db *sql.DB) *RefreshTokenRepository {
+	return &RefreshTokenRepository{
+		db: db,
+	}
+}
+
+func (r *RefreshTokenRepository) Save(token *domain.RefreshToken) (*domain.RefreshToken, error) {
+	query := `INSERT INTO achmadnr.refresh_tokens (id, family_id, employee_id, token_hash, two_factor_verified, expires_at)
+	VALUES ($1, $2, $3, $4, $5, $6) RETURNING created_at`
+	err := r.db.QueryRow(query, token.ID, token.FamilyID, token.EmployeeID, token.TokenHash, token.TwoFactorVerified, token.ExpiresAt).Scan(&token.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+func (r *RefreshTokenRepository) FindByID(id string) (*domain.RefreshToken, error) {
+	query := `SELECT id, family_id, employee_id, token_hash, two_factor_verified, expires_at, revoked_at, replaced_by, created_at
+	FROM achmadnr.refresh_tokens WHERE id = $1`
+	var token domain.RefreshToken
+	err := r.db.QueryRow(query, id).Scan(&token.ID, &token.FamilyID, &token.EmployeeID, &token.TokenHash,
+		&token.TwoFactorVerified, &token.ExpiresAt, &token.RevokedAt, &token.ReplacedBy, &token.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *RefreshTokenRepository) Rotate(oldID string, newToken *domain.RefreshToken) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			tx.Commit()
+		}
+	}()
+
+	// 1. Tandai token lama sebagai terpakai, hanya jika masih aktif
+	res, err := tx.Exec(`
+		UPDATE achmadnr.refresh_tokens
+		SET revoked_at = NOW(), replaced_by = $2
+		WHERE id = $1 AND revoked_at IS NULL
+	`, oldID, newToken.ID)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if rowsAffected == 0 {
+		// token sudah dipakai atau dicabut, tidak ada yang perlu di-commit
+		return false, nil
+	}
+
+	// 2. Simpan token baru dalam family yang sama
+	err = tx.QueryRow(`
+		INSERT INTO achmadnr.refresh_tokens (id, family_id, employee_id, token_hash, two_factor_verified, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING created_at
+	`, newToken.ID, newToken.FamilyID, newToken.EmployeeID, newToken.TokenHash, newToken.TwoFactorVerified, newToken.ExpiresAt).Scan(&newToken.CreatedAt)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *RefreshTokenRepository) RevokeFamily(familyID string) error {
+	query := `UPDATE achmadnr.refresh_tokens SET revoked_at = NOW() WHERE family_id = $1 AND revoked_at IS NULL`
+	_, err := r.db.Exec(query, familyID)
+	return err
+}
+
+func (r *RefreshTokenRepository) RevokeAllByEmployeeID(employeeID string) error {
+	query := `UPDATE achmadnr.refresh_tokens SET revoked_at = NOW() WHERE employee_id = $1 AND revoked_at IS NULL`
+	_, err := r.db.Exec(query, employeeID)
+	return err
+}
@@ -29,9 +29,7 @@ func NewRoleRepository(db *sql.DB) *RoleRepository {
 	}
 */
 func (r *RoleRepository) FindByUserID(id string) (*domain.Role, error) {
-	query := `SELECT r.id, r.name, r.level, r.description, r.can_add_role, r.can_add_employee, r.can_add_unit,
-	r.can_add_position, r.can_add_echelon, r.can_add_religion, r.can_add_grade, r.can_assign_employee_internal,
-	r.can_assign_employee_global, r.created_at, r.modified_at
+	query := `SELECT r.id, r.name, r.level, r.description, r.require_2fa, r.created_at, r.modified_at
 	FROM achmadnr.roles r
 	JOIN achmadnr.employees u ON r.id = u.role_id
 	WHERE u.id = $1`
@@ -42,28 +40,21 @@ func (r *RoleRepository) FindByUserID(id string) (*domain.Role, error) {
 		&role.Name,
 		&role.Level,
 		&role.Description,
-		&role.CanAddRole,
-		&role.CanAddEmployee,
-		&role.CanAddUnit,
-		&role.CanAddPosition,
-		&role.CanAddEchelon,
-		&role.CanAddReligion,
-		&role.CanAddGrade,
-		&role.CanAssignEmployeeInternal,
-		&role.CanAssignEmployeeGlobal,
+		&role.Require2FA,
 		&role.CreatedAt,
 		&role.ModifiedAt)
 	if err != nil {
 		fmt.Println("Error scanning role:", err)
 		return nil, err
 	}
+	if err := r.findPermissions(&role); err != nil {
+		return nil, err
+	}
 	return &role, nil
 }
 func (r *RoleRepository) FindAll() ([]domain.Role, error) {
 	query := `SELECT 
-	id, name, level, description, can_add_role, can_add_employee, can_add_unit, can_add_position, 
-	can_add_echelon, can_add_religion, can_add_grade, can_assign_employee_internal, can_assign_employee_global, 
-	created_at, modified_at 
+	id, name, level, description, require_2fa, created_at, modified_at 
 	FROM achmadnr.roles`
 	rows, err := r.db.Query(query)
 	if err != nil {
@@ -73,7 +64,7 @@ func (r *RoleRepository) FindAll() ([]domain.Role, error) {
 	var roles []domain.Role
 	for rows.Next() {
 		var role domain.Role
-		if err := rows.Scan(&role.ID, &role.Name, &role.Level, &role.Description, &role.CanAddRole, &role.CanAddEmployee, &role.CanAddUnit, &role.CanAddPosition, &role.CanAddEchelon, &role.CanAddReligion, &role.CanAddGrade, &role.CanAssignEmployeeInternal, &role.CanAssignEmployeeGlobal, &role.CreatedAt, &role.ModifiedAt); err != nil {
+		if err := rows.Scan(&role.ID, &role.Name, &role.Level, &role.Description, &role.Require2FA, &role.CreatedAt, &role.ModifiedAt); err != nil {
 			return nil, err
 		}
 		roles = append(roles, role)
@@ -81,73 +72,101 @@ func (r *RoleRepository) FindAll() ([]domain.Role, error) {
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
+	// permission seluruh role diambil dengan satu query
+	permissions, err := r.findAllPermissions()
+	if err != nil {
+		return nil, err
+	}
+	for i := range roles {
+		roles[i].Permissions = permissions[roles[i].ID]
+		if roles[i].Permissions == nil {
+			roles[i].Permissions = []domain.RolePermission{}
+		}
+	}
 	return roles, nil
 }
 func (r *RoleRepository) FindByID(id string) (*domain.Role, error) {
-	query := `SELECT id, name, level, description, can_add_role, can_add_employee, can_add_unit, 
-	can_add_position, can_add_echelon, can_add_religion, can_add_grade, can_assign_employee_internal, 
-	can_assign_employee_global, created_at, modified_at FROM achmadnr.roles WHERE id = $1`
+	query := `SELECT id, name, level, description, require_2fa, created_at, modified_at FROM achmadnr.roles WHERE id = $1`
 	row := r.db.QueryRow(query, id)
 	var role domain.Role
-	if err := row.Scan(&role.ID, &role.Name, &role.Level, &role.Description, &role.CanAddRole, &role.CanAddEmployee, &role.CanAddUnit, &role.CanAddPosition, &role.CanAddEchelon, &role.CanAddReligion, &role.CanAddGrade, &role.CanAssignEmployeeInternal, &role.CanAssignEmployeeGlobal, &role.CreatedAt, &role.ModifiedAt); err != nil {
+	if err := row.Scan(&role.ID, &role.Name, &role.Level, &role.Description, &role.Require2FA, &role.CreatedAt, &role.ModifiedAt); err != nil {
 		// if err == sql.ErrNoRows {
 		// 	return nil, nil
 		// }
 		return nil, err
 	}
+	if err := r.findPermissions(&role); err != nil {
+		return nil, err
+	}
 	return &role, nil
 }
+
+// Save menyimpan role beserta permission-nya dalam satu transaksi
 func (r *RoleRepository) Save(role *domain.Role) (*domain.Role, error) {
-	query := `INSERT INTO achmadnr.roles (id, name, level, description, can_add_role, can_add_employee, can_add_unit,
-	can_add_position, can_add_echelon, can_add_religion, can_add_grade, can_assign_employee_internal,
-	can_assign_employee_global)
-	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
-	_, err := r.db.Exec(query,
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			tx.Commit()
+		}
+	}()
+
+	query := `INSERT INTO achmadnr.roles (id, name, level, description, require_2fa)
+	VALUES ($1, $2, $3, $4, $5)`
+	_, err = tx.Exec(query,
 		role.ID,
 		role.Name,
 		role.Level,
 		role.Description,
-		role.CanAddRole,
-		role.CanAddEmployee,
-		role.CanAddUnit,
-		role.CanAddPosition,
-		role.CanAddEchelon,
-		role.CanAddReligion,
-		role.CanAddGrade,
-		role.CanAssignEmployeeInternal,
-		role.CanAssignEmployeeGlobal)
+		role.Require2FA)
 	if err != nil {
 		return nil, err
 	}
+	if err = savePermissions(tx, role); err != nil {
+		return nil, err
+	}
 	return role, nil
 }
+
+// Update mengganti seluruh permission role dengan role.Permissions
 func (r *RoleRepository) Update(role *domain.Role) (*domain.Role, error) {
-	query := `UPDATE achmadnr.roles SET name = $1, level = $2, description = $3, can_add_role = $4, can_add_employee = $5,
-	can_add_unit = $6, can_add_position = $7, can_add_echelon = $8, can_add_religion = $9,
-	can_add_grade = $10, can_assign_employee_internal = $11, can_assign_employee_global = $12,
-	modified_at = now() WHERE id = $13`
-	_, err := r.db.Exec(query,
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			tx.Commit()
+		}
+	}()
+
+	query := `UPDATE achmadnr.roles SET name = $1, level = $2, description = $3, require_2fa = $4, modified_at = now() WHERE id = $5`
+	_, err = tx.Exec(query,
 		role.Name,
 		role.Level,
 		role.Description,
-		role.CanAddRole,
-		role.CanAddEmployee,
-		role.CanAddUnit,
-		role.CanAddPosition,
-		role.CanAddEchelon,
-		role.CanAddReligion,
-		role.CanAddGrade,
-		role.CanAssignEmployeeInternal,
-		role.CanAssignEmployeeGlobal,
+		role.Require2FA,
 		role.ID)
 	if err != nil {
 		return nil, err
 	}
+	if _, err = tx.Exec(`DELETE FROM achmadnr.role_permissions WHERE role_id = $1`, role.ID); err != nil {
+		return nil, err
+	}
+	if err = savePermissions(tx, role); err != nil {
+		return nil, err
+	}
 	return role, nil
 }
 
 func (r *RoleRepository) Delete(id string) error {
-	query := `DELETE FROM roles WHERE id = $1`
+	query := `DELETE FROM achmadnr.roles WHERE id = $1`
 	res, err := r.db.Exec(query, id)
 	if err != nil {
 		return err
@@ -162,9 +181,7 @@ func (r *RoleRepository) Delete(id string) error {
 	return nil
 }
 func (r *RoleRepository) FindByName(name string) (*domain.Role, error) {
-	query := `SELECT id, name, level, description, can_add_role, can_add_employee, can_add_unit, 
-	can_add_position, can_add_echelon, can_add_religion, can_add_grade, can_assign_employee_internal, 
-	can_assign_employee_global, created_at, modified_at FROM achmadnr.roles WHERE name ILIKE $1`
+	query := `SELECT id, name, level, description, require_2fa, created_at, modified_at FROM achmadnr.roles WHERE name ILIKE $1`
 	row := r.db.QueryRow(query, "%"+name+"%")
 	var role domain.Role
 	err := row.Scan(
@@ -172,15 +189,7 @@ func (r *RoleRepository) FindByName(name string) (*domain.Role, error) {
 		&role.Name,
 		&role.Level,
 		&role.Description,
-		&role.CanAddRole,
-		&role.CanAddEmployee,
-		&role.CanAddUnit,
-		&role.CanAddPosition,
-		&role.CanAddEchelon,
-		&role.CanAddReligion,
-		&role.CanAddGrade,
-		&role.CanAssignEmployeeInternal,
-		&role.CanAssignEmployeeGlobal,
+		&role.Require2FA,
 		&role.CreatedAt,
 		&role.ModifiedAt)
 	if err != nil {
@@ -189,9 +198,22 @@ func (r *RoleRepository) FindByName(name string) (*domain.Role, error) {
 		// }
 		return nil, err
 	}
+	if err := r.findPermissions(&role); err != nil {
+		return nil, err
+	}
 	return &role, nil
 }
 
+// CountEmployees menghitung jumlah employee yang masih memakai role tersebut
+func (r *RoleRepository) CountEmployees(id string) (int, error) {
+	query := `SELECT COUNT(*) FROM achmadnr.employees WHERE role_id = $1`
+	var count int
+	if err := r.db.QueryRow(query, id).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
 func (r *RoleRepository) FindPromoteRole(promoterRoleID string) ([]domain.RolePromotion, error) {
 	query := `SELECT promoter_role_id, from_role_id, to_role_id
 FROM achmadnr.role_promotions
@@ -215,3 +237,109 @@ WHERE promoter_role_id = $1`
 	return rolePromotions, nil
 
 }
+
+func (r *RoleRepository) FindAllPromotion() ([]domain.RolePromotion, error) {
+	query := `SELECT promoter_role_id, from_role_id, to_role_id
+FROM achmadnr.role_promotions
+ORDER BY promoter_role_id, from_role_id, to_role_id`
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var rolePromotions []domain.RolePromotion
+	for rows.Next() {
+		var rolePromotion domain.RolePromotion
+		if err := rows.Scan(&rolePromotion.PromoterRoleID, &rolePromotion.FromRoleID, &rolePromotion.ToRoleID); err != nil {
+			return nil, err
+		}
+		rolePromotions = append(rolePromotions, rolePromotion)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return rolePromotions, nil
+}
+
+func (r *RoleRepository) SavePromotion(rolePromotion *domain.RolePromotion) (*domain.RolePromotion, error) {
+	query := `INSERT INTO achmadnr.role_promotions (promoter_role_id, from_role_id, to_role_id) VALUES ($1, $2, $3)`
+	_, err := r.db.Exec(query, rolePromotion.PromoterRoleID, rolePromotion.FromRoleID, rolePromotion.ToRoleID)
+	if isPgError(err, pgUniqueViolation) {
+		return nil, domain.ErrRolePromotionExists
+	}
+	if err != nil {
+		return nil, err
+	}
+	return rolePromotion, nil
+}
+
+func (r *RoleRepository) DeletePromotion(rolePromotion *domain.RolePromotion) error {
+	query := `DELETE FROM achmadnr.role_promotions WHERE promoter_role_id = $1 AND from_role_id = $2 AND to_role_id = $3`
+	res, err := r.db.Exec(query, rolePromotion.PromoterRoleID, rolePromotion.FromRoleID, rolePromotion.ToRoleID)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("no rows deleted")
+	}
+	return nil
+}
+
+// EmployeeRoleChanged tidak melakukan apa-apa karena RoleRepository selalu membaca role employee dari database
+func (r *RoleRepository) EmployeeRoleChanged(employeeID string) {}
+
+// findPermissions mengisi role.Permissions dari achmadnr.role_permissions
+func (r *RoleRepository) findPermissions(role *domain.Role) error {
+	query := `SELECT permission_id, scope FROM achmadnr.role_permissions WHERE role_id = $1 ORDER BY permission_id`
+	rows, err := r.db.Query(query, role.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	role.Permissions = []domain.RolePermission{}
+	for rows.Next() {
+		var permission domain.RolePermission
+		if err := rows.Scan(&permission.PermissionID, &permission.Scope); err != nil {
+			return err
+		}
+		role.Permissions = append(role.Permissions, permission)
+	}
+	return rows.Err()
+}
+
+// findAllPermissions mengembalikan permission seluruh role dikelompokkan per role id
+func (r *RoleRepository) findAllPermissions() (map[string][]domain.RolePermission, error) {
+	query := `SELECT role_id, permission_id, scope FROM achmadnr.role_permissions ORDER BY role_id, permission_id`
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	permissions := map[string][]domain.RolePermission{}
+	for rows.Next() {
+		var roleID string
+		var permission domain.RolePermission
+		if err := rows.Scan(&roleID, &permission.PermissionID, &permission.Scope); err != nil {
+			return nil, err
+		}
+		permissions[roleID] = append(permissions[roleID], permission)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
+
+func savePermissions(tx *sql.Tx, role *domain.Role) error {
+	query := `INSERT INTO achmadnr.role_permissions (role_id, permission_id, scope) VALUES ($1, $2, $3)`
+	for _, permission := range role.Permissions {
+		if _, err := tx.Exec(query, role.ID, permission.PermissionID, permission.Scope); err != nil {
+			return err
+		}
+	}
+	return nil
+}
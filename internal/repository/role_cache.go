@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"sync"
+	"time"
+
+	"github.com/achmadnr21/emploman/internal/domain"
+)
+
+// CachedRoleRepository menyimpan hasil FindByID, FindByUserID dan FindPromoteRole di memori proses. Cache dikosongkan
+// setiap kali role atau promosi role diubah lewat repository ini, dan role milik employee dilupakan lewat
+// EmployeeRoleChanged, jadi satu instance harus dipakai bersama oleh semua usecase. ttl membatasi umur cache
+// jika role diubah dari instance lain.
+type CachedRoleRepository struct {
+	domain.RoleInterface
+	ttl        time.Duration
+	mu         sync.RWMutex
+	roles      map[string]cachedRole
+	promotions map[string]cachedPromotions
+	// users menyimpan role id employee, role-nya sendiri tetap diambil dari roles
+	users map[string]cachedUserRole
+	// generation bertambah setiap invalidate, hasil query yang dimulai sebelum invalidate tidak disimpan
+	generation uint64
+}
+
+type cachedRole struct {
+	role      domain.Role
+	expiresAt time.Time
+}
+
+type cachedUserRole struct {
+	roleID    string
+	expiresAt time.Time
+}
+
+type cachedPromotions struct {
+	promotions []domain.RolePromotion
+	expiresAt  time.Time
+}
+
+func NewCachedRoleRepository(repo domain.RoleInterface, ttl time.Duration) *CachedRoleRepository {
+	return &CachedRoleRepository{
+		RoleInterface: repo,
+		ttl:           ttl,
+		roles:         map[string]cachedRole{},
+		promotions:    map[string]cachedPromotions{},
+		users:         map[string]cachedUserRole{},
+	}
+}
+
+// FindByID mengembalikan salinan role agar caller tidak mengubah isi cache
+func (r *CachedRoleRepository) FindByID(id string) (*domain.Role, error) {
+	r.mu.RLock()
+	cached, ok := r.roles[id]
+	generation := r.generation
+	r.mu.RUnlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return copyRole(&cached.role), nil
+	}
+	role, err := r.RoleInterface.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	if generation == r.generation {
+		r.roles[id] = cachedRole{role: *copyRole(role), expiresAt: time.Now().Add(r.ttl)}
+	}
+	r.mu.Unlock()
+	return role, nil
+}
+
+// FindByUserID mengembalikan role employee saat ini. Hanya role id yang disimpan per employee,
+// sehingga perubahan permission role tetap ikut terbaca lewat FindByID.
+func (r *CachedRoleRepository) FindByUserID(id string) (*domain.Role, error) {
+	r.mu.RLock()
+	cached, ok := r.users[id]
+	generation := r.generation
+	r.mu.RUnlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return r.FindByID(cached.roleID)
+	}
+	role, err := r.RoleInterface.FindByUserID(id)
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	if generation == r.generation {
+		r.users[id] = cachedUserRole{roleID: role.ID, expiresAt: time.Now().Add(r.ttl)}
+	}
+	r.mu.Unlock()
+	return role, nil
+}
+
+func (r *CachedRoleRepository) FindPromoteRole(promoterRoleID string) ([]domain.RolePromotion, error) {
+	r.mu.RLock()
+	cached, ok := r.promotions[promoterRoleID]
+	generation := r.generation
+	r.mu.RUnlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return append([]domain.RolePromotion(nil), cached.promotions...), nil
+	}
+	promotions, err := r.RoleInterface.FindPromoteRole(promoterRoleID)
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	if generation == r.generation {
+		r.promotions[promoterRoleID] = cachedPromotions{
+			promotions: append([]domain.RolePromotion(nil), promotions...),
+			expiresAt:  time.Now().Add(r.ttl),
+		}
+	}
+	r.mu.Unlock()
+	return promotions, nil
+}
+
+func (r *CachedRoleRepository) Save(role *domain.Role) (*domain.Role, error) {
+	defer r.invalidate()
+	return r.RoleInterface.Save(role)
+}
+
+func (r *CachedRoleRepository) Update(role *domain.Role) (*domain.Role, error) {
+	defer r.invalidate()
+	return r.RoleInterface.Update(role)
+}
+
+// Delete juga menghapus promosi role lewat on delete cascade, jadi seluruh cache dikosongkan
+func (r *CachedRoleRepository) Delete(id string) error {
+	defer r.invalidate()
+	return r.RoleInterface.Delete(id)
+}
+
+func (r *CachedRoleRepository) SavePromotion(rolePromotion *domain.RolePromotion) (*domain.RolePromotion, error) {
+	defer r.invalidate()
+	return r.RoleInterface.SavePromotion(rolePromotion)
+}
+
+func (r *CachedRoleRepository) DeletePromotion(rolePromotion *domain.RolePromotion) error {
+	defer r.invalidate()
+	return r.RoleInterface.DeletePromotion(rolePromotion)
+}
+
+// EmployeeRoleChanged melupakan role employee yang baru dipromosikan atau diturunkan
+func (r *CachedRoleRepository) EmployeeRoleChanged(employeeID string) {
+	r.mu.Lock()
+	r.generation++
+	delete(r.users, employeeID)
+	r.mu.Unlock()
+	r.RoleInterface.EmployeeRoleChanged(employeeID)
+}
+
+// invalidate dijalankan setelah query selesai agar request lain tidak sempat mengisi cache dengan data lama
+func (r *CachedRoleRepository) invalidate() {
+	r.mu.Lock()
+	r.generation++
+	r.roles = map[string]cachedRole{}
+	r.promotions = map[string]cachedPromotions{}
+	r.users = map[string]cachedUserRole{}
+	r.mu.Unlock()
+}
+
+// copyRole menyalin role termasuk slice permission
+func copyRole(role *domain.Role) *domain.Role {
+	copied := *role
+	copied.Permissions = append([]domain.RolePermission{}, role.Permissions...)
+	return &copied
+}
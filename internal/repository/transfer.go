@@ -0,0 +1,230 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/achmadnr21/emploman/internal/domain"
+)
+
+type TransferRepository struct {
+	db *sql.DB
+}
+
+func NewTransferRepository(db *sql.DB) *TransferRepository {
+	return &TransferRepository{
+		db: db,
+	}
+}
+
+func (r *TransferRepository) Save(transfer *domain.TransferRequest, decision *domain.TransferDecision) (*domain.TransferRequest, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			tx.Commit()
+		}
+	}()
+
+	err = tx.QueryRow(`INSERT INTO achmadnr.transfer_requests
+		(employee_id, from_unit_id, to_unit_id, to_position_id, start_date, reason, status, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at, modified_at`,
+		transfer.EmployeeID, transfer.FromUnitID, transfer.ToUnitID, transfer.ToPositionID,
+		transfer.StartDate, transfer.Reason, transfer.Status, transfer.CreatedBy).Scan(&transfer.ID, &transfer.CreatedAt, &transfer.ModifiedAt)
+	// idx_transfer_requests_open hanya mengizinkan satu transfer berjalan per employee
+	if isPgError(err, pgUniqueViolation) {
+		err = domain.ErrTransferOpenExists
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	decision.TransferID = transfer.ID
+	if err = saveTransferDecision(tx, decision); err != nil {
+		return nil, err
+	}
+	return transfer, nil
+}
+
+func (r *TransferRepository) FindByID(id int) (*domain.TransferRequest, error) {
+	query := transferQuery + ` WHERE t.id = $1`
+	return scanTransfer(r.db.QueryRow(query, id))
+}
+
+func (r *TransferRepository) FindAll(status string) ([]domain.TransferRequest, error) {
+	query := transferQuery + ` WHERE $1 = '' OR t.status = $1 ORDER BY t.created_at DESC, t.id DESC`
+	rows, err := r.db.Query(query, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	transfers := []domain.TransferRequest{}
+	for rows.Next() {
+		transfer, err := scanTransfer(rows)
+		if err != nil {
+			return nil, err
+		}
+		transfers = append(transfers, *transfer)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return transfers, nil
+}
+
+func (r *TransferRepository) FindDecisions(transferID int) ([]domain.TransferDecision, error) {
+	query := `SELECT d.id, d.transfer_id, coalesce(d.from_status, ''), d.to_status, d.actor_id,
+	coalesce(e.full_name, ''), d.comment, d.created_at
+	FROM achmadnr.transfer_decisions d
+	LEFT JOIN achmadnr.employees e ON d.actor_id = e.id
+	WHERE d.transfer_id = $1
+	ORDER BY d.created_at, d.id`
+	rows, err := r.db.Query(query, transferID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	decisions := []domain.TransferDecision{}
+	for rows.Next() {
+		var decision domain.TransferDecision
+		if err := rows.Scan(&decision.ID, &decision.TransferID, &decision.FromStatus, &decision.ToStatus,
+			&decision.ActorID, &decision.ActorName, &decision.Comment, &decision.CreatedAt); err != nil {
+			return nil, err
+		}
+		decisions = append(decisions, decision)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return decisions, nil
+}
+
+func (r *TransferRepository) Transition(transfer *domain.TransferRequest, decision *domain.TransferDecision) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			tx.Commit()
+		}
+	}()
+
+	// status hanya diubah jika belum diubah oleh permintaan lain
+	err = tx.QueryRow(`UPDATE achmadnr.transfer_requests
+		SET status = $2, decree_number = NULLIF($3, ''), assignment_id = $4, modified_at = NOW()
+		WHERE id = $1 AND status = $5
+		RETURNING modified_at`,
+		transfer.ID, decision.ToStatus, transfer.DecreeNumber, transfer.AssignmentID,
+		decision.FromStatus).Scan(&transfer.ModifiedAt)
+	if err == sql.ErrNoRows {
+		err = domain.ErrTransferStatusChanged
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	decision.TransferID = transfer.ID
+	if err = saveTransferDecision(tx, decision); err != nil {
+		return err
+	}
+	transfer.Status = decision.ToStatus
+	return nil
+}
+
+func (r *TransferRepository) Execute(transfer *domain.TransferRequest, decision *domain.TransferDecision, assignment *domain.EmployeeAssignment) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			tx.Commit()
+		}
+	}()
+
+	// klaim transfer lebih dulu, permintaan eksekusi kedua menunggu lalu gagal di sini tanpa membuat assignment
+	err = tx.QueryRow(`UPDATE achmadnr.transfer_requests
+		SET status = $2, decree_number = NULLIF($3, ''), modified_at = NOW()
+		WHERE id = $1 AND status = $4
+		RETURNING modified_at`,
+		transfer.ID, decision.ToStatus, transfer.DecreeNumber, decision.FromStatus).Scan(&transfer.ModifiedAt)
+	if err == sql.ErrNoRows {
+		err = domain.ErrTransferStatusChanged
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	if err = insertAssignment(tx, assignment); err != nil {
+		return err
+	}
+	_, err = tx.Exec(`UPDATE achmadnr.transfer_requests SET assignment_id = $2 WHERE id = $1`, transfer.ID, assignment.ID)
+	if err != nil {
+		return err
+	}
+	decision.TransferID = transfer.ID
+	if err = saveTransferDecision(tx, decision); err != nil {
+		return err
+	}
+	transfer.AssignmentID = &assignment.ID
+	transfer.Status = decision.ToStatus
+	return nil
+}
+
+// ==================================================================== UTILITIES ====================================================================
+
+const transferQuery = `SELECT t.id, t.employee_id, e.full_name, t.from_unit_id, fu.name, t.to_unit_id, tu.name,
+	t.to_position_id, p.name, t.start_date, t.reason, coalesce(t.decree_number, ''), t.assignment_id, t.status,
+	t.created_by, t.created_at, t.modified_at
+	FROM achmadnr.transfer_requests t
+	INNER JOIN achmadnr.employees e ON t.employee_id = e.id
+	INNER JOIN achmadnr.units fu ON t.from_unit_id = fu.id
+	INNER JOIN achmadnr.units tu ON t.to_unit_id = tu.id
+	INNER JOIN achmadnr.positions p ON t.to_position_id = p.id`
+
+func scanTransfer(row interface{ Scan(...interface{}) error }) (*domain.TransferRequest, error) {
+	var transfer domain.TransferRequest
+	var assignmentID sql.NullInt64
+	if err := row.Scan(
+		&transfer.ID,
+		&transfer.EmployeeID,
+		&transfer.EmployeeName,
+		&transfer.FromUnitID,
+		&transfer.FromUnitName,
+		&transfer.ToUnitID,
+		&transfer.ToUnitName,
+		&transfer.ToPositionID,
+		&transfer.ToPositionName,
+		&transfer.StartDate,
+		&transfer.Reason,
+		&transfer.DecreeNumber,
+		&assignmentID,
+		&transfer.Status,
+		&transfer.CreatedBy,
+		&transfer.CreatedAt,
+		&transfer.ModifiedAt,
+	); err != nil {
+		return nil, err
+	}
+	if assignmentID.Valid {
+		id := int(assignmentID.Int64)
+		transfer.AssignmentID = &id
+	}
+	return &transfer, nil
+}
+
+func saveTransferDecision(tx *sql.Tx, decision *domain.TransferDecision) error {
+	return tx.QueryRow(`INSERT INTO achmadnr.transfer_decisions (transfer_id, from_status, to_status, actor_id, comment)
+		VALUES ($1, NULLIF($2, ''), $3, $4, $5) RETURNING id, created_at`,
+		decision.TransferID, decision.FromStatus, decision.ToStatus, decision.ActorID,
+		decision.Comment).Scan(&decision.ID, &decision.CreatedAt)
+}
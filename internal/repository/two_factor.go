@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/achmadnr21/emploman/internal/domain"
+)
+
+type TwoFactorRepository struct {
+	db *sql.DB
+}
+
+func NewTwoFactorRepository(db *sql.DB) *TwoFactorRepository {
+	return &TwoFactorRepository{
+		db: db,
+	}
+}
+
+func (r *TwoFactorRepository) FindByEmployeeID(employeeID string) (*domain.TwoFactor, error) {
+	query := `SELECT employee_id, secret, enabled, last_used_step, confirmed_at, created_at
+	FROM achmadnr.employee_two_factors WHERE employee_id = $1`
+	var tf domain.TwoFactor
+	err := r.db.QueryRow(query, employeeID).Scan(&tf.EmployeeID, &tf.Secret, &tf.Enabled,
+		&tf.LastUsedStep, &tf.ConfirmedAt, &tf.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &tf, nil
+}
+
+func (r *TwoFactorRepository) SavePending(employeeID string, secret string) error {
+	// enrolment ulang mengganti secret yang belum dikonfirmasi, 2FA aktif tidak ikut tertimpa
+	query := `INSERT INTO achmadnr.employee_two_factors (employee_id, secret)
+	VALUES ($1, $2)
+	ON CONFLICT (employee_id) DO UPDATE SET secret = excluded.secret, last_used_step = 0, created_at = now()
+	WHERE employee_two_factors.enabled = false`
+	res, err := r.db.Exec(query, employeeID, secret)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return domain.ErrTwoFactorEnabled
+	}
+	return nil
+}
+
+func (r *TwoFactorRepository) Enable(employeeID string, step int64, recoveryCodeHashes []string) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			tx.Commit()
+		}
+	}()
+
+	// 1. Aktifkan 2FA, hanya jika masih pending
+	res, err := tx.Exec(`
+		UPDATE achmadnr.employee_two_factors
+		SET enabled = true, last_used_step = $2, confirmed_at = now()
+		WHERE employee_id = $1 AND enabled = false AND last_used_step < $2
+	`, employeeID, step)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if rowsAffected == 0 {
+		return false, nil
+	}
+
+	// 2. Simpan recovery code baru
+	if err = replaceRecoveryCodes(tx, employeeID, recoveryCodeHashes); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *TwoFactorRepository) UseStep(employeeID string, step int64) (bool, error) {
+	query := `UPDATE achmadnr.employee_two_factors SET last_used_step = $2
+	WHERE employee_id = $1 AND enabled = true AND last_used_step < $2`
+	res, err := r.db.Exec(query, employeeID, step)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
+
+func (r *TwoFactorRepository) ReplaceRecoveryCodes(employeeID string, recoveryCodeHashes []string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			tx.Commit()
+		}
+	}()
+	err = replaceRecoveryCodes(tx, employeeID, recoveryCodeHashes)
+	return err
+}
+
+func (r *TwoFactorRepository) UseRecoveryCode(employeeID string, codeHash string) (bool, error) {
+	query := `UPDATE achmadnr.employee_recovery_codes SET used_at = now()
+	WHERE employee_id = $1 AND code_hash = $2 AND used_at IS NULL`
+	res, err := r.db.Exec(query, employeeID, codeHash)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
+
+func (r *TwoFactorRepository) CountRecoveryCodes(employeeID string) (int, error) {
+	query := `SELECT COUNT(*) FROM achmadnr.employee_recovery_codes WHERE employee_id = $1 AND used_at IS NULL`
+	var count int
+	err := r.db.QueryRow(query, employeeID).Scan(&count)
+	return count, err
+}
+
+func (r *TwoFactorRepository) Delete(employeeID string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			tx.Commit()
+		}
+	}()
+	if _, err = tx.Exec(`DELETE FROM achmadnr.employee_recovery_codes WHERE employee_id = $1`, employeeID); err != nil {
+		return err
+	}
+	_, err = tx.Exec(`DELETE FROM achmadnr.employee_two_factors WHERE employee_id = $1`, employeeID)
+	return err
+}
+
+// replaceRecoveryCodes menghapus seluruh recovery code lama lalu menyimpan yang baru
+func replaceRecoveryCodes(tx *sql.Tx, employeeID string, recoveryCodeHashes []string) error {
+	if _, err := tx.Exec(`DELETE FROM achmadnr.employee_recovery_codes WHERE employee_id = $1`, employeeID); err != nil {
+		return err
+	}
+	stmt, err := tx.Prepare(`INSERT INTO achmadnr.employee_recovery_codes (employee_id, code_hash) VALUES ($1, $2)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for _, hash := range recoveryCodeHashes {
+		if _, err := stmt.Exec(employeeID, hash); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -18,7 +18,7 @@ func NewUnitRepository(db *sql.DB) *UnitRepository {
 }
 
 func (r *UnitRepository) FindAll() ([]domain.Unit, error) {
-	query := `SELECT id, name, address, description, created_at, modified_at FROM achmadnr.units`
+	query := `SELECT id, parent_id, name, address, description, created_at, modified_at FROM achmadnr.units`
 	rows, err := r.db.Query(query)
 	if err != nil {
 		return nil, err
@@ -30,6 +30,7 @@ func (r *UnitRepository) FindAll() ([]domain.Unit, error) {
 		var unit domain.Unit
 		if err := rows.Scan(
 			&unit.ID,
+			&unit.ParentID,
 			&unit.Name,
 			&unit.Address,
 			&unit.Description,
@@ -43,10 +44,11 @@ func (r *UnitRepository) FindAll() ([]domain.Unit, error) {
 	return units, nil
 }
 func (r *UnitRepository) FindByID(id int) (*domain.Unit, error) {
-	query := `SELECT id, name, address, description, created_at, modified_at FROM achmadnr.units WHERE id = $1`
+	query := `SELECT id, parent_id, name, address, description, created_at, modified_at FROM achmadnr.units WHERE id = $1`
 	unit := &domain.Unit{}
 	err := r.db.QueryRow(query, id).Scan(
 		&unit.ID,
+		&unit.ParentID,
 		&unit.Name,
 		&unit.Address,
 		&unit.Description,
@@ -59,16 +61,33 @@ func (r *UnitRepository) FindByID(id int) (*domain.Unit, error) {
 	return unit, nil
 }
 func (r *UnitRepository) Save(unit *domain.Unit) (*domain.Unit, error) {
-	query := `INSERT INTO achmadnr.units (name, address, description) VALUES ($1, $2, $3) RETURNING id`
-	err := r.db.QueryRow(query, unit.Name, unit.Address, unit.Description).Scan(&unit.ID)
+	query := `INSERT INTO achmadnr.units (parent_id, name, address, description) VALUES ($1, $2, $3, $4) RETURNING id`
+	err := r.db.QueryRow(query, unit.ParentID, unit.Name, unit.Address, unit.Description).Scan(&unit.ID)
 	if err != nil {
 		return nil, err
 	}
 	return unit, nil
 }
 func (r *UnitRepository) Update(unit *domain.Unit) (*domain.Unit, error) {
-	query := `UPDATE achmadnr.units SET name = $1, address = $2, description = $3, modified_at = now() WHERE id = $4`
-	_, err := r.db.Exec(query, unit.Name, unit.Address, unit.Description, unit.ID)
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			tx.Commit()
+		}
+	}()
+
+	if unit.ParentID != nil {
+		if err = checkUnitParent(tx, unit.ID, *unit.ParentID); err != nil {
+			return nil, err
+		}
+	}
+	query := `UPDATE achmadnr.units SET parent_id = $1, name = $2, address = $3, description = $4, modified_at = now() WHERE id = $5`
+	_, err = tx.Exec(query, unit.ParentID, unit.Name, unit.Address, unit.Description, unit.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -90,7 +109,7 @@ func (r *UnitRepository) Delete(id int) error {
 	return nil
 }
 func (r *UnitRepository) FindByName(name string) ([]domain.Unit, error) {
-	query := `SELECT id, name, address, description, created_at, modified_at FROM achmadnr.units WHERE name ILIKE $1`
+	query := `SELECT id, parent_id, name, address, description, created_at, modified_at FROM achmadnr.units WHERE name ILIKE $1`
 	rows, err := r.db.Query(query, "%"+name+"%")
 	if err != nil {
 		return nil, err
@@ -101,6 +120,7 @@ func (r *UnitRepository) FindByName(name string) ([]domain.Unit, error) {
 		var unit domain.Unit
 		if err := rows.Scan(
 			&unit.ID,
+			&unit.ParentID,
 			&unit.Name,
 			&unit.Address,
 			&unit.Description,
@@ -116,7 +136,7 @@ func (r *UnitRepository) FindByName(name string) ([]domain.Unit, error) {
 
 func (r *UnitRepository) Search(query string) ([]domain.Unit, error) {
 	query = "%" + query + "%"
-	sqlQuery := `SELECT id, name, address, description, created_at, modified_at FROM achmadnr.units WHERE name ILIKE $1 OR address ILIKE $1 ILIKE $1`
+	sqlQuery := `SELECT id, parent_id, name, address, description, created_at, modified_at FROM achmadnr.units WHERE name ILIKE $1 OR address ILIKE $1 ILIKE $1`
 	rows, err := r.db.Query(sqlQuery, query)
 	if err != nil {
 		return nil, err
@@ -128,15 +148,144 @@ func (r *UnitRepository) Search(query string) ([]domain.Unit, error) {
 		var unit domain.Unit
 		if err := rows.Scan(
 			&unit.ID,
+			&unit.ParentID,
+			&unit.Name,
+			&unit.Address,
+			&unit.Description,
+			&unit.CreatedAt,
+			&unit.ModifiedAt,
+		); err != nil {
+			return nil, err
+		}
+		units = append(units, unit)
+	}
+	return units, nil
+}
+
+// unitSubtreeCTE menelusuri unit $1 beserta turunannya, path mencegah perulangan jika data parent rusak
+const unitSubtreeCTE = `WITH RECURSIVE subtree AS (
+		SELECT u.id, u.parent_id, 0 AS depth, ARRAY[u.id] AS path
+		FROM achmadnr.units u WHERE u.id = $1
+		UNION ALL
+		SELECT c.id, c.parent_id, s.depth + 1, s.path || c.id
+		FROM achmadnr.units c
+		INNER JOIN subtree s ON c.parent_id = s.id
+		WHERE NOT c.id = ANY(s.path)
+	)`
+
+func (r *UnitRepository) FindSubtreeIDs(rootID int) ([]int, error) {
+	query := unitSubtreeCTE + ` SELECT id FROM subtree ORDER BY depth, id`
+	rows, err := r.db.Query(query, rootID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (r *UnitRepository) FindSubtree(rootID int) ([]domain.UnitTree, error) {
+	query := unitSubtreeCTE + `
+	SELECT u.id, u.parent_id, u.name, u.address, u.description, u.created_at, u.modified_at, s.depth,
+	(SELECT COUNT(DISTINCT ea.employee_id) FROM achmadnr.employee_assignments ea
+		INNER JOIN achmadnr.employees e ON ea.employee_id = e.id
+		WHERE ea.unit_id = u.id AND ` + activeAssignment("ea") + `
+		AND e.employment_status IN ('active', 'on_leave')) AS headcount,
+	(SELECT COUNT(DISTINCT ea.employee_id) FROM subtree d
+		INNER JOIN achmadnr.employee_assignments ea ON ea.unit_id = d.id
+		INNER JOIN achmadnr.employees e ON ea.employee_id = e.id
+		WHERE s.id = ANY(d.path) AND ` + activeAssignment("ea") + `
+		AND e.employment_status IN ('active', 'on_leave')) AS total_headcount,
+	coalesce(h.employee_id::text, ''), coalesce(h.nip, ''), coalesce(h.full_name, ''), coalesce(h.position_name, '')
+	FROM subtree s
+	INNER JOIN achmadnr.units u ON s.id = u.id
+	LEFT JOIN LATERAL (
+		SELECT ea.employee_id, e.nip, e.full_name, p.name AS position_name
+		FROM achmadnr.employee_assignments ea
+		INNER JOIN achmadnr.employees e ON ea.employee_id = e.id
+		INNER JOIN achmadnr.positions p ON ea.position_id = p.id
+		WHERE ea.unit_id = u.id AND ` + activeAssignment("ea") + ` AND p.is_head = TRUE
+		ORDER BY (ea.assignment_type = 'primary') DESC, ea.start_date DESC
+		LIMIT 1
+	) h ON TRUE
+	ORDER BY s.depth, u.name`
+	rows, err := r.db.Query(query, rootID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var units []domain.UnitTree
+	for rows.Next() {
+		var unit domain.UnitTree
+		var head domain.UnitHead
+		if err := rows.Scan(
+			&unit.ID,
+			&unit.ParentID,
 			&unit.Name,
 			&unit.Address,
 			&unit.Description,
 			&unit.CreatedAt,
 			&unit.ModifiedAt,
+			&unit.Depth,
+			&unit.Headcount,
+			&unit.TotalHeadcount,
+			&head.EmployeeID,
+			&head.NIP,
+			&head.FullName,
+			&head.PositionName,
 		); err != nil {
 			return nil, err
 		}
+		if head.EmployeeID != "" {
+			unit.Head = &head
+		}
 		units = append(units, unit)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 	return units, nil
 }
+
+// ==================================================================== UTILITIES ====================================================================
+
+// unitAncestorsCTE mengambil unit $2 beserta seluruh leluhurnya
+const unitAncestorsCTE = `WITH RECURSIVE ancestors AS (
+		SELECT u.id, u.parent_id, ARRAY[u.id] AS path
+		FROM achmadnr.units u WHERE u.id = $2
+		UNION ALL
+		SELECT p.id, p.parent_id, a.path || p.id
+		FROM achmadnr.units p
+		INNER JOIN ancestors a ON p.id = a.parent_id
+		WHERE NOT p.id = ANY(a.path)
+	)`
+
+// checkUnitParent mengunci unit dan seluruh leluhur parent barunya sebelum memeriksa siklus, sehingga dua
+// pemindahan yang saling bergantung tidak dapat lolos bersamaan. Parent membentuk siklus jika unit termasuk
+// leluhur parent.
+func checkUnitParent(tx *sql.Tx, unitID int, parentID int) error {
+	lock := unitAncestorsCTE + ` SELECT u.id FROM achmadnr.units u
+	WHERE u.id = $1 OR u.id IN (SELECT id FROM ancestors) ORDER BY u.id FOR UPDATE`
+	if _, err := tx.Exec(lock, unitID, parentID); err != nil {
+		return err
+	}
+	var cycle bool
+	check := unitAncestorsCTE + ` SELECT EXISTS (SELECT 1 FROM ancestors WHERE id = $1)`
+	if err := tx.QueryRow(check, unitID, parentID).Scan(&cycle); err != nil {
+		return err
+	}
+	if cycle {
+		return domain.ErrUnitCycle
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/achmadnr21/emploman/internal/domain"
+	"github.com/lib/pq"
+)
+
+type UnitGroupRepository struct {
+	db *sql.DB
+}
+
+func NewUnitGroupRepository(db *sql.DB) *UnitGroupRepository {
+	return &UnitGroupRepository{
+		db: db,
+	}
+}
+
+// unitGroupQuery mengambil grup beserta id unit anggotanya dalam satu query
+const unitGroupQuery = `SELECT g.id, g.name, coalesce(g.description, ''),
+	coalesce(array_agg(m.unit_id ORDER BY m.unit_id) FILTER (WHERE m.unit_id IS NOT NULL), '{}'),
+	g.created_at, g.modified_at
+	FROM achmadnr.unit_groups g
+	LEFT JOIN achmadnr.unit_group_members m ON g.id = m.group_id`
+
+func (r *UnitGroupRepository) FindAll() ([]domain.UnitGroup, error) {
+	rows, err := r.db.Query(unitGroupQuery + ` GROUP BY g.id ORDER BY g.name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	groups := []domain.UnitGroup{}
+	for rows.Next() {
+		group, err := scanUnitGroup(rows)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, *group)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+func (r *UnitGroupRepository) FindByID(id int) (*domain.UnitGroup, error) {
+	return scanUnitGroup(r.db.QueryRow(unitGroupQuery+` WHERE g.id = $1 GROUP BY g.id`, id))
+}
+
+func (r *UnitGroupRepository) Save(group *domain.UnitGroup) (*domain.UnitGroup, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			tx.Commit()
+		}
+	}()
+
+	err = tx.QueryRow(`INSERT INTO achmadnr.unit_groups (name, description) VALUES ($1, NULLIF($2, ''))
+		RETURNING id, created_at, modified_at`,
+		group.Name, group.Description).Scan(&group.ID, &group.CreatedAt, &group.ModifiedAt)
+	if isPgError(err, pgUniqueViolation) {
+		err = domain.ErrUnitGroupExists
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = saveUnitGroupMembers(tx, group); err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+func (r *UnitGroupRepository) Update(group *domain.UnitGroup) (*domain.UnitGroup, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			tx.Commit()
+		}
+	}()
+
+	err = tx.QueryRow(`UPDATE achmadnr.unit_groups SET name = $1, description = NULLIF($2, ''), modified_at = now()
+		WHERE id = $3 RETURNING created_at, modified_at`,
+		group.Name, group.Description, group.ID).Scan(&group.CreatedAt, &group.ModifiedAt)
+	if isPgError(err, pgUniqueViolation) {
+		err = domain.ErrUnitGroupExists
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	// anggota grup selalu diganti seluruhnya
+	if _, err = tx.Exec(`DELETE FROM achmadnr.unit_group_members WHERE group_id = $1`, group.ID); err != nil {
+		return nil, err
+	}
+	if err = saveUnitGroupMembers(tx, group); err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+func (r *UnitGroupRepository) Delete(id int) error {
+	res, err := r.db.Exec(`DELETE FROM achmadnr.unit_groups WHERE id = $1`, id)
+	if isPgError(err, pgForeignKeyViolation) {
+		return domain.ErrUnitGroupInUse
+	}
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("no rows deleted")
+	}
+	return nil
+}
+
+// ==================================================================== UTILITIES ====================================================================
+
+func scanUnitGroup(row interface{ Scan(...interface{}) error }) (*domain.UnitGroup, error) {
+	var group domain.UnitGroup
+	var unitIDs pq.Int64Array
+	if err := row.Scan(&group.ID, &group.Name, &group.Description, &unitIDs,
+		&group.CreatedAt, &group.ModifiedAt); err != nil {
+		return nil, err
+	}
+	group.UnitIDs = make([]int, 0, len(unitIDs))
+	for _, id := range unitIDs {
+		group.UnitIDs = append(group.UnitIDs, int(id))
+	}
+	return &group, nil
+}
+
+func saveUnitGroupMembers(tx *sql.Tx, group *domain.UnitGroup) error {
+	for _, unitID := range group.UnitIDs {
+		_, err := tx.Exec(`INSERT INTO achmadnr.unit_group_members (group_id, unit_id) VALUES ($1, $2)`, group.ID, unitID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/achmadnr21/emploman/internal/domain"
+)
+
+type UnitScopeRepository struct {
+	db *sql.DB
+}
+
+func NewUnitScopeRepository(db *sql.DB) *UnitScopeRepository {
+	return &UnitScopeRepository{
+		db: db,
+	}
+}
+
+// unitScopeQuery mengambil grant beserta nama unit atau nama unit group yang diberikan
+const unitScopeQuery = `SELECT s.id, s.employee_id, s.unit_id, u.name, s.unit_group_id, g.name, s.include_subunits, s.granted_by, s.created_at
+	FROM achmadnr.employee_unit_scopes s
+	LEFT JOIN achmadnr.units u ON s.unit_id = u.id
+	LEFT JOIN achmadnr.unit_groups g ON s.unit_group_id = g.id`
+
+func (r *UnitScopeRepository) FindByEmployeeID(employeeID string) ([]domain.UnitScopeGrant, error) {
+	query := unitScopeQuery + ` WHERE s.employee_id = $1 ORDER BY coalesce(u.name, g.name)`
+	rows, err := r.db.Query(query, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	grants := []domain.UnitScopeGrant{}
+	for rows.Next() {
+		grant, err := scanUnitScopeGrant(rows)
+		if err != nil {
+			return nil, err
+		}
+		grants = append(grants, *grant)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return grants, nil
+}
+
+func (r *UnitScopeRepository) FindByID(id int) (*domain.UnitScopeGrant, error) {
+	return scanUnitScopeGrant(r.db.QueryRow(unitScopeQuery+` WHERE s.id = $1`, id))
+}
+
+func (r *UnitScopeRepository) Save(grant *domain.UnitScopeGrant) (*domain.UnitScopeGrant, error) {
+	query := `INSERT INTO achmadnr.employee_unit_scopes (employee_id, unit_id, unit_group_id, include_subunits, granted_by)
+	VALUES ($1, NULLIF($2, 0), NULLIF($3, 0), $4, $5) RETURNING id, created_at`
+	err := r.db.QueryRow(query, grant.EmployeeID, grant.UnitID, grant.UnitGroupID, grant.IncludeSubunits, grant.GrantedBy).Scan(&grant.ID, &grant.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return grant, nil
+}
+
+func (r *UnitScopeRepository) Delete(id int) error {
+	query := `DELETE FROM achmadnr.employee_unit_scopes WHERE id = $1`
+	res, err := r.db.Exec(query, id)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("no rows deleted")
+	}
+	return nil
+}
+
+// ==================================================================== UTILITIES ====================================================================
+
+func scanUnitScopeGrant(row interface{ Scan(...interface{}) error }) (*domain.UnitScopeGrant, error) {
+	var grant domain.UnitScopeGrant
+	var unitID, groupID sql.NullInt64
+	var unitName, groupName sql.NullString
+	if err := row.Scan(&grant.ID, &grant.EmployeeID, &unitID, &unitName, &groupID, &groupName,
+		&grant.IncludeSubunits, &grant.GrantedBy, &grant.CreatedAt); err != nil {
+		return nil, err
+	}
+	grant.UnitID = int(unitID.Int64)
+	grant.UnitName = unitName.String
+	grant.UnitGroupID = int(groupID.Int64)
+	grant.UnitGroupName = groupName.String
+	return &grant, nil
+}
@@ -0,0 +1,60 @@
+package usecase
+
+import (
+	"fmt"
+
+	"github.com/achmadnr21/emploman/internal/authorization"
+	"github.com/achmadnr21/emploman/internal/domain"
+	"github.com/achmadnr21/emploman/internal/utils"
+)
+
+const (
+	auditDefaultPageSize = 20
+	auditMaxPageSize     = 100
+)
+
+type AuditUsecase struct {
+	auditRepo domain.AuditInterface
+	authz     *authorization.Authorizer
+}
+
+func NewAuditUsecase(auditRepo domain.AuditInterface, authz *authorization.Authorizer) *AuditUsecase {
+	return &AuditUsecase{
+		auditRepo: auditRepo,
+		authz:     authz,
+	}
+}
+
+func (uc *AuditUsecase) GetAll(principal *domain.Principal, filter domain.AuditFilter) ([]domain.AuditLog, *domain.PageMeta, error) {
+	if err := uc.authz.Authorize(principal, authorization.AuditRead, authorization.Resource{}); err != nil {
+		return nil, nil, err
+	}
+	if filter.PageSize <= 0 {
+		filter.PageSize = auditDefaultPageSize
+	}
+	if filter.PageSize > auditMaxPageSize {
+		return nil, nil, &utils.BadRequestError{Message: fmt.Sprintf("page_size cannot be more than %d", auditMaxPageSize)}
+	}
+	if filter.Page <= 0 {
+		filter.Page = 1
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return nil, nil, &utils.BadRequestError{Message: "from must be before to"}
+	}
+
+	logs, total, err := uc.auditRepo.FindAll(filter)
+	if err != nil {
+		fmt.Println("Error getting audit logs:", err)
+		return nil, nil, &utils.InternalServerError{Message: "failed to get audit logs"}
+	}
+	if logs == nil {
+		logs = []domain.AuditLog{}
+	}
+	meta := &domain.PageMeta{
+		Page:       filter.Page,
+		PageSize:   filter.PageSize,
+		Total:      total,
+		TotalPages: (total + filter.PageSize - 1) / filter.PageSize,
+	}
+	return logs, meta, nil
+}
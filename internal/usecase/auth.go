@@ -1,67 +1,401 @@
 package usecase
 
 import (
+	"database/sql"
+	"errors"
+	"fmt"
 	"time"
 
+	"github.com/achmadnr21/emploman/internal/authorization"
 	"github.com/achmadnr21/emploman/internal/domain"
 	"github.com/achmadnr21/emploman/internal/utils"
 )
 
+// Kebijakan pembatasan login. Setelah loginDelayAfter kegagalan, percobaan berikutnya harus
+// menunggu 1, 2, 4, ... detik (maksimal loginMaxDelay). Setelah threshold kegagalan dalam
+// loginFailureWindow, key dikunci selama loginLockoutDuration.
+const (
+	loginFailureWindow   = 15 * time.Minute
+	loginDelayAfter      = 3
+	loginMaxDelay        = time.Minute
+	loginLockoutNIP      = 5
+	loginLockoutIP       = 20
+	loginLockoutDuration = 15 * time.Minute
+)
+
+// twoFactorTokenTTL adalah batas waktu memasukkan kode 2FA setelah password benar
+const twoFactorTokenTTL = 5 * time.Minute
+
+// dummyPasswordHash dipakai untuk nip yang tidak terdaftar agar waktu respon sama dengan password salah
+const dummyPasswordHash = "$2a$12$sPDC2Gm5dS3w7GWuM3xy.eDGuZyc25PfS.LFJyVmJHpawV1eClqWi"
+
 type AuthUsecase struct {
-	EmpRepo  domain.EmployeeInterface
-	RoleRepo domain.RoleInterface
+	EmpRepo       domain.EmployeeInterface
+	RoleRepo      domain.RoleInterface
+	TokenRepo     domain.RefreshTokenInterface
+	PasswordRepo  domain.PasswordInterface
+	AttemptRepo   domain.LoginAttemptInterface
+	TwoFactorRepo domain.TwoFactorInterface
+	AuditRepo     domain.AuditInterface
+	Authz         *authorization.Authorizer
 }
 
-func NewAuthUsecase(employeeRepo domain.EmployeeInterface, roleRepo domain.RoleInterface) *AuthUsecase {
+func NewAuthUsecase(employeeRepo domain.EmployeeInterface, roleRepo domain.RoleInterface, tokenRepo domain.RefreshTokenInterface, passwordRepo domain.PasswordInterface, attemptRepo domain.LoginAttemptInterface, twoFactorRepo domain.TwoFactorInterface, auditRepo domain.AuditInterface, authz *authorization.Authorizer) *AuthUsecase {
 	return &AuthUsecase{
-		EmpRepo:  employeeRepo,
-		RoleRepo: roleRepo,
+		EmpRepo:       employeeRepo,
+		RoleRepo:      roleRepo,
+		TokenRepo:     tokenRepo,
+		PasswordRepo:  passwordRepo,
+		AttemptRepo:   attemptRepo,
+		TwoFactorRepo: twoFactorRepo,
+		AuditRepo:     auditRepo,
+		Authz:         authz,
 	}
 }
 
-func (au *AuthUsecase) Login(nip, password string) (string, string, error) {
+func (au *AuthUsecase) Login(nip, password, ip string) (*domain.AuthTokens, error) {
+	nipKey := domain.LoginAttemptKeyNIP + nip
+	ipKey := domain.LoginAttemptKeyIP + ip
+	if err := checkLoginThrottle(au.AttemptRepo, []string{nipKey, ipKey}); err != nil {
+		return nil, err
+	}
 	// Find employee by NIK
 	employee, err := au.EmpRepo.FindByNIP(nip)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		fmt.Println("Error finding employee by nip:", err)
+		return nil, &utils.InternalServerError{Message: "failed to login"}
+	}
+	// nip tidak terdaftar dan password salah mendapat respon yang sama
+	hash := dummyPasswordHash
+	if employee != nil {
+		hash = employee.Password
+	}
+	if !utils.CheckPasswordHash(password, hash) || employee == nil {
+		recordLoginFailure(au.AttemptRepo, nipKey, loginLockoutNIP)
+		recordLoginFailure(au.AttemptRepo, ipKey, loginLockoutIP)
+		return nil, &utils.UnauthorizedError{Message: "invalid user or password"}
+	}
+	// hitungan per ip tidak direset agar login sukses dengan akun sendiri tidak membuka tebakan akun lain
+	if err := au.AttemptRepo.Reset(nipKey); err != nil {
+		fmt.Println("Error resetting login attempts:", err)
+	}
+	// pegawai yang sudah pensiun/berhenti tidak boleh login
+	if !employee.IsActive() {
+		return nil, &utils.UnauthorizedError{Message: "account is inactive"}
+	}
+	// employee dengan 2FA aktif harus memasukkan kode lebih dulu lewat VerifyTwoFactor
+	tf, err := au.TwoFactorRepo.FindByEmployeeID(employee.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		fmt.Println("Error getting two-factor:", err)
+		return nil, &utils.InternalServerError{Message: "failed to login"}
+	}
+	if tf != nil && tf.Enabled {
+		token, err := utils.GenerateRestrictedToken(employee.ID, utils.TokenPurposeTwoFactor, twoFactorTokenTTL)
+		if err != nil {
+			return nil, &utils.InternalServerError{Message: "failed to generate token"}
+		}
+		return &domain.AuthTokens{TwoFactorToken: token, TwoFactorRequired: true}, nil
+	}
+	return au.completeLogin(employee, false)
+}
+
+// VerifyTwoFactor adalah langkah kedua login, menukar token 2FA dan kode TOTP atau recovery code
+// dengan token akses. Kode yang salah ikut dihitung sebagai kegagalan login.
+func (au *AuthUsecase) VerifyTwoFactor(twoFactorToken string, payload domain.TwoFactorCode, ip string) (*domain.AuthTokens, error) {
+	claims, err := utils.ParseAccessToken(twoFactorToken)
+	if err != nil || claims.Purpose != utils.TokenPurposeTwoFactor {
+		return nil, &utils.UnauthorizedError{Message: "invalid two-factor token"}
+	}
+	employee, err := au.EmpRepo.FindByID(claims.UserId)
+	if err != nil || !employee.IsActive() {
+		return nil, &utils.UnauthorizedError{Message: "account is inactive"}
+	}
+	nipKey := domain.LoginAttemptKeyNIP + employee.NIP
+	ipKey := domain.LoginAttemptKeyIP + ip
+	if err := checkLoginThrottle(au.AttemptRepo, []string{nipKey, ipKey}); err != nil {
+		return nil, err
+	}
+	valid, err := verifyTwoFactorCode(au.TwoFactorRepo, employee.ID, payload)
+	if err != nil {
+		return nil, err
+	}
+	if !valid {
+		recordLoginFailure(au.AttemptRepo, nipKey, loginLockoutNIP)
+		recordLoginFailure(au.AttemptRepo, ipKey, loginLockoutIP)
+		return nil, &utils.UnauthorizedError{Message: "invalid two-factor code"}
+	}
+	if err := au.AttemptRepo.Reset(nipKey); err != nil {
+		fmt.Println("Error resetting login attempts:", err)
+	}
+	return au.completeLogin(employee, true)
+}
+
+func (au *AuthUsecase) RefreshToken(refreshToken string) (*domain.AuthTokens, error) {
+	stored, err := au.verifyRefreshToken(refreshToken)
 	if err != nil {
-		return "", "", &utils.NotFoundError{Message: "user not found"}
+		return nil, err
+	}
+	// token yang sudah dipakai/dicabut dipakai lagi, cabut seluruh family
+	if stored.RevokedAt != nil {
+		if err := au.TokenRepo.RevokeFamily(stored.FamilyID); err != nil {
+			fmt.Println("Error revoking token family:", err)
+		}
+		return nil, &utils.UnauthorizedError{Message: "refresh token reuse detected"}
+	}
+	employee, err := au.EmpRepo.FindByID(stored.EmployeeID)
+	if err != nil || !employee.IsActive() {
+		return nil, &utils.UnauthorizedError{Message: "account is inactive"}
 	}
-	// Check password
-	if !utils.CheckPasswordHash(password, employee.Password) {
-		return "", "", &utils.UnauthorizedError{Message: "invalid user or password"}
+	// role dibaca ulang agar perubahan role employee masuk ke token akses berikutnya
+	role, err := au.RoleRepo.FindByID(employee.RoleID)
+	if err != nil {
+		return nil, &utils.InternalServerError{Message: "failed to get user role"}
+	}
+	// role yang mewajibkan 2FA dicek ulang seperti saat login, sesi tanpa 2FA tidak boleh diperpanjang
+	if role.Require2FA {
+		if tokens, err := au.stepDownSession(stored); tokens != nil || err != nil {
+			return tokens, err
+		}
+	}
+	// generate token ketika valid dan tidak expired
+	token, err := utils.GenerateAccessToken(stored.EmployeeID, role)
+	if err != nil {
+		return nil, &utils.InternalServerError{Message: "failed to generate token"}
+	}
+	newRefreshToken, newStored, err := au.issueRefreshToken(stored.EmployeeID, stored.FamilyID, stored.TwoFactorVerified)
+	if err != nil {
+		return nil, err
+	}
+	rotated, err := au.TokenRepo.Rotate(stored.ID, newStored)
+	if err != nil {
+		fmt.Println("Error rotating refresh token:", err)
+		return nil, &utils.InternalServerError{Message: "failed to rotate refresh token"}
+	}
+	if !rotated {
+		// request lain sudah merotasi token ini lebih dulu
+		if err := au.TokenRepo.RevokeFamily(stored.FamilyID); err != nil {
+			fmt.Println("Error revoking token family:", err)
+		}
+		return nil, &utils.UnauthorizedError{Message: "refresh token reuse detected"}
+	}
+	return &domain.AuthTokens{AccessToken: token, RefreshToken: newRefreshToken}, nil
+}
+
+// Logout mencabut seluruh family dari refresh token yang diberikan (satu sesi login)
+func (au *AuthUsecase) Logout(refreshToken string) error {
+	stored, err := au.verifyRefreshToken(refreshToken)
+	if err != nil {
+		return err
+	}
+	if err := au.TokenRepo.RevokeFamily(stored.FamilyID); err != nil {
+		return &utils.InternalServerError{Message: "failed to revoke refresh token"}
+	}
+	return nil
+}
+
+// LogoutAll mencabut semua refresh token milik user di semua perangkat
+func (au *AuthUsecase) LogoutAll(proposerId string) error {
+	if err := au.TokenRepo.RevokeAllByEmployeeID(proposerId); err != nil {
+		return &utils.InternalServerError{Message: "failed to revoke refresh tokens"}
+	}
+	return nil
+}
+
+// UnlockAccount menghapus kunci login sebuah nip sebelum masa kuncinya habis
+func (au *AuthUsecase) UnlockAccount(principal *domain.Principal, nip string, meta domain.AuditMeta) error {
+	if err := au.Authz.Authorize(principal, authorization.EmployeeCredential, authorization.Resource{}); err != nil {
+		return err
+	}
+	employee, err := au.EmpRepo.FindByNIP(nip)
+	if err != nil {
+		return &utils.NotFoundError{Message: "employee not found"}
+	}
+	attempts, err := au.AttemptRepo.FindByKeys([]string{domain.LoginAttemptKeyNIP + nip})
+	if err != nil {
+		fmt.Println("Error getting login attempts:", err)
+		return &utils.InternalServerError{Message: "failed to get login attempts"}
+	}
+	if len(attempts) == 0 {
+		return &utils.BadRequestError{Message: "account is not locked"}
+	}
+	if err := au.AttemptRepo.Reset(domain.LoginAttemptKeyNIP + nip); err != nil {
+		fmt.Println("Error resetting login attempts:", err)
+		return &utils.InternalServerError{Message: "failed to unlock account"}
+	}
+	utils.RecordAudit(au.AuditRepo, meta.Entry(principal.UserID, domain.AuditActionUnlock, domain.AuditEntityEmployee, employee.ID), &attempts[0], nil)
+	return nil
+}
+
+// ==================================================================== UTILITIES ====================================================================
+
+// completeLogin menerbitkan token setelah password (dan kode 2FA jika aktif) terverifikasi
+func (au *AuthUsecase) completeLogin(employee *domain.Employee, twoFactorVerified bool) (*domain.AuthTokens, error) {
+	// password hasil reset admin harus diganti dulu, beri token terbatas tanpa refresh token
+	mustChange, err := au.PasswordRepo.MustChangePassword(employee.ID)
+	if err != nil {
+		fmt.Println("Error checking must change password:", err)
+		return nil, &utils.InternalServerError{Message: "failed to check password status"}
+	}
+	if mustChange {
+		token, err := utils.GenerateRestrictedToken(employee.ID, utils.TokenPurposePasswordChange, time.Minute*time.Duration(utils.JWT_EXP_MIN))
+		if err != nil {
+			return nil, &utils.InternalServerError{Message: "failed to generate token"}
+		}
+		return &domain.AuthTokens{AccessToken: token, MustChangePassword: true}, nil
+	}
+	role, err := au.RoleRepo.FindByID(employee.RoleID)
+	if err != nil {
+		return nil, &utils.InternalServerError{Message: "failed to get user role"}
+	}
+	// role yang mewajibkan 2FA hanya mendapat token untuk mengaktifkan 2FA
+	if !twoFactorVerified {
+		if role.Require2FA {
+			token, err := utils.GenerateRestrictedToken(employee.ID, utils.TokenPurposeTwoFactorSetup, time.Minute*time.Duration(utils.JWT_EXP_MIN))
+			if err != nil {
+				return nil, &utils.InternalServerError{Message: "failed to generate token"}
+			}
+			return &domain.AuthTokens{AccessToken: token, TwoFactorSetupRequired: true}, nil
+		}
 	}
 	// generate token
-	token, err := utils.GenerateAccessToken(employee.ID)
+	token, err := utils.GenerateAccessToken(employee.ID, role)
+	if err != nil {
+		return nil, &utils.InternalServerError{Message: "failed to generate token"}
+	}
+	// login baru selalu membuka family refresh token baru
+	familyID, err := utils.GenerateRandomID(16)
+	if err != nil {
+		return nil, &utils.InternalServerError{Message: "failed to generate refresh token"}
+	}
+	refreshToken, stored, err := au.issueRefreshToken(employee.ID, familyID, twoFactorVerified)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := au.TokenRepo.Save(stored); err != nil {
+		fmt.Println("Error saving refresh token:", err)
+		return nil, &utils.InternalServerError{Message: "failed to store refresh token"}
+	}
+	return &domain.AuthTokens{AccessToken: token, RefreshToken: refreshToken}, nil
+}
+
+// checkLoginThrottle menolak login jika salah satu key sedang dikunci atau masih dalam jeda.
+// Dipakai juga oleh setiap pengecekan kode 2FA di luar login.
+func checkLoginThrottle(attemptRepo domain.LoginAttemptInterface, keys []string) error {
+	attempts, err := attemptRepo.FindByKeys(keys)
+	if err != nil {
+		// pembatasan tidak boleh membuat login tidak bisa dipakai sama sekali
+		fmt.Println("Error getting login attempts:", err)
+		return nil
+	}
+	var wait time.Duration
+	for _, attempt := range attempts {
+		wait = max(wait, attempt.LockRemaining)
+		if attempt.SinceLastFailure > loginFailureWindow {
+			continue
+		}
+		wait = max(wait, loginDelay(attempt.FailedCount)-attempt.SinceLastFailure)
+	}
+	if wait <= 0 {
+		return nil
+	}
+	seconds := int((wait + time.Second - 1) / time.Second)
+	return &utils.TooManyRequestError{
+		Message:    fmt.Sprintf("too many failed login attempts, try again in %d seconds", seconds),
+		RetryAfter: seconds,
+	}
+}
+
+// recordLoginFailure menambah hitungan gagal dan mengunci key jika sudah mencapai threshold
+func recordLoginFailure(attemptRepo domain.LoginAttemptInterface, key string, threshold int) {
+	attempt, err := attemptRepo.RecordFailure(key, loginFailureWindow)
 	if err != nil {
-		return "", "", &utils.InternalServerError{Message: "failed to generate token"}
+		fmt.Println("Error recording login failure:", err)
+		return
+	}
+	if attempt.FailedCount >= threshold {
+		if err := attemptRepo.Lock(key, loginLockoutDuration); err != nil {
+			fmt.Println("Error locking login:", err)
+		}
+	}
+}
+
+// loginDelay adalah jeda minimal setelah kegagalan ke-failedCount
+func loginDelay(failedCount int) time.Duration {
+	if failedCount < loginDelayAfter {
+		return 0
+	}
+	shift := min(failedCount-loginDelayAfter, 6)
+	return min(time.Second<<shift, loginMaxDelay)
+}
+
+// stepDownSession memeriksa sesi milik role yang mewajibkan 2FA. Sesi yang login dengan 2FA dan 2FA-nya masih aktif
+// dilanjutkan (nil, nil). Selain itu family dicabut: employee yang belum mengaktifkan 2FA hanya mendapat token untuk
+// mengaktifkan 2FA, sedangkan sesi yang login tanpa kode 2FA harus login ulang.
+func (au *AuthUsecase) stepDownSession(stored *domain.RefreshToken) (*domain.AuthTokens, error) {
+	tf, err := au.TwoFactorRepo.FindByEmployeeID(stored.EmployeeID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		fmt.Println("Error getting two-factor:", err)
+		return nil, &utils.InternalServerError{Message: "failed to refresh token"}
 	}
-	refreshToken, err := utils.GenerateRefreshToken(employee.ID)
+	enabled := tf != nil && tf.Enabled
+	if enabled && stored.TwoFactorVerified {
+		return nil, nil
+	}
+	if err := au.TokenRepo.RevokeFamily(stored.FamilyID); err != nil {
+		fmt.Println("Error revoking token family:", err)
+		return nil, &utils.InternalServerError{Message: "failed to refresh token"}
+	}
+	if enabled {
+		return nil, &utils.UnauthorizedError{Message: "two-factor authentication required, please log in again"}
+	}
+	token, err := utils.GenerateRestrictedToken(stored.EmployeeID, utils.TokenPurposeTwoFactorSetup, time.Minute*time.Duration(utils.JWT_EXP_MIN))
 	if err != nil {
-		return "", "", &utils.InternalServerError{Message: "failed to generate refresh token"}
+		return nil, &utils.InternalServerError{Message: "failed to generate token"}
 	}
-	// Return token and role
-	return token, refreshToken, nil
+	return &domain.AuthTokens{AccessToken: token, TwoFactorSetupRequired: true}, nil
+}
 
+func (au *AuthUsecase) issueRefreshToken(employeeID string, familyID string, twoFactorVerified bool) (string, *domain.RefreshToken, error) {
+	tokenID, err := utils.GenerateRandomID(16)
+	if err != nil {
+		return "", nil, &utils.InternalServerError{Message: "failed to generate refresh token"}
+	}
+	expiresAt := time.Now().Add(time.Minute * time.Duration(utils.REF_EXP_MIN))
+	refreshToken, err := utils.GenerateRefreshToken(employeeID, tokenID, familyID, expiresAt)
+	if err != nil {
+		return "", nil, &utils.InternalServerError{Message: "failed to generate refresh token"}
+	}
+	stored := &domain.RefreshToken{
+		ID:                tokenID,
+		FamilyID:          familyID,
+		EmployeeID:        employeeID,
+		TokenHash:         utils.HashToken(refreshToken),
+		TwoFactorVerified: twoFactorVerified,
+		ExpiresAt:         expiresAt,
+	}
+	return refreshToken, stored, nil
 }
 
-func (au *AuthUsecase) RefreshToken(refreshToken string) (string, string, error) {
+func (au *AuthUsecase) verifyRefreshToken(refreshToken string) (*domain.RefreshToken, error) {
 	// Parse refresh token
 	claims, err := utils.ParseRefreshToken(refreshToken)
 	if err != nil {
-		return "", "", &utils.UnauthorizedError{Message: "invalid refresh token"}
+		return nil, &utils.UnauthorizedError{Message: "invalid refresh token"}
 	}
 	// expired
 	if claims.ExpiresAt.Time.Before(time.Now()) {
-		return "", "", &utils.UnauthorizedError{Message: "refresh token expired"}
+		return nil, &utils.UnauthorizedError{Message: "refresh token expired"}
 	}
-	// generate token ketika valid dan tidak expired
-	token, err := utils.GenerateAccessToken(claims.UserId)
-	if err != nil {
-		return "", "", &utils.InternalServerError{Message: "failed to generate token"}
+	// token tanpa jti berasal dari sebelum token disimpan di server
+	if claims.ID == "" {
+		return nil, &utils.UnauthorizedError{Message: "invalid refresh token"}
 	}
-	refreshToken, err = utils.GenerateRefreshToken(claims.UserId)
+	stored, err := au.TokenRepo.FindByID(claims.ID)
 	if err != nil {
-		return "", "", &utils.InternalServerError{Message: "failed to generate refresh token"}
+		return nil, &utils.UnauthorizedError{Message: "invalid refresh token"}
+	}
+	if stored.TokenHash != utils.HashToken(refreshToken) || stored.EmployeeID != claims.UserId {
+		return nil, &utils.UnauthorizedError{Message: "invalid refresh token"}
 	}
-	// Return token and role
-	return token, refreshToken, nil
+	return stored, nil
 }
@@ -0,0 +1,307 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/achmadnr21/emploman/internal/authorization"
+	"github.com/achmadnr21/emploman/internal/domain"
+	usecase_employee "github.com/achmadnr21/emploman/internal/usecase/employee"
+	"github.com/achmadnr21/emploman/internal/utils"
+)
+
+const (
+	changeRequestMaxDocuments    = 5
+	changeRequestMaxDocumentSize = 5 << 20 // 5 MB
+)
+
+// changeRequestContentTypes adalah jenis dokumen pendukung yang diterima
+var changeRequestContentTypes = map[string]string{
+	".pdf":  "application/pdf",
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".png":  "image/png",
+}
+
+type ChangeRequestUsecase struct {
+	changeRequestRepo domain.ChangeRequestInterface
+	empRepo           domain.EmployeeInterface
+	authz             *authorization.Authorizer
+	gradeRepo         domain.GradeInterface
+	echelonRepo       domain.EchelonInterface
+	s3Repo            domain.S3Interface
+	auditRepo         domain.AuditInterface
+	empUsecase        *usecase_employee.EmployeeUsecase
+}
+
+func NewChangeRequestUsecase(changeRequestRepo domain.ChangeRequestInterface, empRepo domain.EmployeeInterface, authz *authorization.Authorizer, gradeRepo domain.GradeInterface, echelonRepo domain.EchelonInterface, s3Repo domain.S3Interface, auditRepo domain.AuditInterface, empUsecase *usecase_employee.EmployeeUsecase) *ChangeRequestUsecase {
+	return &ChangeRequestUsecase{
+		changeRequestRepo: changeRequestRepo,
+		empRepo:           empRepo,
+		authz:             authz,
+		gradeRepo:         gradeRepo,
+		echelonRepo:       echelonRepo,
+		s3Repo:            s3Repo,
+		auditRepo:         auditRepo,
+		empUsecase:        empUsecase,
+	}
+}
+
+// Submit mengajukan perubahan data diri yang tidak dapat diubah langsung lewat /me beserta dokumen pendukung
+func (uc *ChangeRequestUsecase) Submit(proposerId string, changes domain.ProfileChanges, note string, files []*multipart.FileHeader, meta domain.AuditMeta) (*domain.ProfileChangeRequest, error) {
+	proposer, err := uc.empRepo.FindByID(proposerId)
+	if err != nil || proposer == nil {
+		return nil, &utils.NotFoundError{Message: "user not found"}
+	}
+	if err := uc.validateChanges(&changes); err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, &utils.BadRequestError{Message: "at least one supporting document is required"}
+	}
+	if len(files) > changeRequestMaxDocuments {
+		return nil, &utils.BadRequestError{Message: fmt.Sprintf("cannot attach more than %d documents", changeRequestMaxDocuments)}
+	}
+	for _, file := range files {
+		if _, ok := changeRequestContentTypes[strings.ToLower(filepath.Ext(file.Filename))]; !ok {
+			return nil, &utils.BadRequestError{Message: "only .pdf, .jpg, .jpeg, or .png documents are allowed"}
+		}
+		if file.Size > changeRequestMaxDocumentSize {
+			return nil, &utils.BadRequestError{Message: fmt.Sprintf("document %s is larger than 5 MB", file.Filename)}
+		}
+	}
+	// satu employee hanya boleh memiliki satu change request yang menunggu review
+	pending, err := uc.changeRequestRepo.FindAll(domain.ChangeRequestFilter{Status: domain.ChangeRequestPending, EmployeeID: proposerId})
+	if err != nil {
+		fmt.Println("Error getting change requests:", err)
+		return nil, &utils.InternalServerError{Message: "failed to get change requests"}
+	}
+	if len(pending) > 0 {
+		return nil, &utils.ConflictError{Message: "you already have a pending change request"}
+	}
+
+	request := &domain.ProfileChangeRequest{
+		EmployeeID: proposerId,
+		Changes:    changes,
+		Note:       strings.TrimSpace(note),
+		Status:     domain.ChangeRequestPending,
+	}
+	for i, file := range files {
+		document, err := uc.uploadDocument(proposerId, i, file)
+		if err != nil {
+			return nil, err
+		}
+		request.Documents = append(request.Documents, *document)
+	}
+	if _, err := uc.changeRequestRepo.Save(request); err != nil {
+		fmt.Println("Error saving change request:", err)
+		return nil, &utils.InternalServerError{Message: "failed to submit change request"}
+	}
+	utils.RecordAudit(uc.auditRepo, meta.Entry(proposerId, domain.AuditActionCreate, domain.AuditEntityChangeRequest, request.ID), nil, request)
+	return uc.changeRequestRepo.FindByID(request.ID)
+}
+
+// GetMine mengembalikan seluruh change request milik proposer
+func (uc *ChangeRequestUsecase) GetMine(proposerId string) ([]domain.ProfileChangeRequest, error) {
+	requests, err := uc.changeRequestRepo.FindAll(domain.ChangeRequestFilter{EmployeeID: proposerId})
+	if err != nil {
+		fmt.Println("Error getting change requests:", err)
+		return nil, &utils.InternalServerError{Message: "failed to get change requests"}
+	}
+	return requests, nil
+}
+
+// GetQueue mengembalikan antrean change request untuk kepegawaian, status kosong berarti pending
+func (uc *ChangeRequestUsecase) GetQueue(principal *domain.Principal, status string) ([]domain.ProfileChangeRequest, error) {
+	if err := uc.authorize(principal); err != nil {
+		return nil, err
+	}
+	if status == "" {
+		status = domain.ChangeRequestPending
+	}
+	if status != domain.ChangeRequestPending && status != domain.ChangeRequestApproved && status != domain.ChangeRequestRejected {
+		return nil, &utils.BadRequestError{Message: "status must be one of pending, approved, rejected"}
+	}
+	requests, err := uc.changeRequestRepo.FindAll(domain.ChangeRequestFilter{Status: status})
+	if err != nil {
+		fmt.Println("Error getting change requests:", err)
+		return nil, &utils.InternalServerError{Message: "failed to get change requests"}
+	}
+	return requests, nil
+}
+
+// GetByID mengembalikan change request beserta dokumen, hanya untuk pemilik atau kepegawaian
+func (uc *ChangeRequestUsecase) GetByID(principal *domain.Principal, id int) (*domain.ProfileChangeRequest, error) {
+	request, err := uc.changeRequestRepo.FindByID(id)
+	if err != nil {
+		return nil, &utils.NotFoundError{Message: "change request not found"}
+	}
+	if request.EmployeeID != principal.UserID {
+		if err := uc.authorize(principal); err != nil {
+			return nil, err
+		}
+	}
+	return request, nil
+}
+
+// Approve menerapkan perubahan dengan validasi yang sama dengan UpdateEmployee. Change request diklaim
+// dan employee diubah dalam satu transaksi, perubahan yang tidak lolos validasi membatalkan persetujuan.
+func (uc *ChangeRequestUsecase) Approve(principal *domain.Principal, id int, comment string, meta domain.AuditMeta) (*domain.ProfileChangeRequest, error) {
+	request, err := uc.pending(principal, id)
+	if err != nil {
+		return nil, err
+	}
+	changes := request.Changes
+	update := &domain.Employee{
+		FullName:     changes.FullName,
+		PlaceOfBirth: changes.PlaceOfBirth,
+		Gender:       changes.Gender,
+		GradeID:      changes.GradeID,
+		EchelonID:    changes.EchelonID,
+	}
+	if changes.DateOfBirth != nil {
+		update.DateOfBirth = *changes.DateOfBirth
+	}
+	employee, employeeBefore, err := uc.empUsecase.PrepareUpdate(principal, request.NIP, update)
+	if err != nil {
+		return nil, err
+	}
+
+	before := *request
+	request.Status = domain.ChangeRequestApproved
+	request.ReviewedBy = &principal.UserID
+	request.ReviewComment = strings.TrimSpace(comment)
+	err = uc.changeRequestRepo.Approve(request, employee, principal.UserID)
+	if errors.Is(err, domain.ErrChangeRequestReviewed) {
+		return nil, &utils.ConflictError{Message: "change request has already been reviewed"}
+	}
+	if err != nil {
+		fmt.Println("Error approving change request:", err)
+		return nil, &utils.InternalServerError{Message: "failed to approve change request"}
+	}
+	employee.Password = "" // clear password for security
+	utils.RecordAudit(uc.auditRepo, meta.Entry(principal.UserID, domain.AuditActionUpdate, domain.AuditEntityEmployee, employee.ID), employeeBefore, employee)
+	utils.RecordAudit(uc.auditRepo, meta.Entry(principal.UserID, domain.AuditActionApprove, domain.AuditEntityChangeRequest, request.ID), &before, request)
+	return uc.changeRequestRepo.FindByID(request.ID)
+}
+
+// Reject menolak change request, alasan penolakan wajib diisi
+func (uc *ChangeRequestUsecase) Reject(principal *domain.Principal, id int, comment string, meta domain.AuditMeta) (*domain.ProfileChangeRequest, error) {
+	comment = strings.TrimSpace(comment)
+	if comment == "" {
+		return nil, &utils.BadRequestError{Message: "comment is required to reject a change request"}
+	}
+	request, err := uc.pending(principal, id)
+	if err != nil {
+		return nil, err
+	}
+	return uc.review(principal.UserID, request, domain.ChangeRequestRejected, comment, meta)
+}
+
+// ==================================================================== UTILITIES ====================================================================
+
+// authorize antrean change request hanya untuk role yang boleh mengelola employee
+func (uc *ChangeRequestUsecase) authorize(principal *domain.Principal) error {
+	return uc.authz.Authorize(principal, authorization.ChangeRequestReview, authorization.Resource{})
+}
+
+// pending memastikan reviewer berwenang, bukan pemilik change request, dan change request masih pending
+func (uc *ChangeRequestUsecase) pending(principal *domain.Principal, id int) (*domain.ProfileChangeRequest, error) {
+	if err := uc.authorize(principal); err != nil {
+		return nil, err
+	}
+	request, err := uc.changeRequestRepo.FindByID(id)
+	if err != nil {
+		return nil, &utils.NotFoundError{Message: "change request not found"}
+	}
+	if request.EmployeeID == principal.UserID {
+		return nil, &utils.UnauthorizedError{Message: "you cannot review your own change request"}
+	}
+	if request.Status != domain.ChangeRequestPending {
+		return nil, &utils.ConflictError{Message: fmt.Sprintf("change request is already %s", request.Status)}
+	}
+	return request, nil
+}
+
+func (uc *ChangeRequestUsecase) review(proposerId string, request *domain.ProfileChangeRequest, status string, comment string, meta domain.AuditMeta) (*domain.ProfileChangeRequest, error) {
+	before := *request
+	request.Status = status
+	request.ReviewedBy = &proposerId
+	request.ReviewComment = comment
+	err := uc.changeRequestRepo.Review(request)
+	if errors.Is(err, domain.ErrChangeRequestReviewed) {
+		return nil, &utils.ConflictError{Message: "change request has already been reviewed"}
+	}
+	if err != nil {
+		fmt.Println("Error reviewing change request:", err)
+		return nil, &utils.InternalServerError{Message: "failed to review change request"}
+	}
+	action := domain.AuditActionApprove
+	if status == domain.ChangeRequestRejected {
+		action = domain.AuditActionReject
+	}
+	utils.RecordAudit(uc.auditRepo, meta.Entry(proposerId, action, domain.AuditEntityChangeRequest, request.ID), &before, request)
+	return uc.changeRequestRepo.FindByID(request.ID)
+}
+
+// validateChanges memakai aturan yang sama dengan UpdateEmployee agar perubahan yang disetujui tidak diabaikan
+func (uc *ChangeRequestUsecase) validateChanges(changes *domain.ProfileChanges) error {
+	changes.FullName = strings.TrimSpace(changes.FullName)
+	changes.PlaceOfBirth = strings.TrimSpace(changes.PlaceOfBirth)
+	changes.Gender = strings.ToUpper(strings.TrimSpace(changes.Gender))
+	if changes.IsEmpty() {
+		return &utils.BadRequestError{Message: "no changes submitted"}
+	}
+	if changes.FullName != "" && (len(changes.FullName) <= 3 || !utils.IsAlpha(changes.FullName)) {
+		return &utils.BadRequestError{Message: "full name must be more than 3 letters"}
+	}
+	if changes.PlaceOfBirth != "" && (len(changes.PlaceOfBirth) <= 3 || !utils.IsAlpha(changes.PlaceOfBirth)) {
+		return &utils.BadRequestError{Message: "place of birth must be more than 3 letters"}
+	}
+	if changes.DateOfBirth != nil && changes.DateOfBirth.After(time.Now()) {
+		return &utils.BadRequestError{Message: "date of birth cannot be in the future"}
+	}
+	if changes.Gender != "" && changes.Gender != "L" && changes.Gender != "P" {
+		return &utils.BadRequestError{Message: "gender must be L or P"}
+	}
+	if changes.GradeID != 0 {
+		if _, err := uc.gradeRepo.FindByID(changes.GradeID); err != nil {
+			return &utils.NotFoundError{Message: "grade not found"}
+		}
+	}
+	if changes.EchelonID != 0 {
+		if _, err := uc.echelonRepo.FindByID(changes.EchelonID); err != nil {
+			return &utils.NotFoundError{Message: "echelon not found"}
+		}
+	}
+	return nil
+}
+
+func (uc *ChangeRequestUsecase) uploadDocument(employeeID string, index int, file *multipart.FileHeader) (*domain.ChangeRequestDocument, error) {
+	src, err := file.Open()
+	if err != nil {
+		return nil, &utils.BadRequestError{Message: "failed to open uploaded document"}
+	}
+	defer src.Close()
+	content, err := io.ReadAll(src)
+	if err != nil {
+		return nil, &utils.BadRequestError{Message: "failed to read uploaded document"}
+	}
+	ext := strings.ToLower(filepath.Ext(file.Filename))
+	contentType := changeRequestContentTypes[ext]
+	key := fmt.Sprintf("change-request/%s/%d_%d%s", employeeID, time.Now().UnixNano(), index, ext)
+	url, err := uc.s3Repo.UploadFile(key, content, contentType)
+	if err != nil {
+		return nil, &utils.InternalServerError{Message: "failed to upload document to S3"}
+	}
+	return &domain.ChangeRequestDocument{
+		FileName:    filepath.Base(file.Filename),
+		URL:         url,
+		ContentType: contentType,
+	}, nil
+}
@@ -1,19 +1,22 @@
 package usecase
 
 import (
+	"github.com/achmadnr21/emploman/internal/authorization"
 	"github.com/achmadnr21/emploman/internal/domain"
 	"github.com/achmadnr21/emploman/internal/utils"
 )
 
 type EchelonUsecase struct {
 	EchelonRepo domain.EchelonInterface
-	roleRepo    domain.RoleInterface
+	authz       *authorization.Authorizer
+	auditRepo   domain.AuditInterface
 }
 
-func NewEchelonUsecase(echelonRepo domain.EchelonInterface, roleRepo domain.RoleInterface) *EchelonUsecase {
+func NewEchelonUsecase(echelonRepo domain.EchelonInterface, authz *authorization.Authorizer, auditRepo domain.AuditInterface) *EchelonUsecase {
 	return &EchelonUsecase{
 		EchelonRepo: echelonRepo,
-		roleRepo:    roleRepo,
+		authz:       authz,
+		auditRepo:   auditRepo,
 	}
 }
 func (e *EchelonUsecase) GetAll() ([]domain.Echelon, error) {
@@ -23,23 +26,19 @@ func (e *EchelonUsecase) GetAll() ([]domain.Echelon, error) {
 	}
 	return echelons, nil
 }
-func (e *EchelonUsecase) AddEchelon(proposerId string, echelon *domain.Echelon) error {
-	// check proposer role
-	proposerRole, err := e.roleRepo.FindByUserID(proposerId)
-	if err != nil {
-		return &utils.InternalServerError{Message: "Failed to get proposer role"}
-	}
-	// check proposer can add echelon
-	if !proposerRole.CanAddEchelon {
-		return &utils.UnauthorizedError{Message: "You cannot add echelon"}
+func (e *EchelonUsecase) AddEchelon(principal *domain.Principal, echelon *domain.Echelon, meta domain.AuditMeta) error {
+	if err := e.authz.Authorize(principal, authorization.EchelonManage, authorization.Resource{}); err != nil {
+		return err
 	}
 
 	// make sure echelon code is not empty
 	if echelon.Code == "" {
 		return &utils.BadRequestError{Message: "Echelon code cannot be empty"}
 	}
-	if _, err := e.EchelonRepo.Save(echelon); err != nil {
+	saved, err := e.EchelonRepo.Save(echelon)
+	if err != nil {
 		return &utils.InternalServerError{Message: "Failed to add echelon possibly duplicate ID"}
 	}
+	utils.RecordAudit(e.auditRepo, meta.Entry(principal.UserID, domain.AuditActionCreate, domain.AuditEntityEchelon, saved.ID), nil, saved)
 	return nil
 }
@@ -1,26 +1,25 @@
 package usecase_employee
 
 import (
+	"github.com/achmadnr21/emploman/internal/authorization"
 	"github.com/achmadnr21/emploman/internal/domain"
 	"github.com/achmadnr21/emploman/internal/utils"
 )
 
-func (eu *EmployeeUsecase) authorize(proposerId string, requireAdd bool) (*domain.Employee, *domain.Role, error) {
-	user, err := eu.empRepo.FindByID(proposerId)
-	if err != nil {
-		return nil, nil, &utils.NotFoundError{Message: "user not found"}
+// findAuthorized mencari employee dengan nip lalu memeriksa action terhadap employee tersebut.
+// Permission diperiksa lebih dulu agar user tanpa hak tidak bisa menebak nip yang terdaftar.
+func (eu *EmployeeUsecase) findAuthorized(principal *domain.Principal, action authorization.Action, nip string) (*domain.Employee, error) {
+	if err := eu.authz.Authorize(principal, action, authorization.Resource{}); err != nil {
+		return nil, err
 	}
-	role, err := eu.roleRepo.FindByID(user.RoleID)
+	employee, err := eu.empRepo.FindByNIP(nip)
 	if err != nil {
-		return nil, nil, &utils.NotFoundError{Message: "user role not found"}
-	}
-	if requireAdd && !role.CanAddEmployee {
-		return nil, nil, &utils.UnauthorizedError{Message: "not authorized"}
+		return nil, &utils.NotFoundError{Message: "employee not found"}
 	}
-	if !requireAdd && !(role.CanAddEmployee || role.CanAssignEmployeeInternal || role.CanAssignEmployeeGlobal) {
-		return nil, nil, &utils.UnauthorizedError{Message: "not authorized"}
+	if err := eu.authz.Authorize(principal, action, authorization.Employee(employee.ID)); err != nil {
+		return nil, err
 	}
-	return user, role, nil
+	return employee, nil
 }
 
 func (eu *EmployeeUsecase) hasValidPath(proposerRole string, employeeRole string, roleID string) bool {
@@ -35,20 +34,14 @@ func (eu *EmployeeUsecase) hasValidPath(proposerRole string, employeeRole string
 
 // ==================================================================== UTILITIES ====================================================================
 
+// validateRolePromotion memastikan ada edge from -> to pada daftar promosi milik promoter
 func validateRolePromotion(currRole string, targetRole string, roleList []domain.RolePromotion) bool {
-	var currExists bool = false
-	var targetExists bool = false
 	for _, role := range roleList {
-		// curr exists on the list
-		if currRole == role.FromRoleID || currRole == role.ToRoleID {
-			currExists = true
+		if currRole == role.FromRoleID && targetRole == role.ToRoleID {
+			return true
 		}
-		if targetRole == role.FromRoleID || targetRole == role.ToRoleID {
-			targetExists = true
-		}
-
 	}
-	return currExists && targetExists
+	return false
 }
 func validateEmployeeInput(e *domain.Employee) error {
 
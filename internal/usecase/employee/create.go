@@ -3,17 +3,20 @@ package usecase_employee
 import (
 	"fmt"
 
+	"github.com/achmadnr21/emploman/internal/authorization"
 	"github.com/achmadnr21/emploman/internal/domain"
 	"github.com/achmadnr21/emploman/internal/utils"
 )
 
-func (eu *EmployeeUsecase) Add(proposerId string, employee *domain.Employee) (*domain.Employee, error) {
+const defaultPhotoURL = "https://s3.nevaobjects.id/emploman/pictureprofile/defaultprofile.jpg"
+
+func (eu *EmployeeUsecase) Add(principal *domain.Principal, employee *domain.Employee, meta domain.AuditMeta) (*domain.Employee, error) {
 	// check employee.RoleID should be empty
 	if employee.RoleID != "" {
 		return nil, &utils.BadRequestError{Message: "Invalid Payload"}
 	}
 	employee.RoleID = "USR"
-	if _, _, err := eu.authorize(proposerId, true); err != nil {
+	if err := eu.authz.Authorize(principal, authorization.EmployeeCreate, authorization.Resource{}); err != nil {
 		return nil, err
 	}
 	// validate employee input
@@ -35,14 +38,16 @@ func (eu *EmployeeUsecase) Add(proposerId string, employee *domain.Employee) (*d
 		return nil, &utils.InternalServerError{Message: "failed to hash password"}
 	}
 	employee.Password = hashedPassword
-	employee.PhotoURL = "https://s3.nevaobjects.id/emploman/pictureprofile/defaultprofile.jpg"
+	employee.PhotoURL = defaultPhotoURL
+	employee.StatusReason = ""
 	// save employee
-	newEmployee, err := eu.empRepo.Save(employee)
+	newEmployee, err := eu.empRepo.Save(employee, principal.UserID)
 	// newEmployee.Password = "" // clear password for security
 	if err != nil {
 		fmt.Println("Error saving employee:", err)
 		return nil, &utils.InternalServerError{Message: "failed to save employee"}
 	}
 	newEmployee.Password = ""
+	utils.RecordAudit(eu.auditRepo, meta.Entry(principal.UserID, domain.AuditActionCreate, domain.AuditEntityEmployee, newEmployee.ID), nil, newEmployee)
 	return newEmployee, nil
 }
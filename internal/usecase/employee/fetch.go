@@ -1,40 +1,89 @@
 package usecase_employee
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/achmadnr21/emploman/internal/authorization"
 	"github.com/achmadnr21/emploman/internal/domain"
 	"github.com/achmadnr21/emploman/internal/utils"
 )
 
-func (eu *EmployeeUsecase) GetAll(proposerId string) ([]domain.Employee, error) {
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// GetAll mengembalikan satu halaman employee. Jika cursor diisi maka pagination memakai keyset
+// dan filter.Page diabaikan.
+func (eu *EmployeeUsecase) GetAll(principal *domain.Principal, filter domain.EmployeeFilter, cursor string) ([]domain.Employee, *domain.PageMeta, error) {
 	// cek proposer
-	if _, _, err := eu.authorize(proposerId, false); err != nil {
-		return nil, err
+	scope, err := eu.authz.UnitScope(principal, authorization.EmployeeRead)
+	if err != nil {
+		return nil, nil, err
 	}
-	// get all employee
-	employees, err := eu.empRepo.FindAll()
+	if err := normalizeEmployeeFilter(&filter); err != nil {
+		return nil, nil, err
+	}
+	if !scope.All {
+		// tanpa unit dalam scope tidak ada employee yang bisa ditampilkan
+		if len(scope.UnitIDs) == 0 {
+			return []domain.Employee{}, &domain.PageMeta{Page: filter.Page, PageSize: filter.PageSize}, nil
+		}
+		filter.ScopeUnitIDs = scope.UnitIDs
+	}
+	if cursor != "" {
+		values, err := utils.DecodeCursor(cursor)
+		if err != nil {
+			return nil, nil, err
+		}
+		// cursor berisi nilai setiap kolom sort ditambah id
+		if len(values) != len(filter.Sort)+1 {
+			return nil, nil, &utils.BadRequestError{Message: "cursor does not match sort"}
+		}
+		filter.CursorValues = values
+		filter.Page = 0
+	}
+	// get employee page
+	employees, total, err := eu.empRepo.FindPage(filter)
 	if err != nil {
-		return nil, &utils.InternalServerError{Message: "failed to get employees"}
+		fmt.Println("Error getting employee page:", err)
+		return nil, nil, &utils.InternalServerError{Message: "failed to get employees"}
+	}
+	meta := &domain.PageMeta{
+		Page:       filter.Page,
+		PageSize:   filter.PageSize,
+		Total:      total,
+		TotalPages: (total + filter.PageSize - 1) / filter.PageSize,
+	}
+	// repository mengambil satu baris lebih untuk mengetahui ada halaman berikutnya
+	if len(employees) > filter.PageSize {
+		employees = employees[:filter.PageSize]
+		last := employees[len(employees)-1]
+		values := make([]string, 0, len(filter.Sort)+1)
+		for _, field := range filter.Sort {
+			values = append(values, employeeSortValue(&last, field.Field))
+		}
+		values = append(values, last.ID)
+		meta.NextCursor = utils.EncodeCursor(values)
 	}
 	// return employees
-	return employees, nil
+	return employees, meta, nil
 }
 
-func (eu *EmployeeUsecase) GetByNIP(proposerId string, nip string) (*domain.Employee, error) {
-	// cek proposer
-	if _, _, err := eu.authorize(proposerId, false); err != nil {
-		return nil, err
-	}
+func (eu *EmployeeUsecase) GetByNIP(principal *domain.Principal, nip string) (*domain.Employee, error) {
 	// get employee by nip
-	employee, err := eu.empRepo.FindByNIP(nip)
+	employee, err := eu.findAuthorized(principal, authorization.EmployeeRead, nip)
 	if err != nil {
-		return nil, &utils.NotFoundError{Message: "employee not found"}
+		return nil, err
 	}
 	// return employee
 	return employee, nil
 }
-func (eu *EmployeeUsecase) GetByUnit(proposerId string, unitId int) ([]domain.Employee, error) {
+func (eu *EmployeeUsecase) GetByUnit(principal *domain.Principal, unitId int) ([]domain.Employee, error) {
 	// cek proposer
-	if _, _, err := eu.authorize(proposerId, false); err != nil {
+	if err := eu.authz.Authorize(principal, authorization.EmployeeRead, authorization.Resource{}); err != nil {
 		return nil, err
 	}
 	// check wether unit exists
@@ -42,6 +91,9 @@ func (eu *EmployeeUsecase) GetByUnit(proposerId string, unitId int) ([]domain.Em
 	if err != nil {
 		return nil, &utils.NotFoundError{Message: "unit not found"}
 	}
+	if err := eu.authz.Authorize(principal, authorization.EmployeeRead, authorization.Unit(unit.ID)); err != nil {
+		return nil, err
+	}
 
 	// get employee by unit
 	employees, err := eu.empRepo.FindByUnit(unit.ID)
@@ -52,16 +104,100 @@ func (eu *EmployeeUsecase) GetByUnit(proposerId string, unitId int) ([]domain.Em
 	return employees, nil
 }
 
-func (eu *EmployeeUsecase) Search(proposerId string, input string) ([]domain.Employee, error) {
+func (eu *EmployeeUsecase) Search(principal *domain.Principal, input string) ([]domain.Employee, error) {
 	// cek proposer
-	if _, _, err := eu.authorize(proposerId, false); err != nil {
+	scope, err := eu.authz.UnitScope(principal, authorization.EmployeeRead)
+	if err != nil {
 		return nil, err
 	}
+	var scopeUnitIDs []int
+	if !scope.All {
+		// tanpa unit dalam scope tidak ada employee yang bisa ditampilkan
+		if len(scope.UnitIDs) == 0 {
+			return []domain.Employee{}, nil
+		}
+		scopeUnitIDs = scope.UnitIDs
+	}
 	// get employee by input
-	employees, err := eu.empRepo.Search(input)
+	employees, err := eu.empRepo.Search(input, scopeUnitIDs)
 	if err != nil {
 		return nil, &utils.NotFoundError{Message: "employee not found"}
 	}
 	// return employee
 	return employees, nil
 }
+
+// ==================================================================== UTILITIES ====================================================================
+
+func normalizeEmployeeFilter(filter *domain.EmployeeFilter) error {
+	if filter.PageSize <= 0 {
+		filter.PageSize = defaultPageSize
+	}
+	if filter.PageSize > maxPageSize {
+		return &utils.BadRequestError{Message: fmt.Sprintf("page_size cannot be more than %d", maxPageSize)}
+	}
+	if filter.Page <= 0 {
+		filter.Page = 1
+	}
+	filter.Gender = strings.ToUpper(filter.Gender)
+	if filter.Gender != "" && filter.Gender != "L" && filter.Gender != "P" {
+		return &utils.BadRequestError{Message: "gender must be L or P"}
+	}
+	// default listing hanya menampilkan employee aktif, "all" berarti tanpa filter status
+	if len(filter.Statuses) == 0 {
+		filter.Statuses = domain.ActiveEmploymentStatuses
+	} else if len(filter.Statuses) == 1 && filter.Statuses[0] == "all" {
+		filter.Statuses = nil
+	}
+	for _, status := range filter.Statuses {
+		if !domain.IsValidEmploymentStatus(status) {
+			return &utils.BadRequestError{Message: "invalid status " + status}
+		}
+	}
+	filter.ReligionID = strings.ToUpper(filter.ReligionID)
+	filter.RoleID = strings.ToUpper(filter.RoleID)
+	if filter.BirthYearFrom > 0 && filter.BirthYearTo > 0 && filter.BirthYearFrom > filter.BirthYearTo {
+		return &utils.BadRequestError{Message: "birth_year_from cannot be after birth_year_to"}
+	}
+	if len(filter.Sort) == 0 {
+		filter.Sort = []domain.SortField{{Field: "nip"}}
+	}
+	seen := map[string]bool{}
+	for _, field := range filter.Sort {
+		if !domain.EmployeeSortFields[field.Field] {
+			return &utils.BadRequestError{Message: "invalid sort field " + field.Field}
+		}
+		if seen[field.Field] {
+			return &utils.BadRequestError{Message: "duplicate sort field " + field.Field}
+		}
+		seen[field.Field] = true
+	}
+	return nil
+}
+
+// employeeSortValue mengembalikan nilai kolom sort dalam bentuk yang dapat di-cast kembali oleh postgres
+func employeeSortValue(e *domain.Employee, field string) string {
+	switch field {
+	case "nip":
+		return e.NIP
+	case "full_name":
+		return e.FullName
+	case "date_of_birth":
+		return e.DateOfBirth.Format("2006-01-02")
+	case "gender":
+		return e.Gender
+	case "grade_id":
+		return fmt.Sprint(e.GradeID)
+	case "echelon_id":
+		return fmt.Sprint(e.EchelonID)
+	case "religion_id":
+		return e.ReligionID
+	case "role_id":
+		return e.RoleID
+	case "created_at":
+		return e.CreatedAt.Format(time.RFC3339Nano)
+	case "modified_at":
+		return e.ModifiedAt.Format(time.RFC3339Nano)
+	}
+	return ""
+}
@@ -0,0 +1,118 @@
+package usecase_employee
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/achmadnr21/emploman/internal/authorization"
+	"github.com/achmadnr21/emploman/internal/domain"
+	"github.com/achmadnr21/emploman/internal/utils"
+)
+
+// GetHistory mengembalikan riwayat perubahan employee, perubahan terbaru lebih dulu
+func (eu *EmployeeUsecase) GetHistory(principal *domain.Principal, nip string) ([]domain.EmployeeHistory, error) {
+	employee, err := eu.findAuthorized(principal, authorization.EmployeeRead, nip)
+	if err != nil {
+		return nil, err
+	}
+	versions, err := eu.empRepo.FindVersions(employee.ID)
+	if err != nil {
+		fmt.Println("Error getting employee versions:", err)
+		return nil, &utils.InternalServerError{Message: "failed to get employee history"}
+	}
+
+	histories := make([]domain.EmployeeHistory, 0, len(versions))
+	var previous *domain.Employee
+	for i := range versions {
+		version := &versions[i]
+		changes, err := employeeChanges(previous, &version.Employee)
+		if err != nil {
+			fmt.Println("Error building employee changes:", err)
+			return nil, &utils.InternalServerError{Message: "failed to get employee history"}
+		}
+		histories = append(histories, domain.EmployeeHistory{
+			Version:       version.Version,
+			ChangedAt:     version.ValidFrom,
+			ChangedBy:     version.ChangedBy,
+			ChangedByName: version.ChangedByName,
+			Changes:       changes,
+		})
+		previous = &version.Employee
+	}
+	// dibalik agar perubahan terbaru ada di index 0
+	for i, j := 0, len(histories)-1; i < j; i, j = i+1, j-1 {
+		histories[i], histories[j] = histories[j], histories[i]
+	}
+	return histories, nil
+}
+
+// GetByNIPAsOf merekonstruksi data employee sebagaimana tercatat pada akhir tanggal asOf
+func (eu *EmployeeUsecase) GetByNIPAsOf(principal *domain.Principal, nip string, asOf time.Time) (*domain.Employee, error) {
+	employee, err := eu.findAuthorized(principal, authorization.EmployeeRead, nip)
+	if err != nil {
+		return nil, err
+	}
+	endOfDay := time.Date(asOf.Year(), asOf.Month(), asOf.Day(), 0, 0, 0, 0, asOf.Location()).AddDate(0, 0, 1)
+	if !employee.CreatedAt.Before(endOfDay) {
+		return nil, &utils.NotFoundError{Message: "employee did not exist at that date"}
+	}
+	version, err := eu.empRepo.FindVersionAsOf(employee.ID, endOfDay)
+	if err == nil {
+		return &version.Employee, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		fmt.Println("Error getting employee version:", err)
+		return nil, &utils.InternalServerError{Message: "failed to get employee history"}
+	}
+	// employee tanpa history sama sekali belum pernah berubah sejak dibuat
+	versions, err := eu.empRepo.FindVersions(employee.ID)
+	if err != nil {
+		fmt.Println("Error getting employee versions:", err)
+		return nil, &utils.InternalServerError{Message: "failed to get employee history"}
+	}
+	if len(versions) > 0 {
+		return nil, &utils.NotFoundError{Message: "no history recorded for employee at that date"}
+	}
+	employee.Password = ""
+	return employee, nil
+}
+
+// ==================================================================== UTILITIES ====================================================================
+
+// employeeChanges mengembalikan field yang berbeda antara dua versi, urut berdasarkan nama field
+func employeeChanges(before *domain.Employee, after *domain.Employee) ([]domain.EmployeeFieldChange, error) {
+	beforeJSON, afterJSON, err := utils.AuditDiff(before, after)
+	if err != nil {
+		return nil, err
+	}
+	beforeMap := map[string]interface{}{}
+	afterMap := map[string]interface{}{}
+	if len(beforeJSON) > 0 {
+		if err := json.Unmarshal(beforeJSON, &beforeMap); err != nil {
+			return nil, err
+		}
+	}
+	if len(afterJSON) > 0 {
+		if err := json.Unmarshal(afterJSON, &afterMap); err != nil {
+			return nil, err
+		}
+	}
+	fields := make([]string, 0, len(afterMap))
+	for field := range afterMap {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	changes := make([]domain.EmployeeFieldChange, 0, len(fields))
+	for _, field := range fields {
+		changes = append(changes, domain.EmployeeFieldChange{
+			Field: field,
+			From:  beforeMap[field],
+			To:    afterMap[field],
+		})
+	}
+	return changes, nil
+}
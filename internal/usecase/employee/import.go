@@ -0,0 +1,325 @@
+package usecase_employee
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/achmadnr21/emploman/internal/authorization"
+	"github.com/achmadnr21/emploman/internal/domain"
+	"github.com/achmadnr21/emploman/internal/utils"
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	maxImportRows     = 5000
+	maxImportFileSize = 10 << 20 // 10 MB
+)
+
+// importColumns adalah header yang wajib ada pada file import (urutan bebas)
+var importColumns = []string{
+	"nip", "password", "full_name", "place_of_birth", "date_of_birth", "gender",
+	"phone_number", "address", "npwp", "grade", "echelon", "religion",
+}
+
+// Import membaca file CSV/XLSX, memvalidasi setiap baris dan jika dryRun false
+// menyimpan seluruh baris dalam satu transaksi. Jika ada satu baris tidak valid
+// maka tidak ada yang disimpan.
+func (eu *EmployeeUsecase) Import(principal *domain.Principal, file *multipart.FileHeader, dryRun bool, meta domain.AuditMeta) (*domain.EmployeeImportReport, error) {
+	if err := eu.authz.Authorize(principal, authorization.EmployeeCreate, authorization.Resource{}); err != nil {
+		return nil, err
+	}
+	if file.Size > maxImportFileSize {
+		return nil, &utils.BadRequestError{Message: "file is too large, maximum 10 MB"}
+	}
+	records, err := readImportFile(file)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) < 2 {
+		return nil, &utils.BadRequestError{Message: "file has no data rows"}
+	}
+	if len(records)-1 > maxImportRows {
+		return nil, &utils.BadRequestError{Message: fmt.Sprintf("file cannot contain more than %d rows", maxImportRows)}
+	}
+	header := map[string]int{}
+	for i, column := range records[0] {
+		header[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+	for _, column := range importColumns {
+		if _, ok := header[column]; !ok {
+			return nil, &utils.BadRequestError{Message: "missing column " + column}
+		}
+	}
+
+	refs, err := eu.loadImportReferences()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &domain.EmployeeImportReport{DryRun: dryRun}
+	var employees []*domain.Employee
+	seenNIP := map[string]int{}
+	for i, record := range records[1:] {
+		rowNumber := i + 2 // baris 1 adalah header
+		cell := func(column string) string {
+			idx := header[column]
+			if idx >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[idx])
+		}
+		if isEmptyRecord(record) {
+			continue
+		}
+		employee, errs := refs.buildEmployee(cell)
+		row := domain.EmployeeImportRow{Row: rowNumber, NIP: employee.NIP}
+		if len(errs) == 0 {
+			if err := validateEmployeeInput(employee); err != nil {
+				errs = append(errs, errorMessage(err))
+			}
+		}
+		if employee.NIP != "" {
+			if firstRow, ok := seenNIP[employee.NIP]; ok {
+				errs = append(errs, fmt.Sprintf("nip duplicated with row %d", firstRow))
+			} else {
+				seenNIP[employee.NIP] = rowNumber
+				existing, err := eu.empRepo.FindByNIP(employee.NIP)
+				if err != nil && !errors.Is(err, sql.ErrNoRows) {
+					fmt.Println("Error finding employee by nip:", err)
+					return nil, &utils.InternalServerError{Message: "failed to check employee"}
+				}
+				if existing != nil {
+					errs = append(errs, "employee already exists")
+				}
+			}
+		}
+		row.Errors = errs
+		row.Valid = len(errs) == 0
+		if row.Valid {
+			report.ValidRows++
+			employees = append(employees, employee)
+		} else {
+			report.InvalidRows++
+		}
+		report.Rows = append(report.Rows, row)
+	}
+	report.TotalRows = report.ValidRows + report.InvalidRows
+	if report.TotalRows == 0 {
+		return nil, &utils.BadRequestError{Message: "file has no data rows"}
+	}
+	if dryRun || report.InvalidRows > 0 {
+		return report, nil
+	}
+
+	if err := hashPasswords(employees); err != nil {
+		return nil, &utils.InternalServerError{Message: "failed to hash password"}
+	}
+	if err := eu.empRepo.SaveBatch(employees, principal.UserID); err != nil {
+		fmt.Println("Error importing employees:", err)
+		return nil, &utils.InternalServerError{Message: "failed to import employees"}
+	}
+	report.Committed = true
+	nips := make([]string, len(employees))
+	for i, employee := range employees {
+		nips[i] = employee.NIP
+	}
+	utils.RecordAudit(eu.auditRepo, meta.Entry(principal.UserID, domain.AuditActionImport, domain.AuditEntityEmployee, file.Filename), nil,
+		map[string]interface{}{"total": len(employees), "nips": nips})
+	return report, nil
+}
+
+// ==================================================================== UTILITIES ====================================================================
+
+// importReferences memetakan kode grade/echelon dan id/nama religion ke id
+type importReferences struct {
+	grades    map[string]int
+	echelons  map[string]int
+	religions map[string]string
+}
+
+func (eu *EmployeeUsecase) loadImportReferences() (*importReferences, error) {
+	refs := &importReferences{
+		grades:    map[string]int{},
+		echelons:  map[string]int{},
+		religions: map[string]string{},
+	}
+	grades, err := eu.gradeRepo.FindAll()
+	if err != nil {
+		return nil, &utils.InternalServerError{Message: "failed to get grades"}
+	}
+	for _, grade := range grades {
+		refs.grades[strings.ToUpper(grade.Code)] = grade.ID
+	}
+	echelons, err := eu.echelonRepo.FindAll()
+	if err != nil {
+		return nil, &utils.InternalServerError{Message: "failed to get echelons"}
+	}
+	for _, echelon := range echelons {
+		refs.echelons[strings.ToUpper(echelon.Code)] = echelon.ID
+	}
+	religions, err := eu.religionRepo.FindAll()
+	if err != nil {
+		return nil, &utils.InternalServerError{Message: "failed to get religions"}
+	}
+	for _, religion := range religions {
+		refs.religions[strings.ToUpper(religion.ID)] = religion.ID
+		refs.religions[strings.ToUpper(religion.Name)] = religion.ID
+	}
+	return refs, nil
+}
+
+func (refs *importReferences) buildEmployee(cell func(string) string) (*domain.Employee, []string) {
+	var errs []string
+	employee := &domain.Employee{
+		RoleID:       "USR",
+		NIP:          cell("nip"),
+		Password:     cell("password"),
+		FullName:     cell("full_name"),
+		PlaceOfBirth: cell("place_of_birth"),
+		Gender:       strings.ToUpper(cell("gender")),
+		PhoneNumber:  cell("phone_number"),
+		Address:      cell("address"),
+		NPWP:         cell("npwp"),
+		PhotoURL:     defaultPhotoURL,
+	}
+	if value := cell("date_of_birth"); value != "" {
+		dateOfBirth, err := parseImportDate(value)
+		if err != nil {
+			errs = append(errs, "date_of_birth must be a date cell or use format YYYY-MM-DD")
+		}
+		employee.DateOfBirth = dateOfBirth
+	}
+	if id, ok := refs.grades[strings.ToUpper(cell("grade"))]; ok {
+		employee.GradeID = id
+	} else {
+		errs = append(errs, "unknown grade "+cell("grade"))
+	}
+	if id, ok := refs.echelons[strings.ToUpper(cell("echelon"))]; ok {
+		employee.EchelonID = id
+	} else {
+		errs = append(errs, "unknown echelon "+cell("echelon"))
+	}
+	if id, ok := refs.religions[strings.ToUpper(cell("religion"))]; ok {
+		employee.ReligionID = id
+	} else {
+		errs = append(errs, "unknown religion "+cell("religion"))
+	}
+	return employee, errs
+}
+
+func readImportFile(file *multipart.FileHeader) ([][]string, error) {
+	src, err := file.Open()
+	if err != nil {
+		return nil, &utils.BadRequestError{Message: "failed to open uploaded file"}
+	}
+	defer src.Close()
+
+	switch strings.ToLower(filepath.Ext(file.Filename)) {
+	case ".csv":
+		reader := csv.NewReader(src)
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		var records [][]string
+		for {
+			record, err := reader.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, &utils.BadRequestError{Message: "invalid csv file: " + err.Error()}
+			}
+			records = append(records, record)
+		}
+		return records, nil
+	case ".xlsx":
+		workbook, err := excelize.OpenReader(src)
+		if err != nil {
+			return nil, &utils.BadRequestError{Message: "invalid xlsx file"}
+		}
+		defer workbook.Close()
+		// hanya sheet pertama yang dibaca
+		sheets := workbook.GetSheetList()
+		if len(sheets) == 0 {
+			return nil, &utils.BadRequestError{Message: "xlsx file has no sheet"}
+		}
+		// nilai mentah agar tanggal tidak mengikuti format tampilan Excel, cell tanggal dibaca sebagai serial date
+		records, err := workbook.GetRows(sheets[0], excelize.Options{RawCellValue: true})
+		if err != nil {
+			return nil, &utils.BadRequestError{Message: "failed to read xlsx sheet"}
+		}
+		return records, nil
+	}
+	return nil, &utils.BadRequestError{Message: "only .csv or .xlsx files are allowed"}
+}
+
+// hashPasswords melakukan bcrypt secara paralel karena cost 12 cukup lambat untuk ratusan baris
+func hashPasswords(employees []*domain.Employee) error {
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var firstErr error
+	sem := make(chan struct{}, runtime.NumCPU())
+	for _, employee := range employees {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(e *domain.Employee) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			hashed, err := utils.HashPassword(e.Password)
+			if err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+				return
+			}
+			e.Password = hashed
+		}(employee)
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// parseImportDate menerima tanggal YYYY-MM-DD atau serial date dari cell tanggal Excel
+func parseImportDate(value string) (time.Time, error) {
+	date, err := time.Parse("2006-01-02", value)
+	if err == nil {
+		return date, nil
+	}
+	serial, serialErr := strconv.ParseFloat(value, 64)
+	if serialErr != nil || serial < 1 {
+		return time.Time{}, err
+	}
+	date, serialErr = excelize.ExcelDateToTime(serial, false)
+	if serialErr != nil {
+		return time.Time{}, err
+	}
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC), nil
+}
+
+func isEmptyRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// errorMessage mengambil pesan error tanpa prefix tipe error (misal "Bad Request: ")
+func errorMessage(err error) string {
+	if badRequest, ok := err.(*utils.BadRequestError); ok {
+		return badRequest.Message
+	}
+	return err.Error()
+}
@@ -9,30 +9,21 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/achmadnr21/emploman/internal/authorization"
+	"github.com/achmadnr21/emploman/internal/domain"
 	"github.com/achmadnr21/emploman/internal/utils"
 	"github.com/disintegration/imaging"
 )
 
-func (eu *EmployeeUsecase) UploadPP(proposerId string, nip string, file *multipart.FileHeader) (string, error) {
-	// cek proposer
-	proposer, err := eu.empRepo.FindByID(proposerId)
-	if err != nil {
-		return "", &utils.NotFoundError{Message: "user not found"}
-	}
-
-	// cek role
-	pr, err := eu.roleRepo.FindByID(proposer.RoleID)
-	if err != nil {
-		return "", &utils.NotFoundError{Message: "user role not found"}
-	}
-
+func (eu *EmployeeUsecase) UploadPP(principal *domain.Principal, nip string, file *multipart.FileHeader, meta domain.AuditMeta) (string, error) {
 	// dapatkan employee dengan nip
 	employee, err := eu.empRepo.FindByNIP(nip)
 	if err != nil {
 		return "", &utils.NotFoundError{Message: "employee not found"}
 	}
-	if employee.ID != proposer.ID && !(pr.CanAddEmployee) {
-		return "", &utils.UnauthorizedError{Message: "user not authorized"}
+	// foto sendiri selalu boleh, foto employee lain hanya oleh pengelola employee
+	if err := eu.authz.Authorize(principal, authorization.EmployeePhoto, authorization.Employee(employee.ID)); err != nil {
+		return "", err
 	}
 
 	// Buka file dari form
@@ -73,11 +64,13 @@ func (eu *EmployeeUsecase) UploadPP(proposerId string, nip string, file *multipa
 	}
 
 	// Update PhotoURL
+	before := *employee
 	employee.PhotoURL = url
-	newEmp, err := eu.empRepo.Update(employee)
+	newEmp, err := eu.empRepo.Update(employee, principal.UserID)
 	if err != nil {
 		return "", &utils.InternalServerError{Message: "failed to update employee"}
 	}
+	utils.RecordAudit(eu.auditRepo, meta.Entry(principal.UserID, domain.AuditActionPhoto, domain.AuditEntityEmployee, newEmp.ID), &before, newEmp)
 
 	return newEmp.PhotoURL, nil
 }
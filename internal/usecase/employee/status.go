@@ -0,0 +1,96 @@
+package usecase_employee
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/achmadnr21/emploman/internal/authorization"
+	"github.com/achmadnr21/emploman/internal/domain"
+	"github.com/achmadnr21/emploman/internal/utils"
+)
+
+// ChangeStatus mencatat perubahan employment status (cuti, pensiun, resign, diberhentikan, meninggal)
+func (eu *EmployeeUsecase) ChangeStatus(principal *domain.Principal, nip string, status string, effectiveDate time.Time, reason string, meta domain.AuditMeta) (*domain.EmployeeStatusHistory, error) {
+	if err := eu.authz.Authorize(principal, authorization.EmployeeStatus, authorization.Resource{}); err != nil {
+		return nil, err
+	}
+	status = strings.ToLower(status)
+	if !domain.IsValidEmploymentStatus(status) {
+		return nil, &utils.BadRequestError{Message: "status is invalid"}
+	}
+	if len(strings.TrimSpace(reason)) < 5 {
+		return nil, &utils.BadRequestError{Message: "reason is required and must be at least 5 characters"}
+	}
+	employee, err := eu.empRepo.FindByNIP(nip)
+	if err != nil {
+		return nil, &utils.NotFoundError{Message: "employee not found"}
+	}
+	if employee.ID == principal.UserID {
+		return nil, &utils.UnauthorizedError{Message: "cannot change your own employment status"}
+	}
+	if employee.EmploymentStatus == status {
+		return nil, &utils.BadRequestError{Message: "employee already has this status"}
+	}
+	if effectiveDate.IsZero() {
+		effectiveDate = time.Now()
+	}
+	return eu.recordStatus(principal.UserID, employee.ID, status, effectiveDate, reason, meta)
+}
+
+// RestoreStatus membatalkan perubahan status terakhir, mengembalikan employee ke status sebelumnya
+func (eu *EmployeeUsecase) RestoreStatus(principal *domain.Principal, nip string, reason string, meta domain.AuditMeta) (*domain.EmployeeStatusHistory, error) {
+	if err := eu.authz.Authorize(principal, authorization.EmployeeStatus, authorization.Resource{}); err != nil {
+		return nil, err
+	}
+	if len(strings.TrimSpace(reason)) < 5 {
+		return nil, &utils.BadRequestError{Message: "reason is required and must be at least 5 characters"}
+	}
+	employee, err := eu.empRepo.FindByNIP(nip)
+	if err != nil {
+		return nil, &utils.NotFoundError{Message: "employee not found"}
+	}
+	if employee.ID == principal.UserID {
+		return nil, &utils.UnauthorizedError{Message: "cannot change your own employment status"}
+	}
+	histories, err := eu.empRepo.FindStatusHistory(employee.ID)
+	if err != nil {
+		return nil, &utils.InternalServerError{Message: "failed to get status history"}
+	}
+	if len(histories) == 0 {
+		return nil, &utils.BadRequestError{Message: "no status change to restore"}
+	}
+	// history terbaru ada di index 0
+	last := histories[0]
+	return eu.recordStatus(principal.UserID, employee.ID, last.FromStatus, time.Now(), "restore: "+reason, meta)
+}
+
+func (eu *EmployeeUsecase) GetStatusHistory(principal *domain.Principal, nip string) ([]domain.EmployeeStatusHistory, error) {
+	employee, err := eu.findAuthorized(principal, authorization.EmployeeRead, nip)
+	if err != nil {
+		return nil, err
+	}
+	histories, err := eu.empRepo.FindStatusHistory(employee.ID)
+	if err != nil {
+		return nil, &utils.InternalServerError{Message: "failed to get status history"}
+	}
+	return histories, nil
+}
+
+func (eu *EmployeeUsecase) recordStatus(proposerId string, employeeId string, status string, effectiveDate time.Time, reason string, meta domain.AuditMeta) (*domain.EmployeeStatusHistory, error) {
+	history := &domain.EmployeeStatusHistory{
+		EmployeeID:    employeeId,
+		ToStatus:      status,
+		EffectiveDate: effectiveDate,
+		Reason:        reason,
+		ChangedBy:     proposerId,
+	}
+	if err := eu.empRepo.UpdateStatus(history); err != nil {
+		fmt.Println("Error updating employee status:", err)
+		return nil, &utils.InternalServerError{Message: "failed to update employee status"}
+	}
+	utils.RecordAudit(eu.auditRepo, meta.Entry(proposerId, domain.AuditActionStatus, domain.AuditEntityEmployee, employeeId),
+		map[string]interface{}{"employment_status": history.FromStatus},
+		map[string]interface{}{"employment_status": history.ToStatus, "status_effective_date": history.EffectiveDate, "status_reason": history.Reason})
+	return history, nil
+}
@@ -1,115 +1,170 @@
 package usecase_employee
 
 import (
+	"strings"
+
+	"github.com/achmadnr21/emploman/internal/authorization"
 	"github.com/achmadnr21/emploman/internal/domain"
 	"github.com/achmadnr21/emploman/internal/utils"
 )
 
-func (eu *EmployeeUsecase) UpdateEmployee(proposerId string, nip string, employee *domain.Employee) (*domain.Employee, error) {
-	// cek proposer
-	proposer, err := eu.empRepo.FindByID(proposerId)
-	if err != nil || proposer == nil {
-		return nil, &utils.NotFoundError{Message: "user not found"}
-	}
-	// cek role
-	pr, err := eu.roleRepo.FindByID(proposer.RoleID)
+func (eu *EmployeeUsecase) UpdateEmployee(principal *domain.Principal, nip string, employee *domain.Employee, meta domain.AuditMeta) (*domain.Employee, error) {
+	// assign internal hanya boleh mengubah employee di unit scope-nya
+	existingEmployee, err := eu.findAuthorized(principal, authorization.EmployeeUpdate, nip)
 	if err != nil {
-		return nil, &utils.NotFoundError{Message: "user role not found"}
-	}
-	// check if user is authorized to update employee or is self update are allowed
-	if !(pr.CanAddEmployee || pr.CanAssignEmployeeInternal || pr.CanAssignEmployeeGlobal) {
-		return nil, &utils.UnauthorizedError{Message: "user not authorized"}
-	}
-	// check wether employee already exists
-	existingEmployee, err := eu.empRepo.FindByNIP(nip)
-	if err != nil || existingEmployee == nil {
-		return nil, &utils.InternalServerError{Message: "failed to check employee or employee not found"}
+		return nil, err
 	}
+	before := *existingEmployee
 
 	if employee.RoleID != "" {
 		// unauthorized in this endpoint
 		return nil, &utils.UnauthorizedError{Message: "user not authorized"}
 	}
 
-	// full name checking
-	if employee.FullName != "" && len(employee.FullName) > 3 && utils.IsAlpha(employee.FullName) {
-		existingEmployee.FullName = employee.FullName
-	}
-	// place of birth checking
-	if employee.PlaceOfBirth != "" && len(employee.PlaceOfBirth) > 3 && utils.IsAlpha(employee.PlaceOfBirth) {
-		existingEmployee.PlaceOfBirth = employee.PlaceOfBirth
-	}
-	// date of birth checking jika is Zero atau tidak ada isinya
-	if !employee.DateOfBirth.IsZero() {
-		existingEmployee.DateOfBirth = employee.DateOfBirth
-	}
-	// Gender checking
-	if employee.Gender != "" && len(employee.Gender) == 1 {
-		existingEmployee.Gender = employee.Gender
-	}
-	// phone number checking
-	if employee.PhoneNumber != "" && len(employee.PhoneNumber) > 6 && utils.IsNumeric(employee.PhoneNumber) {
-		existingEmployee.PhoneNumber = employee.PhoneNumber
-	}
-	// address checking
-	if employee.Address != "" && len(employee.Address) > 6 {
-		existingEmployee.Address = employee.Address
-	}
-	// NPWP checking
-	if employee.NPWP != "" && len(employee.NPWP) == 16 {
-		existingEmployee.NPWP = employee.NPWP
-	}
-	// grade id checking
-	if employee.GradeID > 0 {
-		existingEmployee.GradeID = employee.GradeID
-	}
-	// religion id checking
-	if employee.ReligionID != "" && len(employee.ReligionID) == 3 {
-		existingEmployee.ReligionID = employee.ReligionID
-	}
-	// echelon id checking
-	if employee.EchelonID > 0 {
-		existingEmployee.EchelonID = employee.EchelonID
-	}
+	mergeEmployee(existingEmployee, employee)
 
-	newEmp, err := eu.empRepo.Update(existingEmployee)
+	newEmp, err := eu.empRepo.Update(existingEmployee, principal.UserID)
 	if err != nil {
 		return nil, &utils.InternalServerError{Message: "failed to update employee"}
 	}
 	newEmp.Password = "" // clear password for security
+	utils.RecordAudit(eu.auditRepo, meta.Entry(principal.UserID, domain.AuditActionUpdate, domain.AuditEntityEmployee, newEmp.ID), &before, newEmp)
 	return newEmp, nil
 }
 
-func (eu *EmployeeUsecase) Promote(proposerId string, nip string, roleID string) (*domain.Employee, error) {
-	// get proposer
-	proposer, err := eu.empRepo.FindByID(proposerId)
-	proposerRole := proposer.RoleID
+// PrepareUpdate menjalankan otorisasi dan validasi UpdateEmployee tanpa menyimpan, dipakai jika perubahan
+// disimpan bersama data lain dalam satu transaksi. Berbeda dengan UpdateEmployee, field yang tidak lolos
+// validasi ditolak alih-alih diabaikan. Mengembalikan employee setelah perubahan dan sebelum perubahan.
+func (eu *EmployeeUsecase) PrepareUpdate(principal *domain.Principal, nip string, employee *domain.Employee) (*domain.Employee, *domain.Employee, error) {
+	existingEmployee, err := eu.findAuthorized(principal, authorization.EmployeeUpdate, nip)
+	if err != nil {
+		return nil, nil, err
+	}
+	before := *existingEmployee
+	if employee.RoleID != "" {
+		return nil, nil, &utils.UnauthorizedError{Message: "user not authorized"}
+	}
+	if skipped := mergeEmployee(existingEmployee, employee); len(skipped) > 0 {
+		return nil, nil, &utils.BadRequestError{Message: "invalid value for " + strings.Join(skipped, ", ")}
+	}
+	return existingEmployee, &before, nil
+}
+
+func (eu *EmployeeUsecase) Promote(principal *domain.Principal, nip string, roleID string, meta domain.AuditMeta) (*domain.Employee, error) {
+	// get proposer role
+	proposerRole, err := utils.PrincipalRole(eu.roleRepo, principal)
 	if err != nil {
-		return nil, &utils.NotFoundError{Message: "user not found"}
+		return nil, &utils.NotFoundError{Message: "user role not found"}
 	}
 	// get employee
 	employee, err := eu.empRepo.FindByNIP(nip)
-	employeeRole := employee.RoleID
 	if err != nil {
 		return nil, &utils.NotFoundError{Message: "employee not found"}
 	}
-	// Jika proposerId == employeeId maka tidak boleh!
-	if proposer.ID == employee.ID {
+	employeeRole := employee.RoleID
+	// proposer tidak boleh mempromosikan dirinya sendiri
+	if principal.UserID == employee.ID {
 		return nil, &utils.UnauthorizedError{Message: "user not authorized"}
 	}
 	if employee.RoleID == roleID {
 		return nil, &utils.BadRequestError{Message: "employee already has this role"}
 	}
-	isValid := eu.hasValidPath(proposerRole, employeeRole, roleID)
+	isValid := eu.hasValidPath(proposerRole.ID, employeeRole, roleID)
 	if !isValid {
 		return nil, &utils.UnauthorizedError{Message: "user not authorized"}
 	}
+	before := *employee
 	employee.RoleID = roleID
 	// save employee
-	newEmployee, err := eu.empRepo.Update(employee)
+	newEmployee, err := eu.empRepo.Update(employee, principal.UserID)
 	if err != nil {
 		return nil, &utils.BadRequestError{Message: "role not found"}
 	}
+	eu.roleRepo.EmployeeRoleChanged(newEmployee.ID)
 	newEmployee.Password = "" // clear password for security
+	utils.RecordAudit(eu.auditRepo, meta.Entry(principal.UserID, domain.AuditActionPromote, domain.AuditEntityEmployee, newEmployee.ID), &before, newEmployee)
 	return newEmployee, nil
 }
+
+// mergeEmployee menyalin field employee yang diisi dan valid ke existing,
+// lalu mengembalikan nama field yang diisi tetapi diabaikan karena tidak valid
+func mergeEmployee(existing *domain.Employee, employee *domain.Employee) []string {
+	skipped := []string{}
+	apply := func(field string, filled bool, valid bool, set func()) {
+		if !filled {
+			return
+		}
+		if !valid {
+			skipped = append(skipped, field)
+			return
+		}
+		set()
+	}
+	apply("full_name", employee.FullName != "", len(employee.FullName) > 3 && utils.IsAlpha(employee.FullName), func() {
+		existing.FullName = employee.FullName
+	})
+	apply("place_of_birth", employee.PlaceOfBirth != "", len(employee.PlaceOfBirth) > 3 && utils.IsAlpha(employee.PlaceOfBirth), func() {
+		existing.PlaceOfBirth = employee.PlaceOfBirth
+	})
+	// date of birth hanya diubah jika diisi
+	apply("date_of_birth", !employee.DateOfBirth.IsZero(), true, func() {
+		existing.DateOfBirth = employee.DateOfBirth
+	})
+	apply("gender", employee.Gender != "", len(employee.Gender) == 1, func() {
+		existing.Gender = employee.Gender
+	})
+	apply("phone_number", employee.PhoneNumber != "", len(employee.PhoneNumber) > 6 && utils.IsNumeric(employee.PhoneNumber), func() {
+		existing.PhoneNumber = employee.PhoneNumber
+	})
+	apply("address", employee.Address != "", len(employee.Address) > 6, func() {
+		existing.Address = employee.Address
+	})
+	apply("npwp", employee.NPWP != "", len(employee.NPWP) == 16, func() {
+		existing.NPWP = employee.NPWP
+	})
+	apply("grade_id", employee.GradeID != 0, employee.GradeID > 0, func() {
+		existing.GradeID = employee.GradeID
+	})
+	apply("religion_id", employee.ReligionID != "", len(employee.ReligionID) == 3, func() {
+		existing.ReligionID = employee.ReligionID
+	})
+	apply("echelon_id", employee.EchelonID != 0, employee.EchelonID > 0, func() {
+		existing.EchelonID = employee.EchelonID
+	})
+	return skipped
+}
+
+// PromoteOptions mengembalikan daftar role yang dapat diberikan proposer kepada employee
+func (eu *EmployeeUsecase) PromoteOptions(principal *domain.Principal, nip string) ([]domain.Role, error) {
+	proposerRole, err := utils.PrincipalRole(eu.roleRepo, principal)
+	if err != nil {
+		return nil, &utils.NotFoundError{Message: "user role not found"}
+	}
+	// sama seperti Promote, hanya role yang memiliki jalur promosi yang boleh melihat pilihan role
+	promoteList, err := eu.roleRepo.FindPromoteRole(proposerRole.ID)
+	if err != nil {
+		return nil, &utils.InternalServerError{Message: "failed to get role promotions"}
+	}
+	if len(promoteList) == 0 {
+		return nil, &utils.UnauthorizedError{Message: "user not authorized"}
+	}
+	employee, err := eu.empRepo.FindByNIP(nip)
+	if err != nil {
+		return nil, &utils.NotFoundError{Message: "employee not found"}
+	}
+	roles := []domain.Role{}
+	if principal.UserID == employee.ID {
+		return roles, nil
+	}
+	for _, promotion := range promoteList {
+		if promotion.FromRoleID != employee.RoleID {
+			continue
+		}
+		role, err := eu.roleRepo.FindByID(promotion.ToRoleID)
+		if err != nil {
+			continue
+		}
+		roles = append(roles, *role)
+	}
+	return roles, nil
+}
@@ -1,21 +1,32 @@
 package usecase_employee
 
 import (
+	"github.com/achmadnr21/emploman/internal/authorization"
 	"github.com/achmadnr21/emploman/internal/domain"
 )
 
 type EmployeeUsecase struct {
-	empRepo  domain.EmployeeInterface
-	roleRepo domain.RoleInterface
-	unitRepo domain.UnitInterface
-	s3Repo   domain.S3Interface
+	empRepo      domain.EmployeeInterface
+	roleRepo     domain.RoleInterface
+	unitRepo     domain.UnitInterface
+	s3Repo       domain.S3Interface
+	gradeRepo    domain.GradeInterface
+	echelonRepo  domain.EchelonInterface
+	religionRepo domain.ReligionInterface
+	auditRepo    domain.AuditInterface
+	authz        *authorization.Authorizer
 }
 
-func NewEmployeeUsecase(empRepo domain.EmployeeInterface, roleRepo domain.RoleInterface, unitRepo domain.UnitInterface, s3Repo domain.S3Interface) *EmployeeUsecase {
+func NewEmployeeUsecase(empRepo domain.EmployeeInterface, roleRepo domain.RoleInterface, unitRepo domain.UnitInterface, s3Repo domain.S3Interface, gradeRepo domain.GradeInterface, echelonRepo domain.EchelonInterface, religionRepo domain.ReligionInterface, auditRepo domain.AuditInterface, authz *authorization.Authorizer) *EmployeeUsecase {
 	return &EmployeeUsecase{
-		empRepo:  empRepo,
-		roleRepo: roleRepo,
-		unitRepo: unitRepo,
-		s3Repo:   s3Repo,
+		empRepo:      empRepo,
+		roleRepo:     roleRepo,
+		unitRepo:     unitRepo,
+		s3Repo:       s3Repo,
+		gradeRepo:    gradeRepo,
+		echelonRepo:  echelonRepo,
+		religionRepo: religionRepo,
+		auditRepo:    auditRepo,
+		authz:        authz,
 	}
 }
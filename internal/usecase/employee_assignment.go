@@ -1,8 +1,12 @@
 package usecase
 
 import (
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/achmadnr21/emploman/internal/authorization"
 	"github.com/achmadnr21/emploman/internal/domain"
 	"github.com/achmadnr21/emploman/internal/utils"
 )
@@ -10,55 +14,51 @@ import (
 type EmployeeAssignmentUsecase struct {
 	empAssignRepo domain.EmployeeAssignmentInterface
 	empRepo       domain.EmployeeInterface
-	roleRepo      domain.RoleInterface
 	unitRepo      domain.UnitInterface
 	positionRepo  domain.PositionInterface
+	auditRepo     domain.AuditInterface
+	authz         *authorization.Authorizer
 }
 
-func NewEmployeeAssignmentUsecase(empAssignRepo domain.EmployeeAssignmentInterface, empRepo domain.EmployeeInterface, roleRepo domain.RoleInterface, unitRepo domain.UnitInterface, positionRepo domain.PositionInterface) *EmployeeAssignmentUsecase {
+func NewEmployeeAssignmentUsecase(empAssignRepo domain.EmployeeAssignmentInterface, empRepo domain.EmployeeInterface, unitRepo domain.UnitInterface, positionRepo domain.PositionInterface, auditRepo domain.AuditInterface, authz *authorization.Authorizer) *EmployeeAssignmentUsecase {
 	return &EmployeeAssignmentUsecase{
 		empAssignRepo: empAssignRepo,
 		empRepo:       empRepo,
-		roleRepo:      roleRepo,
 		unitRepo:      unitRepo,
 		positionRepo:  positionRepo,
+		auditRepo:     auditRepo,
+		authz:         authz,
 	}
 }
 
-func (e *EmployeeAssignmentUsecase) GetAll(proposerId string) ([]domain.EmployeeAssignmentResponse, error) {
-	// check proposer role
-	proposerRole, err := e.roleRepo.FindByUserID(proposerId)
+func (e *EmployeeAssignmentUsecase) GetAll(principal *domain.Principal) ([]domain.EmployeeAssignmentResponse, error) {
+	// assign global melihat seluruh assignment, assign internal hanya unit dalam scope
+	scope, err := e.authz.UnitScope(principal, authorization.AssignmentRead)
 	if err != nil {
-		return nil, &utils.UnauthorizedError{Message: "Failed to get user role"}
-	}
-	// check proposer can assign employee global or internal
-	if !proposerRole.CanAssignEmployeeGlobal && !proposerRole.CanAssignEmployeeInternal {
-		return nil, &utils.UnauthorizedError{}
+		return nil, err
 	}
 	empAssignments, err := e.empAssignRepo.FindAll()
 	if err != nil {
 		fmt.Println("Error in GetAll: ", err)
 		return nil, &utils.InternalServerError{Message: "Failed to get employee assignments"}
 	}
-	return empAssignments, nil
-}
-
-func (e *EmployeeAssignmentUsecase) GetAssignmentByAllID(proposerId string, employeeID string, unitID int, positionID int) (*domain.EmployeeAssignmentResponse, error) {
-	// check proposer role
-	proposerRole, err := e.roleRepo.FindByUserID(proposerId)
-	if err != nil {
-		return nil, err
+	if scope.All {
+		return empAssignments, nil
 	}
-	// check proposer unit id via EmployeeAssignment table
-	proposerAssign, err := e.empAssignRepo.FindByEmployeeID(proposerId)
-	if err != nil {
-		return nil, err
+	// assign internal hanya melihat assignment pada unit dalam scope
+	scoped := []domain.EmployeeAssignmentResponse{}
+	for _, assignment := range empAssignments {
+		if scope.Contains(assignment.UnitID) {
+			scoped = append(scoped, assignment)
+		}
 	}
+	return scoped, nil
+}
 
-	if !proposerRole.CanAssignEmployeeGlobal {
-		if !proposerRole.CanAssignEmployeeInternal || proposerAssign.UnitID != unitID {
-			return nil, &utils.UnauthorizedError{}
-		}
+func (e *EmployeeAssignmentUsecase) GetAssignmentByAllID(principal *domain.Principal, employeeID string, unitID int, positionID int) (*domain.EmployeeAssignmentResponse, error) {
+	// check unit berada dalam scope proposer
+	if err := e.authz.Authorize(principal, authorization.AssignmentRead, authorization.Unit(unitID)); err != nil {
+		return nil, err
 	}
 
 	assignmentGranted, err := e.empAssignRepo.FindByID(employeeID, unitID, positionID)
@@ -69,22 +69,10 @@ func (e *EmployeeAssignmentUsecase) GetAssignmentByAllID(proposerId string, empl
 
 }
 
-func (e *EmployeeAssignmentUsecase) AssignEmployee(proposerId string, assignStatement *domain.EmployeeAssignment) error {
-	// check proposer role
-	proposerRole, err := e.roleRepo.FindByUserID(proposerId)
-	if err != nil {
-		return &utils.UnauthorizedError{Message: "Failed to get user role"}
-	}
-	// check proposer unit id via EmployeeAssignment table
-	proposerAssign, err := e.empAssignRepo.FindByEmployeeID(proposerId)
-	if err != nil {
-		return &utils.UnauthorizedError{Message: "Failed to get user role"}
-	}
-
-	if !proposerRole.CanAssignEmployeeGlobal {
-		if !proposerRole.CanAssignEmployeeInternal || proposerAssign.UnitID != assignStatement.UnitID {
-			return &utils.UnauthorizedError{}
-		}
+func (e *EmployeeAssignmentUsecase) AssignEmployee(principal *domain.Principal, assignStatement *domain.EmployeeAssignment, meta domain.AuditMeta) error {
+	// check unit tujuan berada dalam scope proposer
+	if err := e.authz.Authorize(principal, authorization.AssignmentManage, authorization.Unit(assignStatement.UnitID)); err != nil {
+		return err
 	}
 	// check existance of employee
 	emp, _ := e.empRepo.FindByID(assignStatement.EmployeeID)
@@ -101,59 +89,114 @@ func (e *EmployeeAssignmentUsecase) AssignEmployee(proposerId string, assignStat
 	if position == nil {
 		return &utils.NotFoundError{Message: "Position not found"}
 	}
-	assignStatement.IsActive = true
+	// validasi periode, end_date kosong berarti tanpa batas
+	startDate := utils.DateOnly(assignStatement.StartDate.Time)
+	assignStatement.StartDate = domain.NewDate(startDate)
+	var endDate *time.Time
+	if assignStatement.EndDate != nil && !assignStatement.EndDate.IsZero() {
+		end := utils.DateOnly(assignStatement.EndDate.Time)
+		if end.Before(startDate) {
+			return &utils.BadRequestError{Message: "end_date cannot be before start_date"}
+		}
+		endDate = &end
+		assignStatement.EndDate = &domain.Date{Time: end}
+	} else {
+		assignStatement.EndDate = nil
+	}
+	assignStatement.Reason = strings.ToLower(strings.TrimSpace(assignStatement.Reason))
+	if !domain.IsValidAssignmentReason(assignStatement.Reason) {
+		return &utils.BadRequestError{Message: "reason must be one of mutasi, promotion, secondment"}
+	}
+	assignStatement.DecreeNumber = strings.TrimSpace(assignStatement.DecreeNumber)
+	if assignStatement.DecreeNumber == "" {
+		return &utils.BadRequestError{Message: "decree_number is required"}
+	}
+	// assignment_type kosong berarti jabatan definitif
+	assignStatement.Type = strings.ToLower(strings.TrimSpace(assignStatement.Type))
+	if assignStatement.Type == "" {
+		assignStatement.Type = domain.AssignmentTypePrimary
+	}
+	if !domain.IsValidAssignmentType(assignStatement.Type) {
+		return &utils.BadRequestError{Message: "assignment_type must be one of primary, acting, additional"}
+	}
+	if assignStatement.Type == domain.AssignmentTypeActing && assignStatement.EndDate == nil {
+		return &utils.BadRequestError{Message: "end_date is required for acting assignment"}
+	}
+	history, err := e.empAssignRepo.FindHistoryByEmployeeID(assignStatement.EmployeeID)
+	if err != nil {
+		fmt.Println("Error in AssignEmployee: ", err)
+		return &utils.InternalServerError{Message: "Failed to get assignment history"}
+	}
+	primary := assignStatement.Type == domain.AssignmentTypePrimary
+	// jabatan definitif sebelumnya dicatat sebagai before pada audit
+	var before *domain.EmployeeAssignmentResponse
+	for i := range history {
+		period := &history[i]
+		if primary && period.Type == domain.AssignmentTypePrimary {
+			// periode primary terbuka yang dimulai sebelum periode baru akan ditutup otomatis
+			if period.EndDate == nil && period.StartDate.Before(startDate) {
+				before = period
+				continue
+			}
+		} else if period.UnitID != assignStatement.UnitID || period.PositionID != assignStatement.PositionID {
+			// Plt dan tugas tambahan hanya tidak boleh rangkap pada unit dan posisi yang sama
+			continue
+		}
+		if period.Overlaps(startDate, endDate) {
+			return &utils.ConflictError{Message: fmt.Sprintf("assignment period overlaps with assignment starting %s", period.StartDate.Format("2006-01-02"))}
+		}
+	}
 	// perform transactional assignment
 	err = e.empAssignRepo.TransactionalAssignment(assignStatement)
+	if errors.Is(err, domain.ErrFormationFull) {
+		return &utils.ConflictError{Message: "formation for this unit and position is full, set override_formation to assign anyway"}
+	}
+	if errors.Is(err, domain.ErrAssignmentOverlap) {
+		return &utils.ConflictError{Message: "assignment period overlaps with another primary assignment"}
+	}
 	if err != nil {
 		fmt.Println("Error in AssignEmployee: ", err)
 		return &utils.InternalServerError{Message: "Failed to assign employee"}
 	}
+	utils.RecordAudit(e.auditRepo, meta.Entry(principal.UserID, domain.AuditActionAssign, domain.AuditEntityAssignment, assignStatement.ID), before, assignStatement)
 
 	return nil
 }
 
-func (e *EmployeeAssignmentUsecase) Deactivate(proposerId string, employeeID string, unitID int, positionID int) error {
-	// check proposer role
-	proposerRole, err := e.roleRepo.FindByUserID(proposerId)
-	if err != nil {
-		return &utils.UnauthorizedError{Message: "Failed to get user role"}
-	}
-	// check proposer unit id via EmployeeAssignment table
-	proposerAssign, err := e.empAssignRepo.FindByEmployeeID(proposerId)
-	if err != nil {
-		return &utils.UnauthorizedError{Message: "Failed to get user role"}
+// Deactivate menutup periode aktif dengan endDate, zero time berarti hari ini
+func (e *EmployeeAssignmentUsecase) Deactivate(principal *domain.Principal, employeeID string, unitID int, positionID int, endDate time.Time, meta domain.AuditMeta) error {
+	// check unit berada dalam scope proposer
+	if err := e.authz.Authorize(principal, authorization.AssignmentManage, authorization.Unit(unitID)); err != nil {
+		return err
 	}
 
-	if !proposerRole.CanAssignEmployeeGlobal {
-		if !proposerRole.CanAssignEmployeeInternal || proposerAssign.UnitID != unitID {
-			return &utils.UnauthorizedError{Message: "Unauthorized to deactivate employee assignment"}
-		}
+	current, err := e.empAssignRepo.FindByID(employeeID, unitID, positionID)
+	if err != nil || !current.IsActive {
+		return &utils.NotFoundError{Message: "Active employee assignment not found"}
+	}
+	endDate = utils.DateOnly(endDate)
+	if endDate.Before(current.StartDate) {
+		return &utils.BadRequestError{Message: "end_date cannot be before start_date"}
 	}
 
-	err = e.empAssignRepo.Deactivate(employeeID, unitID, positionID)
+	err = e.empAssignRepo.Deactivate(employeeID, unitID, positionID, endDate)
 	if err != nil {
 		fmt.Println("Error in Deactivate: ", err)
 		return &utils.InternalServerError{Message: "Failed to deactivate employee assignment"}
 	}
+	after := *current
+	after.EndDate = &endDate
+	// endDate di masa depan berarti periode masih berjalan sampai endDate
+	after.IsActive = !endDate.Before(utils.DateOnly(time.Time{}))
+	utils.RecordAudit(e.auditRepo, meta.Entry(principal.UserID, domain.AuditActionDeactivate, domain.AuditEntityAssignment, current.ID), current, &after)
 	return nil
 }
 
-func (e *EmployeeAssignmentUsecase) GetAssignmentByEmployeeID(proposerId string, employeeID string) (*domain.EmployeeAssignmentResponse, error) {
-	// check proposer role
-	proposerRole, err := e.roleRepo.FindByUserID(proposerId)
-	if err != nil {
-		return nil, &utils.UnauthorizedError{Message: "Failed to get user role"}
-	}
-	// check proposer unit id via EmployeeAssignment table
-	proposerAssign, err := e.empAssignRepo.FindByEmployeeID(proposerId)
-	if err != nil {
-		return nil, &utils.UnauthorizedError{Message: "Failed to get user role"}
-	}
-
-	if !proposerRole.CanAssignEmployeeGlobal {
-		if !proposerRole.CanAssignEmployeeInternal || proposerAssign.EmployeeID != employeeID {
-			return nil, &utils.UnauthorizedError{}
-		}
+// GetAssignmentByEmployeeID mengembalikan seluruh jabatan aktif employee, termasuk Plt/Plh dan tugas tambahan
+func (e *EmployeeAssignmentUsecase) GetAssignmentByEmployeeID(principal *domain.Principal, employeeID string) ([]domain.EmployeeAssignmentResponse, error) {
+	// employee boleh melihat assignment-nya sendiri, selain itu harus berada dalam scope proposer
+	if err := e.authz.Authorize(principal, authorization.AssignmentRead, authorization.Employee(employeeID)); err != nil {
+		return nil, err
 	}
 
 	assignments, err := e.empAssignRepo.FindByEmployeeID(employeeID)
@@ -161,5 +204,30 @@ func (e *EmployeeAssignmentUsecase) GetAssignmentByEmployeeID(proposerId string,
 		fmt.Println("Error in GetAssignmentByEmployeeID: ", err)
 		return nil, &utils.InternalServerError{Message: "Failed to get employee assignment"}
 	}
+	if assignments == nil {
+		assignments = []domain.EmployeeAssignmentResponse{}
+	}
 	return assignments, nil
 }
+
+// GetHistory mengembalikan seluruh periode assignment employee sebagai riwayat karier, terbaru lebih dulu
+func (e *EmployeeAssignmentUsecase) GetHistory(principal *domain.Principal, employeeID string) ([]domain.EmployeeAssignmentResponse, error) {
+	// employee boleh melihat riwayatnya sendiri, selain itu harus berada dalam scope proposer
+	if err := e.authz.Authorize(principal, authorization.AssignmentRead, authorization.Employee(employeeID)); err != nil {
+		return nil, err
+	}
+	emp, _ := e.empRepo.FindByID(employeeID)
+	if emp == nil {
+		return nil, &utils.NotFoundError{Message: "Employee not found"}
+	}
+
+	history, err := e.empAssignRepo.FindHistoryByEmployeeID(employeeID)
+	if err != nil {
+		fmt.Println("Error in GetHistory: ", err)
+		return nil, &utils.InternalServerError{Message: "Failed to get assignment history"}
+	}
+	if history == nil {
+		history = []domain.EmployeeAssignmentResponse{}
+	}
+	return history, nil
+}
@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"fmt"
+
+	"github.com/achmadnr21/emploman/internal/authorization"
+	"github.com/achmadnr21/emploman/internal/domain"
+	"github.com/achmadnr21/emploman/internal/utils"
+)
+
+type FormationUsecase struct {
+	formationRepo domain.FormationInterface
+	unitRepo      domain.UnitInterface
+	positionRepo  domain.PositionInterface
+	authz         *authorization.Authorizer
+	auditRepo     domain.AuditInterface
+}
+
+func NewFormationUsecase(formationRepo domain.FormationInterface, unitRepo domain.UnitInterface, positionRepo domain.PositionInterface, authz *authorization.Authorizer, auditRepo domain.AuditInterface) *FormationUsecase {
+	return &FormationUsecase{
+		formationRepo: formationRepo,
+		unitRepo:      unitRepo,
+		positionRepo:  positionRepo,
+		authz:         authz,
+		auditRepo:     auditRepo,
+	}
+}
+
+func (uc *FormationUsecase) GetByUnitID(unitID int) ([]domain.Formation, error) {
+	if _, err := uc.unitRepo.FindByID(unitID); err != nil {
+		return nil, &utils.NotFoundError{Message: "unit not found"}
+	}
+	formations, err := uc.formationRepo.FindByUnitID(unitID)
+	if err != nil {
+		fmt.Println("Error getting formations:", err)
+		return nil, &utils.InternalServerError{Message: "failed to get formations"}
+	}
+	return formations, nil
+}
+
+// SetFormation menetapkan kuota posisi pada unit, kuota yang sudah ada akan diganti
+func (uc *FormationUsecase) SetFormation(principal *domain.Principal, formation *domain.Formation, meta domain.AuditMeta) (*domain.Formation, error) {
+	if err := uc.authorize(principal); err != nil {
+		return nil, err
+	}
+	if formation.Quota < 0 {
+		return nil, &utils.BadRequestError{Message: "quota cannot be negative"}
+	}
+	unit, err := uc.unitRepo.FindByID(formation.UnitID)
+	if err != nil {
+		return nil, &utils.NotFoundError{Message: "unit not found"}
+	}
+	position, err := uc.positionRepo.FindByID(formation.PositionID)
+	if err != nil {
+		return nil, &utils.NotFoundError{Message: "position not found"}
+	}
+	before, err := uc.find(formation.UnitID, formation.PositionID)
+	if err != nil {
+		return nil, err
+	}
+	saved, err := uc.formationRepo.Save(formation)
+	if err != nil {
+		fmt.Println("Error saving formation:", err)
+		return nil, &utils.InternalServerError{Message: "failed to save formation"}
+	}
+	saved.UnitName = unit.Name
+	saved.PositionName = position.Name
+	action := domain.AuditActionCreate
+	if before != nil {
+		action = domain.AuditActionUpdate
+		saved.Filled = before.Filled
+		saved.Vacant = max(saved.Quota-saved.Filled, 0)
+	} else {
+		saved.Vacant = saved.Quota
+	}
+	utils.RecordAudit(uc.auditRepo, meta.Entry(principal.UserID, action, domain.AuditEntityFormation, saved.ID), before, saved)
+	return saved, nil
+}
+
+func (uc *FormationUsecase) DeleteFormation(principal *domain.Principal, unitID int, positionID int, meta domain.AuditMeta) error {
+	if err := uc.authorize(principal); err != nil {
+		return err
+	}
+	before, err := uc.find(unitID, positionID)
+	if err != nil {
+		return err
+	}
+	if before == nil {
+		return &utils.NotFoundError{Message: "formation not found"}
+	}
+	if err := uc.formationRepo.Delete(unitID, positionID); err != nil {
+		return &utils.NotFoundError{Message: "formation not found"}
+	}
+	utils.RecordAudit(uc.auditRepo, meta.Entry(principal.UserID, domain.AuditActionDelete, domain.AuditEntityFormation, before.ID), before, nil)
+	return nil
+}
+
+// GetVacancies mengembalikan kursi kosong pada unit, unitID 0 berarti laporan seluruh instansi
+func (uc *FormationUsecase) GetVacancies(unitID int) (*domain.VacancyReport, error) {
+	if unitID != 0 {
+		if _, err := uc.unitRepo.FindByID(unitID); err != nil {
+			return nil, &utils.NotFoundError{Message: "unit not found"}
+		}
+	}
+	vacancies, err := uc.formationRepo.FindVacancies(unitID)
+	if err != nil {
+		fmt.Println("Error getting vacancies:", err)
+		return nil, &utils.InternalServerError{Message: "failed to get vacancies"}
+	}
+	report := &domain.VacancyReport{Vacancies: vacancies}
+	for _, vacancy := range vacancies {
+		report.TotalVacant += vacancy.Vacant
+	}
+	return report, nil
+}
+
+// ==================================================================== UTILITIES ====================================================================
+
+// authorize formasi dikelola oleh role yang boleh mengelola unit
+func (uc *FormationUsecase) authorize(principal *domain.Principal) error {
+	return uc.authz.Authorize(principal, authorization.UnitManage, authorization.Resource{})
+}
+
+// find mengembalikan formasi pada unit dan posisi, nil jika belum ditetapkan
+func (uc *FormationUsecase) find(unitID int, positionID int) (*domain.Formation, error) {
+	formations, err := uc.formationRepo.FindByUnitID(unitID)
+	if err != nil {
+		fmt.Println("Error getting formations:", err)
+		return nil, &utils.InternalServerError{Message: "failed to get formations"}
+	}
+	for i := range formations {
+		if formations[i].PositionID == positionID {
+			return &formations[i], nil
+		}
+	}
+	return nil, nil
+}
@@ -1,19 +1,22 @@
 package usecase
 
 import (
+	"github.com/achmadnr21/emploman/internal/authorization"
 	"github.com/achmadnr21/emploman/internal/domain"
 	"github.com/achmadnr21/emploman/internal/utils"
 )
 
 type GradeUsecase struct {
 	GradeRepo domain.GradeInterface
-	roleRepo  domain.RoleInterface
+	authz     *authorization.Authorizer
+	auditRepo domain.AuditInterface
 }
 
-func NewGradeUsecase(gradeRepo domain.GradeInterface, roleRepo domain.RoleInterface) *GradeUsecase {
+func NewGradeUsecase(gradeRepo domain.GradeInterface, authz *authorization.Authorizer, auditRepo domain.AuditInterface) *GradeUsecase {
 	return &GradeUsecase{
 		GradeRepo: gradeRepo,
-		roleRepo:  roleRepo,
+		authz:     authz,
+		auditRepo: auditRepo,
 	}
 }
 
@@ -24,23 +27,19 @@ func (g *GradeUsecase) GetAll() ([]domain.Grade, error) {
 	}
 	return grades, nil
 }
-func (g *GradeUsecase) AddGrade(proposerId string, grade *domain.Grade) error {
-	// check proposer role
-	proposerRole, err := g.roleRepo.FindByUserID(proposerId)
-	if err != nil {
-		return &utils.InternalServerError{Message: "Failed to get proposer role"}
-	}
-	// check proposer can add grade
-	if !proposerRole.CanAddGrade {
-		return &utils.UnauthorizedError{Message: "You cannot add grade"}
+func (g *GradeUsecase) AddGrade(principal *domain.Principal, grade *domain.Grade, meta domain.AuditMeta) error {
+	if err := g.authz.Authorize(principal, authorization.GradeManage, authorization.Resource{}); err != nil {
+		return err
 	}
 
 	// make sure grade code is not empty
 	if grade.Code == "" {
 		return &utils.BadRequestError{Message: "Grade code cannot be empty"}
 	}
-	if _, err := g.GradeRepo.Save(grade); err != nil {
+	saved, err := g.GradeRepo.Save(grade)
+	if err != nil {
 		return &utils.InternalServerError{Message: "Failed to add grade possibly duplicate ID"}
 	}
+	utils.RecordAudit(g.auditRepo, meta.Entry(principal.UserID, domain.AuditActionCreate, domain.AuditEntityGrade, saved.ID), nil, saved)
 	return nil
 }
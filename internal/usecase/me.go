@@ -15,18 +15,20 @@ import (
 )
 
 type MeUsecase struct {
-	empRepo  domain.EmployeeInterface
-	roleRepo domain.RoleInterface
-	unitRepo domain.UnitInterface
-	s3Repo   domain.S3Interface
+	empRepo   domain.EmployeeInterface
+	roleRepo  domain.RoleInterface
+	unitRepo  domain.UnitInterface
+	s3Repo    domain.S3Interface
+	auditRepo domain.AuditInterface
 }
 
-func NewMeUsecase(empRepo domain.EmployeeInterface, roleRepo domain.RoleInterface, unitRepo domain.UnitInterface, s3Repo domain.S3Interface) *MeUsecase {
+func NewMeUsecase(empRepo domain.EmployeeInterface, roleRepo domain.RoleInterface, unitRepo domain.UnitInterface, s3Repo domain.S3Interface, auditRepo domain.AuditInterface) *MeUsecase {
 	return &MeUsecase{
-		empRepo:  empRepo,
-		roleRepo: roleRepo,
-		unitRepo: unitRepo,
-		s3Repo:   s3Repo,
+		empRepo:   empRepo,
+		roleRepo:  roleRepo,
+		unitRepo:  unitRepo,
+		s3Repo:    s3Repo,
+		auditRepo: auditRepo,
 	}
 }
 
@@ -41,7 +43,7 @@ func (eu *MeUsecase) GetMe(proposerId string) (*domain.Employee, error) {
 	return proposer, nil
 }
 
-func (eu *MeUsecase) UpdateMe(proposerId string, employee *domain.Employee) (*domain.Employee, error) {
+func (eu *MeUsecase) UpdateMe(proposerId string, employee *domain.Employee, meta domain.AuditMeta) (*domain.Employee, error) {
 	// cek proposer
 	proposer, err := eu.empRepo.FindByID(proposerId)
 	if err != nil || proposer == nil {
@@ -51,23 +53,25 @@ func (eu *MeUsecase) UpdateMe(proposerId string, employee *domain.Employee) (*do
 		// unauthorized in this endpoint
 		return nil, &utils.UnauthorizedError{Message: "user not authorized"}
 	}
+	before := *proposer
 	// UpdateMe hanya membolehkan update data diri, yaitu:
-	// Phone Number, Address, Religion
+	// Phone Number, Address, NPWP, Religion
+	// field lainnya diajukan lewat change request (POST /me/change-request)
 	// full name checking
 	if employee.FullName != "" || len(employee.FullName) > 3 || utils.IsAlpha(employee.FullName) {
-		return nil, &utils.UnauthorizedError{Message: "user not authorized to change full name"}
+		return nil, &utils.UnauthorizedError{Message: "full name can only be changed through a change request"}
 	}
 	// place of birth checking
 	if employee.PlaceOfBirth != "" || len(employee.PlaceOfBirth) > 3 || utils.IsAlpha(employee.PlaceOfBirth) {
-		return nil, &utils.UnauthorizedError{Message: "user not authorized to change place of birth"}
+		return nil, &utils.UnauthorizedError{Message: "place of birth can only be changed through a change request"}
 	}
 	// date of birth checking jika is Zero atau tidak ada isinya
 	if !employee.DateOfBirth.IsZero() {
-		return nil, &utils.UnauthorizedError{Message: "user not authorized to change date of birth"}
+		return nil, &utils.UnauthorizedError{Message: "date of birth can only be changed through a change request"}
 	}
 	// Gender checking
 	if employee.Gender != "" && len(employee.Gender) == 1 {
-		return nil, &utils.UnauthorizedError{Message: "user not authorized to change gender"}
+		return nil, &utils.UnauthorizedError{Message: "gender can only be changed through a change request"}
 	}
 	// phone number checking
 	if employee.PhoneNumber != "" && len(employee.PhoneNumber) > 6 && utils.IsNumeric(employee.PhoneNumber) {
@@ -83,7 +87,7 @@ func (eu *MeUsecase) UpdateMe(proposerId string, employee *domain.Employee) (*do
 	}
 	// grade id checking
 	if employee.GradeID > 0 {
-		return nil, &utils.UnauthorizedError{Message: "user not authorized to change grade"}
+		return nil, &utils.UnauthorizedError{Message: "grade can only be changed through a change request"}
 	}
 	// religion id checking
 	if employee.ReligionID != "" && len(employee.ReligionID) == 3 {
@@ -91,18 +95,19 @@ func (eu *MeUsecase) UpdateMe(proposerId string, employee *domain.Employee) (*do
 	}
 	// echelon id checking
 	if employee.EchelonID > 0 {
-		return nil, &utils.UnauthorizedError{Message: "user not authorized to change echelon"}
+		return nil, &utils.UnauthorizedError{Message: "echelon can only be changed through a change request"}
 	}
 
-	newEmp, err := eu.empRepo.Update(proposer)
+	newEmp, err := eu.empRepo.Update(proposer, proposer.ID)
 	if err != nil {
 		return nil, &utils.InternalServerError{Message: "failed to update employee data"}
 	}
 	newEmp.Password = "" // clear password for security
+	utils.RecordAudit(eu.auditRepo, meta.Entry(proposerId, domain.AuditActionUpdate, domain.AuditEntityEmployee, newEmp.ID), &before, newEmp)
 	return newEmp, nil
 }
 
-func (eu *MeUsecase) UploadPPMe(proposerId string, file *multipart.FileHeader) (string, error) {
+func (eu *MeUsecase) UploadPPMe(proposerId string, file *multipart.FileHeader, meta domain.AuditMeta) (string, error) {
 	// cek proposer
 	proposer, err := eu.empRepo.FindByID(proposerId)
 	if err != nil {
@@ -147,11 +152,13 @@ func (eu *MeUsecase) UploadPPMe(proposerId string, file *multipart.FileHeader) (
 	}
 
 	// Update PhotoURL
+	before := *proposer
 	proposer.PhotoURL = url
-	newEmp, err := eu.empRepo.Update(proposer)
+	newEmp, err := eu.empRepo.Update(proposer, proposer.ID)
 	if err != nil {
 		return "", &utils.InternalServerError{Message: "failed to update employee"}
 	}
+	utils.RecordAudit(eu.auditRepo, meta.Entry(proposerId, domain.AuditActionPhoto, domain.AuditEntityEmployee, newEmp.ID), &before, newEmp)
 
 	return newEmp.PhotoURL, nil
 }
@@ -0,0 +1,177 @@
+package usecase
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/achmadnr21/emploman/internal/authorization"
+	"github.com/achmadnr21/emploman/internal/domain"
+	"github.com/achmadnr21/emploman/internal/utils"
+)
+
+// passwordResetTokenTTL adalah masa berlaku token lupa password
+const passwordResetTokenTTL = 30 * time.Minute
+
+type PasswordUsecase struct {
+	passwordRepo domain.PasswordInterface
+	empRepo      domain.EmployeeInterface
+	authz        *authorization.Authorizer
+	tokenRepo    domain.RefreshTokenInterface
+	notifier     domain.Notifier
+	auditRepo    domain.AuditInterface
+}
+
+func NewPasswordUsecase(passwordRepo domain.PasswordInterface, empRepo domain.EmployeeInterface, authz *authorization.Authorizer, tokenRepo domain.RefreshTokenInterface, notifier domain.Notifier, auditRepo domain.AuditInterface) *PasswordUsecase {
+	return &PasswordUsecase{
+		passwordRepo: passwordRepo,
+		empRepo:      empRepo,
+		authz:        authz,
+		tokenRepo:    tokenRepo,
+		notifier:     notifier,
+		auditRepo:    auditRepo,
+	}
+}
+
+// ChangePassword mengganti password milik sendiri. Seluruh sesi login dicabut sehingga
+// user harus login ulang dengan password baru.
+func (uc *PasswordUsecase) ChangePassword(proposerId string, payload domain.PasswordChange, meta domain.AuditMeta) error {
+	proposer, err := uc.empRepo.FindByID(proposerId)
+	if err != nil {
+		return &utils.NotFoundError{Message: "user not found"}
+	}
+	if !utils.CheckPasswordHash(payload.OldPassword, proposer.Password) {
+		return &utils.UnauthorizedError{Message: "old password is incorrect"}
+	}
+	if payload.NewPassword == payload.OldPassword {
+		return &utils.BadRequestError{Message: "new password must be different from old password"}
+	}
+	if err := utils.ValidatePassword(payload.NewPassword); err != nil {
+		return err
+	}
+	hash, err := utils.HashPassword(payload.NewPassword)
+	if err != nil {
+		return &utils.InternalServerError{Message: "failed to hash password"}
+	}
+	if err := uc.passwordRepo.UpdatePassword(proposer.ID, hash, false); err != nil {
+		fmt.Println("Error updating password:", err)
+		return &utils.InternalServerError{Message: "failed to update password"}
+	}
+	uc.revokeSessions(proposer.ID)
+	utils.RecordAudit(uc.auditRepo, meta.Entry(proposerId, domain.AuditActionPassword, domain.AuditEntityEmployee, proposer.ID), nil, nil)
+	return nil
+}
+
+// ResetByAdmin membuat password sementara untuk employee lain dan mewajibkan ganti password
+// saat login berikutnya. Password sementara hanya dikirim ke employee lewat notifier.
+func (uc *PasswordUsecase) ResetByAdmin(principal *domain.Principal, nip string, meta domain.AuditMeta) error {
+	if err := uc.authz.Authorize(principal, authorization.EmployeeCredential, authorization.Resource{}); err != nil {
+		return err
+	}
+	employee, err := uc.empRepo.FindByNIP(nip)
+	if err != nil {
+		return &utils.NotFoundError{Message: "employee not found"}
+	}
+	if employee.ID == principal.UserID {
+		return &utils.BadRequestError{Message: "use /me/password to change your own password"}
+	}
+	if !employee.IsActive() {
+		return &utils.BadRequestError{Message: "employee is inactive"}
+	}
+	temporary, err := utils.GenerateTemporaryPassword()
+	if err != nil {
+		return &utils.InternalServerError{Message: "failed to generate temporary password"}
+	}
+	hash, err := utils.HashPassword(temporary)
+	if err != nil {
+		return &utils.InternalServerError{Message: "failed to hash password"}
+	}
+	if err := uc.passwordRepo.UpdatePassword(employee.ID, hash, true); err != nil {
+		fmt.Println("Error resetting password:", err)
+		return &utils.InternalServerError{Message: "failed to reset password"}
+	}
+	uc.revokeSessions(employee.ID)
+	utils.RecordAudit(uc.auditRepo, meta.Entry(principal.UserID, domain.AuditActionResetPassword, domain.AuditEntityEmployee, employee.ID), nil, nil)
+
+	message := fmt.Sprintf("Password Anda telah direset oleh admin. Password sementara: %s\n"+
+		"Anda wajib mengganti password setelah login.", temporary)
+	if err := uc.notifier.Notify(employee, "Reset password", message); err != nil {
+		fmt.Println("Error sending reset password notification:", err)
+		return &utils.InternalServerError{Message: "password was reset but notification failed"}
+	}
+	return nil
+}
+
+// ForgotPassword mengirim token reset ke employee lewat notifier. Hasilnya selalu sukses
+// agar endpoint tidak bisa dipakai untuk menebak nip yang terdaftar.
+func (uc *PasswordUsecase) ForgotPassword(nip string) error {
+	if nip == "" {
+		return &utils.BadRequestError{Message: "nip is required"}
+	}
+	employee, err := uc.empRepo.FindByNIP(nip)
+	if err != nil || !employee.IsActive() {
+		return nil
+	}
+	token, err := utils.GenerateRandomID(32)
+	if err != nil {
+		fmt.Println("Error generating reset token:", err)
+		return nil
+	}
+	stored := &domain.PasswordResetToken{
+		EmployeeID: employee.ID,
+		TokenHash:  utils.HashToken(token),
+	}
+	if _, err := uc.passwordRepo.SaveResetToken(stored, passwordResetTokenTTL); err != nil {
+		fmt.Println("Error saving reset token:", err)
+		return nil
+	}
+	message := fmt.Sprintf("Gunakan token berikut untuk mengganti password Anda: %s\n"+
+		"Token berlaku selama %d menit dan hanya bisa dipakai sekali.", token, int(passwordResetTokenTTL.Minutes()))
+	if err := uc.notifier.Notify(employee, "Lupa password", message); err != nil {
+		fmt.Println("Error sending forgot password notification:", err)
+	}
+	return nil
+}
+
+// ResetPassword mengganti password memakai token dari ForgotPassword
+func (uc *PasswordUsecase) ResetPassword(payload domain.PasswordReset, meta domain.AuditMeta) error {
+	if payload.Token == "" {
+		return &utils.BadRequestError{Message: "token is required"}
+	}
+	if err := utils.ValidatePassword(payload.NewPassword); err != nil {
+		return err
+	}
+	invalid := &utils.UnauthorizedError{Message: "invalid or expired reset token"}
+	stored, err := uc.passwordRepo.FindResetToken(utils.HashToken(payload.Token))
+	if err != nil {
+		return invalid
+	}
+	// masa berlaku dicek oleh ResetPassword terhadap now() database
+	if stored.UsedAt != nil {
+		return invalid
+	}
+	hash, err := utils.HashPassword(payload.NewPassword)
+	if err != nil {
+		return &utils.InternalServerError{Message: "failed to hash password"}
+	}
+	reset, err := uc.passwordRepo.ResetPassword(stored.ID, hash)
+	if err != nil {
+		fmt.Println("Error resetting password:", err)
+		return &utils.InternalServerError{Message: "failed to reset password"}
+	}
+	if !reset {
+		// token expired atau dipakai request lain di antara pengecekan dan update
+		return invalid
+	}
+	uc.revokeSessions(stored.EmployeeID)
+	utils.RecordAudit(uc.auditRepo, meta.Entry(stored.EmployeeID, domain.AuditActionResetPassword, domain.AuditEntityEmployee, stored.EmployeeID), nil, nil)
+	return nil
+}
+
+// ==================================================================== UTILITIES ====================================================================
+
+// revokeSessions mencabut semua refresh token, kegagalan hanya dicatat karena password sudah terganti
+func (uc *PasswordUsecase) revokeSessions(employeeID string) {
+	if err := uc.tokenRepo.RevokeAllByEmployeeID(employeeID); err != nil {
+		fmt.Println("Error revoking refresh tokens:", err)
+	}
+}
@@ -1,57 +1,50 @@
 package usecase
 
 import (
+	"github.com/achmadnr21/emploman/internal/authorization"
 	"github.com/achmadnr21/emploman/internal/domain"
 	"github.com/achmadnr21/emploman/internal/utils"
 )
 
 type PositionUsecase struct {
 	positionRepo domain.PositionInterface
-	roleRepo     domain.RoleInterface
+	authz        *authorization.Authorizer
+	auditRepo    domain.AuditInterface
 }
 
-func NewPositionUsecase(positionRepo domain.PositionInterface, roleRepo domain.RoleInterface) *PositionUsecase {
+func NewPositionUsecase(positionRepo domain.PositionInterface, authz *authorization.Authorizer, auditRepo domain.AuditInterface) *PositionUsecase {
 	return &PositionUsecase{
 		positionRepo: positionRepo,
-		roleRepo:     roleRepo,
+		authz:        authz,
+		auditRepo:    auditRepo,
 	}
 }
 
-func (uc *PositionUsecase) AddPosition(proposerId string, position *domain.Position) (*domain.Position, error) {
-	// get proposer role
-	proposerRole, err := uc.roleRepo.FindByUserID(proposerId)
-	if err != nil {
+func (uc *PositionUsecase) AddPosition(principal *domain.Principal, position *domain.Position, meta domain.AuditMeta) (*domain.Position, error) {
+	if err := uc.authz.Authorize(principal, authorization.PositionManage, authorization.Resource{}); err != nil {
 		return nil, err
 	}
-	// jika tidak bisa add position, maka return error
-	if !proposerRole.CanAddPosition {
-		return nil, &utils.UnauthorizedError{Message: "You are not authorized to add position"}
-	}
 	if position.Name == "" || len(position.Name) < 5 {
 		return nil, &utils.BadRequestError{Message: "Position name must be at least 5 characters"}
 	}
 	// proses position
-	position, err = uc.positionRepo.Save(position)
+	position, err := uc.positionRepo.Save(position)
 	if err != nil {
 		return nil, &utils.InternalServerError{Message: err.Error()}
 	}
+	utils.RecordAudit(uc.auditRepo, meta.Entry(principal.UserID, domain.AuditActionCreate, domain.AuditEntityPosition, position.ID), nil, position)
 	return position, nil
 }
-func (uc *PositionUsecase) UpdatePosition(proposerId string, position *domain.Position) (*domain.Position, error) {
-	// get proposer role
-	proposerRole, err := uc.roleRepo.FindByUserID(proposerId)
-	if err != nil {
+func (uc *PositionUsecase) UpdatePosition(principal *domain.Principal, position *domain.Position, meta domain.AuditMeta) (*domain.Position, error) {
+	if err := uc.authz.Authorize(principal, authorization.PositionManage, authorization.Resource{}); err != nil {
 		return nil, err
 	}
-	// jika tidak bisa add position, maka return error
-	if !proposerRole.CanAddPosition {
-		return nil, &utils.UnauthorizedError{Message: "You are not authorized to update position"}
-	}
 	// check if position exists
 	oldPosition, err := uc.positionRepo.FindByID(position.ID)
 	if err != nil {
 		return nil, &utils.NotFoundError{Message: "Position not found"}
 	}
+	before := *oldPosition
 
 	// perform all checking
 	// check if position name already exists
@@ -74,29 +67,30 @@ func (uc *PositionUsecase) UpdatePosition(proposerId string, position *domain.Po
 	if position.Name != "" && len(position.Name) > 5 {
 		oldPosition.Name = position.Name
 	}
+	oldPosition.IsHead = position.IsHead
 
 	// proses position
 	position, err = uc.positionRepo.Update(oldPosition)
 	if err != nil {
 		return nil, err
 	}
+	utils.RecordAudit(uc.auditRepo, meta.Entry(principal.UserID, domain.AuditActionUpdate, domain.AuditEntityPosition, position.ID), &before, position)
 	return position, nil
 }
-func (uc *PositionUsecase) DeletePosition(proposerId string, id int) error {
-	// get proposer role
-	proposerRole, err := uc.roleRepo.FindByUserID(proposerId)
-	if err != nil {
+func (uc *PositionUsecase) DeletePosition(principal *domain.Principal, id int, meta domain.AuditMeta) error {
+	if err := uc.authz.Authorize(principal, authorization.PositionManage, authorization.Resource{}); err != nil {
 		return err
 	}
-	// jika tidak bisa delete position, maka return error
-	if !proposerRole.CanAddPosition {
-		return &utils.UnauthorizedError{Message: "You are not authorized to delete position"}
+	oldPosition, err := uc.positionRepo.FindByID(id)
+	if err != nil {
+		return &utils.NotFoundError{Message: "Position not found"}
 	}
 	// proses position
 	err = uc.positionRepo.Delete(id)
 	if err != nil {
 		return err
 	}
+	utils.RecordAudit(uc.auditRepo, meta.Entry(principal.UserID, domain.AuditActionDelete, domain.AuditEntityPosition, id), oldPosition, nil)
 	return nil
 }
 func (uc *PositionUsecase) GetAllPosition() ([]domain.Position, error) {
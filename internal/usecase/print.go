@@ -2,63 +2,110 @@ package usecase
 
 import (
 	"fmt"
+	"net/url"
+	"strings"
+	"time"
 
+	"github.com/achmadnr21/emploman/internal/authorization"
 	"github.com/achmadnr21/emploman/internal/domain"
 	"github.com/achmadnr21/emploman/internal/utils"
 )
 
 type PrintUsecase struct {
-	printRepo domain.PrintInterface
-	empRepo   domain.EmployeeInterface
-	roleRepo  domain.RoleInterface
-	unitRepo  domain.UnitInterface
+	printRepo     domain.PrintInterface
+	empRepo       domain.EmployeeInterface
+	unitRepo      domain.UnitInterface
+	empAssignRepo domain.EmployeeAssignmentInterface
+	s3Repo        domain.S3Interface
+	authz         *authorization.Authorizer
 }
 
-func NewPrintUsecase(printRepo domain.PrintInterface, empRepo domain.EmployeeInterface, roleRepo domain.RoleInterface, unitRepo domain.UnitInterface) *PrintUsecase {
+func NewPrintUsecase(printRepo domain.PrintInterface, empRepo domain.EmployeeInterface, unitRepo domain.UnitInterface, empAssignRepo domain.EmployeeAssignmentInterface, s3Repo domain.S3Interface, authz *authorization.Authorizer) *PrintUsecase {
 	return &PrintUsecase{
-		printRepo: printRepo,
-		empRepo:   empRepo,
-		roleRepo:  roleRepo,
-		unitRepo:  unitRepo,
+		printRepo:     printRepo,
+		empRepo:       empRepo,
+		unitRepo:      unitRepo,
+		empAssignRepo: empAssignRepo,
+		s3Repo:        s3Repo,
+		authz:         authz,
 	}
 }
 
-// private:
-func (eu *PrintUsecase) authorize(proposerId string, requireAdd bool) (*domain.Employee, *domain.Role, error) {
-	user, err := eu.empRepo.FindByID(proposerId)
-	if err != nil {
-		return nil, nil, &utils.NotFoundError{Message: "user not found"}
-	}
-	role, err := eu.roleRepo.FindByID(user.RoleID)
+// PrintAll mengembalikan daftar JSON seluruh employee tanpa saring status kepegawaian, sama seperti PrintByUnitID.
+// Hanya export dokumen yang terbatas pada employee aktif.
+func (eu *PrintUsecase) PrintAll(principal *domain.Principal) ([]domain.PrintEmployee, error) {
+	// cek proposer
+	scope, err := eu.authz.UnitScope(principal, authorization.EmployeeExport)
 	if err != nil {
-		return nil, nil, &utils.NotFoundError{Message: "user role not found"}
+		return nil, err
 	}
-	if requireAdd && !role.CanAddEmployee {
-		return nil, nil, &utils.UnauthorizedError{Message: "not authorized"}
+	if scope.All {
+		employees, err := eu.printRepo.PrintAll()
+		if err != nil {
+			return nil, &utils.InternalServerError{Message: "failed to get employees"}
+		}
+		return employees, nil
 	}
-	if !requireAdd && !(role.CanAddEmployee || role.CanAssignEmployeeInternal || role.CanAssignEmployeeGlobal) {
-		return nil, nil, &utils.UnauthorizedError{Message: "not authorized"}
+	// assign internal hanya mencetak employee pada unit dalam scope
+	employees := []domain.PrintEmployee{}
+	for _, unitID := range scope.UnitIDs {
+		unitEmployees, err := eu.printRepo.PrintByUnit(unitID)
+		if err != nil {
+			return nil, &utils.InternalServerError{Message: "failed to get employees"}
+		}
+		employees = append(employees, unitEmployees...)
 	}
-	return user, role, nil
+	return employees, nil
 }
 
-func (eu *PrintUsecase) PrintAll(proposerId string) ([]domain.PrintEmployee, error) {
+func (eu *PrintUsecase) PrintByUnitID(principal *domain.Principal, unitId int) ([]domain.PrintEmployee, error) {
 	// cek proposer
-	if _, _, err := eu.authorize(proposerId, false); err != nil {
+	unit, err := eu.scopedUnit(principal, unitId)
+	if err != nil {
 		return nil, err
 	}
-	// get all employee
-	employees, err := eu.printRepo.PrintAll()
+
+	// get employee by unit
+	employees, err := eu.printRepo.PrintByUnit(unit.ID)
 	if err != nil {
-		return nil, &utils.InternalServerError{Message: "failed to get employees"}
+		return nil, &utils.NotFoundError{Message: "employee not found"}
 	}
-	// return employees
+	// return employee
 	return employees, nil
 }
 
-func (eu *PrintUsecase) PrintByUnitID(proposerId string, unitId int) ([]domain.PrintEmployee, error) {
+func (eu *PrintUsecase) PrintByNIP(principal *domain.Principal, nip string) (*domain.PrintEmployee, error) {
 	// cek proposer
-	if _, _, err := eu.authorize(proposerId, false); err != nil {
+	if err := eu.authz.Authorize(principal, authorization.EmployeeExport, authorization.Resource{}); err != nil {
+		return nil, err
+	}
+	emp, err := eu.empRepo.FindByNIP(nip)
+	if err != nil {
+		return nil, &utils.NotFoundError{Message: "employee not found"}
+	}
+	// employee di luar unit scope tidak boleh dicetak, kecuali data diri sendiri
+	if err := eu.authz.Authorize(principal, authorization.EmployeeExport, authorization.Employee(emp.ID)); err != nil {
+		return nil, err
+	}
+	// get employee by nip
+	employee, err := eu.printRepo.PrintByNIP(nip)
+	if err != nil {
+		fmt.Println("err: ", err)
+		return nil, &utils.NotFoundError{Message: "employee not found"}
+	}
+	// seluruh jabatan aktif, termasuk Plt/Plh dan tugas tambahan
+	employee.CurrentRoles, err = eu.empAssignRepo.FindByEmployeeID(emp.ID)
+	if err != nil {
+		fmt.Println("Error getting current assignments:", err)
+		return nil, &utils.InternalServerError{Message: "failed to get current assignments"}
+	}
+	// return employee
+	return employee, nil
+}
+
+// scopedUnit memastikan unit ada dan berada dalam unit scope proposer
+func (eu *PrintUsecase) scopedUnit(principal *domain.Principal, unitId int) (*domain.Unit, error) {
+	if err := eu.authz.Authorize(principal, authorization.EmployeeExport, authorization.Resource{}); err != nil {
 		return nil, err
 	}
 	// check wether unit exists
@@ -66,27 +113,156 @@ func (eu *PrintUsecase) PrintByUnitID(proposerId string, unitId int) ([]domain.P
 	if err != nil {
 		return nil, &utils.NotFoundError{Message: "unit not found"}
 	}
+	if err := eu.authz.Authorize(principal, authorization.EmployeeExport, authorization.Unit(unit.ID)); err != nil {
+		return nil, err
+	}
+	return unit, nil
+}
 
-	// get employee by unit
-	employees, err := eu.printRepo.PrintByUnit(unit.ID)
+// EmployeeExport berisi judul dokumen dan fungsi untuk membaca employee satu per satu,
+// dipakai untuk export csv/xlsx/pdf agar unit besar tidak ditampung di memory
+type EmployeeExport struct {
+	Title  string
+	Stream func(fn func(*domain.PrintEmployee) error) error
+}
+
+func (eu *PrintUsecase) ExportAll(principal *domain.Principal) (*EmployeeExport, error) {
+	// cek proposer
+	scope, err := eu.authz.UnitScope(principal, authorization.EmployeeExport)
 	if err != nil {
-		return nil, &utils.NotFoundError{Message: "employee not found"}
+		return nil, err
 	}
-	// return employee
-	return employees, nil
+	if scope.All {
+		return &EmployeeExport{Stream: eu.printRepo.StreamAll}, nil
+	}
+	// assign internal hanya mencetak employee pada unit dalam scope, dibaca per unit
+	return &EmployeeExport{
+		Stream: func(fn func(*domain.PrintEmployee) error) error {
+			for _, unitID := range scope.UnitIDs {
+				if err := eu.printRepo.StreamByUnit(unitID, fn); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}, nil
 }
 
-func (eu *PrintUsecase) PrintByNIP(proposerId string, nip string) (*domain.PrintEmployee, error) {
+func (eu *PrintUsecase) ExportByUnitID(principal *domain.Principal, unitId int) (*EmployeeExport, error) {
 	// cek proposer
-	if _, _, err := eu.authorize(proposerId, false); err != nil {
+	unit, err := eu.scopedUnit(principal, unitId)
+	if err != nil {
 		return nil, err
 	}
-	// get employee by nip
-	employee, err := eu.printRepo.PrintByNIP(nip)
+	return &EmployeeExport{
+		Title: unit.Name,
+		Stream: func(fn func(*domain.PrintEmployee) error) error {
+			return eu.printRepo.StreamByUnit(unit.ID, fn)
+		},
+	}, nil
+}
+
+func (eu *PrintUsecase) ExportByNIP(principal *domain.Principal, nip string) (*EmployeeExport, error) {
+	employee, err := eu.PrintByNIP(principal, nip)
+	if err != nil {
+		return nil, err
+	}
+	return &EmployeeExport{
+		Title: employee.Unit,
+		Stream: func(fn func(*domain.PrintEmployee) error) error {
+			return fn(employee)
+		},
+	}, nil
+}
+
+// PrintCV mengumpulkan data Daftar Riwayat Hidup: biodata, foto profil dan riwayat jabatan
+func (eu *PrintUsecase) PrintCV(principal *domain.Principal, nip string) (*domain.PrintEmployeeCV, error) {
+	employee, err := eu.PrintByNIP(principal, nip)
+	if err != nil {
+		return nil, err
+	}
+	emp, err := eu.empRepo.FindByNIP(nip)
 	if err != nil {
-		fmt.Println("err: ", err)
 		return nil, &utils.NotFoundError{Message: "employee not found"}
 	}
-	// return employee
-	return employee, nil
+	assignments, err := eu.empAssignRepo.FindHistoryByEmployeeID(emp.ID)
+	if err != nil {
+		fmt.Println("Error getting assignment history:", err)
+		return nil, &utils.InternalServerError{Message: "failed to get assignment history"}
+	}
+	cv := &domain.PrintEmployeeCV{
+		Employee:    employee,
+		Assignments: assignments,
+	}
+	cv.Photo = eu.fetchPhoto(employee.PhotoURL)
+	return cv, nil
+}
+
+// PrintCard menyiapkan data kartu pegawai beserta token QR yang ditandatangani
+func (eu *PrintUsecase) PrintCard(principal *domain.Principal, nip string) (*domain.PrintEmployeeCard, error) {
+	employee, err := eu.PrintByNIP(principal, nip)
+	if err != nil {
+		return nil, err
+	}
+	issuedAt := time.Now()
+	return &domain.PrintEmployeeCard{
+		Employee: employee,
+		Photo:    eu.fetchPhoto(employee.PhotoURL),
+		Token:    utils.GenerateCardToken(employee.NIP, issuedAt),
+		IssuedAt: issuedAt,
+	}, nil
+}
+
+// VerifyCard dipanggil tanpa login dari hasil scan QR code, hanya data yang tercetak di kartu yang dikembalikan
+func (eu *PrintUsecase) VerifyCard(token string) (*domain.CardVerification, error) {
+	nip, issuedAt, err := utils.ParseCardToken(token)
+	if err != nil {
+		return nil, &utils.NotFoundError{Message: "card is not recognized"}
+	}
+	emp, err := eu.empRepo.FindByNIP(nip)
+	if err != nil {
+		return nil, &utils.NotFoundError{Message: "card is not recognized"}
+	}
+	employee, err := eu.printRepo.PrintByNIP(nip)
+	if err != nil {
+		return nil, &utils.NotFoundError{Message: "card is not recognized"}
+	}
+	return &domain.CardVerification{
+		NIP:              employee.NIP,
+		FullName:         employee.FullName,
+		Jabatan:          employee.Jabatan,
+		Unit:             employee.Unit,
+		EmploymentStatus: emp.EmploymentStatus,
+		IsActive:         emp.IsActive(),
+		IssuedAt:         issuedAt,
+	}, nil
+}
+
+// ==================================================================== UTILITIES ====================================================================
+
+// fetchPhoto mengambil foto profil dari S3. Foto tidak wajib, dokumen tetap dicetak dengan kotak foto kosong.
+func (eu *PrintUsecase) fetchPhoto(photoURL string) []byte {
+	key := photoKey(photoURL)
+	if key == "" {
+		return nil
+	}
+	photo, err := eu.s3Repo.GetFile(key)
+	if err != nil {
+		fmt.Println("Error getting profile photo:", err)
+		return nil
+	}
+	return photo
+}
+
+// photoKey mengambil object key dari url yang dihasilkan S3Repository.UploadFile (https://host/bucket/key)
+func photoKey(photoURL string) string {
+	parsed, err := url.Parse(photoURL)
+	if err != nil {
+		return ""
+	}
+	parts := strings.SplitN(strings.TrimPrefix(parsed.Path, "/"), "/", 2)
+	if len(parts) != 2 {
+		return ""
+	}
+	return parts[1]
 }
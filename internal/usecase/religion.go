@@ -3,19 +3,22 @@ package usecase
 import (
 	"fmt"
 
+	"github.com/achmadnr21/emploman/internal/authorization"
 	"github.com/achmadnr21/emploman/internal/domain"
 	"github.com/achmadnr21/emploman/internal/utils"
 )
 
 type ReligionUsecase struct {
 	ReligionRepo domain.ReligionInterface
-	roleRepo     domain.RoleInterface
+	authz        *authorization.Authorizer
+	auditRepo    domain.AuditInterface
 }
 
-func NewReligionUsecase(religionRepo domain.ReligionInterface, roleRepo domain.RoleInterface) *ReligionUsecase {
+func NewReligionUsecase(religionRepo domain.ReligionInterface, authz *authorization.Authorizer, auditRepo domain.AuditInterface) *ReligionUsecase {
 	return &ReligionUsecase{
 		ReligionRepo: religionRepo,
-		roleRepo:     roleRepo,
+		authz:        authz,
+		auditRepo:    auditRepo,
 	}
 }
 func (r *ReligionUsecase) GetAll() ([]domain.Religion, error) {
@@ -25,15 +28,9 @@ func (r *ReligionUsecase) GetAll() ([]domain.Religion, error) {
 	}
 	return religions, nil
 }
-func (r *ReligionUsecase) AddReligion(proposerId string, religion *domain.Religion) error {
-	// check proposer role
-	proposerRole, err := r.roleRepo.FindByUserID(proposerId)
-	if err != nil {
-		return &utils.UnauthorizedError{Message: "Failed to get user role"}
-	}
-	// check proposer can add religion
-	if !proposerRole.CanAddReligion {
-		return &utils.UnauthorizedError{Message: "You cannot add religion"}
+func (r *ReligionUsecase) AddReligion(principal *domain.Principal, religion *domain.Religion, meta domain.AuditMeta) error {
+	if err := r.authz.Authorize(principal, authorization.ReligionManage, authorization.Resource{}); err != nil {
+		return err
 	}
 	// make sure religio.ID is char(3)
 	if len(religion.ID) != 3 {
@@ -44,8 +41,10 @@ func (r *ReligionUsecase) AddReligion(proposerId string, religion *domain.Religi
 		return &utils.BadRequestError{Message: "Religion name cannot be empty"}
 	}
 
-	if _, err := r.ReligionRepo.Save(religion); err != nil {
+	saved, err := r.ReligionRepo.Save(religion)
+	if err != nil {
 		return &utils.InternalServerError{Message: fmt.Sprintf("Failed to add religion possibly duplicate ID")}
 	}
+	utils.RecordAudit(r.auditRepo, meta.Entry(principal.UserID, domain.AuditActionCreate, domain.AuditEntityReligion, saved.ID), nil, saved)
 	return nil
 }
@@ -0,0 +1,357 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/achmadnr21/emploman/internal/authorization"
+	"github.com/achmadnr21/emploman/internal/domain"
+	"github.com/achmadnr21/emploman/internal/utils"
+)
+
+type RoleUsecase struct {
+	roleRepo       domain.RoleInterface
+	permissionRepo domain.PermissionInterface
+	auditRepo      domain.AuditInterface
+	authz          *authorization.Authorizer
+}
+
+func NewRoleUsecase(roleRepo domain.RoleInterface, permissionRepo domain.PermissionInterface, auditRepo domain.AuditInterface, authz *authorization.Authorizer) *RoleUsecase {
+	return &RoleUsecase{
+		roleRepo:       roleRepo,
+		permissionRepo: permissionRepo,
+		auditRepo:      auditRepo,
+		authz:          authz,
+	}
+}
+
+// private:
+func (uc *RoleUsecase) authorize(principal *domain.Principal) (*domain.Role, error) {
+	if err := uc.authz.Authorize(principal, authorization.RoleManage, authorization.Resource{}); err != nil {
+		return nil, err
+	}
+	// role proposer dipakai untuk membatasi level dan permission yang boleh diberikan
+	proposerRole, err := utils.PrincipalRole(uc.roleRepo, principal)
+	if err != nil {
+		return nil, &utils.NotFoundError{Message: "user role not found"}
+	}
+	return proposerRole, nil
+}
+
+func (uc *RoleUsecase) GetAllRole() ([]domain.Role, error) {
+	roles, err := uc.roleRepo.FindAll()
+	if err != nil {
+		return nil, &utils.InternalServerError{Message: "failed to get roles"}
+	}
+	return roles, nil
+}
+
+func (uc *RoleUsecase) GetRoleByID(id string) (*domain.Role, error) {
+	role, err := uc.roleRepo.FindByID(strings.ToUpper(id))
+	if err != nil {
+		return nil, &utils.NotFoundError{Message: "role not found"}
+	}
+	return role, nil
+}
+
+// GetAllPermission mengembalikan katalog permission yang dapat diberikan ke role
+func (uc *RoleUsecase) GetAllPermission() ([]domain.Permission, error) {
+	permissions, err := uc.permissionRepo.FindAll()
+	if err != nil {
+		fmt.Println("Error getting permissions:", err)
+		return nil, &utils.InternalServerError{Message: "failed to get permissions"}
+	}
+	return permissions, nil
+}
+
+// GetEffectivePermissions mengembalikan permission yang dimiliki pemegang role menurut policy,
+// yaitu permission role ditambah permission bawaan setiap user yang login
+func (uc *RoleUsecase) GetEffectivePermissions(id string) ([]domain.EffectivePermission, error) {
+	role, err := uc.roleRepo.FindByID(strings.ToUpper(id))
+	if err != nil {
+		return nil, &utils.NotFoundError{Message: "role not found"}
+	}
+	catalogue, err := uc.GetAllPermission()
+	if err != nil {
+		return nil, err
+	}
+	holder := &domain.Principal{RoleID: role.ID, Permissions: role.PermissionSet()}
+	effective := []domain.EffectivePermission{}
+	for _, permission := range catalogue {
+		scope := authorization.ScopeFor(holder, authorization.Action(permission.ID))
+		if scope == authorization.ScopeNone {
+			continue
+		}
+		effective = append(effective, domain.EffectivePermission{
+			PermissionID: permission.ID,
+			Description:  permission.Description,
+			Scope:        scope.String(),
+			Inherited:    holder.Permissions[permission.ID] == "",
+		})
+	}
+	return effective, nil
+}
+
+func (uc *RoleUsecase) AddRole(principal *domain.Principal, role *domain.Role, meta domain.AuditMeta) (*domain.Role, error) {
+	proposerRole, err := uc.authorize(principal)
+	if err != nil {
+		return nil, err
+	}
+	role.ID = strings.ToUpper(role.ID)
+	if len(role.ID) != 3 || !utils.IsAlpha(role.ID) || strings.Contains(role.ID, " ") {
+		return nil, &utils.BadRequestError{Message: "role id must be 3 letters"}
+	}
+	if role.Name == "" || len(role.Name) < 3 {
+		return nil, &utils.BadRequestError{Message: "role name is required and must be at least 3 characters"}
+	}
+	if role.Level <= 0 {
+		return nil, &utils.BadRequestError{Message: "role level must be greater than 0"}
+	}
+	if role.Description == "" {
+		role.Description = "no desc"
+	}
+	if err := uc.validatePermissions(role); err != nil {
+		return nil, err
+	}
+	if err := checkRoleGrant(proposerRole, role); err != nil {
+		return nil, err
+	}
+	// cek duplikasi id
+	if existing, _ := uc.roleRepo.FindByID(role.ID); existing != nil {
+		return nil, &utils.ConflictError{Message: "role already exists"}
+	}
+	newRole, err := uc.roleRepo.Save(role)
+	if err != nil {
+		fmt.Println("Error saving role:", err)
+		return nil, &utils.InternalServerError{Message: "failed to add role possibly duplicate name"}
+	}
+	utils.RecordAudit(uc.auditRepo, meta.Entry(principal.UserID, domain.AuditActionCreate, domain.AuditEntityRole, newRole.ID), nil, newRole)
+	return newRole, nil
+}
+
+// UpdateRole mengubah name, description, level, require_2fa dan permission jika diisi.
+// Permission yang dikirim mengganti seluruh permission role.
+func (uc *RoleUsecase) UpdateRole(principal *domain.Principal, role *domain.RoleUpdate, meta domain.AuditMeta) (*domain.Role, error) {
+	proposerRole, err := uc.authorize(principal)
+	if err != nil {
+		return nil, err
+	}
+	oldRole, err := uc.roleRepo.FindByID(strings.ToUpper(role.ID))
+	if err != nil {
+		return nil, &utils.NotFoundError{Message: "role not found"}
+	}
+	if oldRole.Level > proposerRole.Level {
+		return nil, &utils.UnauthorizedError{Message: "user not authorized to update higher level role"}
+	}
+	before := *oldRole
+	if role.Name != "" && len(role.Name) >= 3 {
+		oldRole.Name = role.Name
+	}
+	if role.Description != "" {
+		oldRole.Description = role.Description
+	}
+	if role.Level > 0 {
+		oldRole.Level = role.Level
+	}
+	if role.Permissions != nil {
+		oldRole.Permissions = *role.Permissions
+	}
+	// require_2fa yang tidak dikirim tidak boleh mematikan 2FA wajib
+	if role.Require2FA != nil {
+		oldRole.Require2FA = *role.Require2FA
+	}
+	if err := uc.validatePermissions(oldRole); err != nil {
+		return nil, err
+	}
+	if err := checkRoleGrant(proposerRole, oldRole); err != nil {
+		return nil, err
+	}
+
+	newRole, err := uc.roleRepo.Update(oldRole)
+	if err != nil {
+		fmt.Println("Error updating role:", err)
+		return nil, &utils.InternalServerError{Message: "failed to update role"}
+	}
+	utils.RecordAudit(uc.auditRepo, meta.Entry(principal.UserID, domain.AuditActionUpdate, domain.AuditEntityRole, newRole.ID), &before, newRole)
+	return newRole, nil
+}
+
+func (uc *RoleUsecase) DeleteRole(principal *domain.Principal, id string, meta domain.AuditMeta) error {
+	proposerRole, err := uc.authorize(principal)
+	if err != nil {
+		return err
+	}
+	role, err := uc.roleRepo.FindByID(strings.ToUpper(id))
+	if err != nil {
+		return &utils.NotFoundError{Message: "role not found"}
+	}
+	if role.ID == proposerRole.ID {
+		return &utils.BadRequestError{Message: "cannot delete your own role"}
+	}
+	if role.Level > proposerRole.Level {
+		return &utils.UnauthorizedError{Message: "user not authorized to delete higher level role"}
+	}
+	// role yang masih dipakai employee tidak boleh dihapus
+	count, err := uc.roleRepo.CountEmployees(role.ID)
+	if err != nil {
+		return &utils.InternalServerError{Message: "failed to check role usage"}
+	}
+	if count > 0 {
+		return &utils.ConflictError{Message: fmt.Sprintf("role is still used by %d employee(s)", count)}
+	}
+	if err := uc.roleRepo.Delete(role.ID); err != nil {
+		return &utils.InternalServerError{Message: "failed to delete role"}
+	}
+	utils.RecordAudit(uc.auditRepo, meta.Entry(principal.UserID, domain.AuditActionDelete, domain.AuditEntityRole, role.ID), role, nil)
+	return nil
+}
+
+func (uc *RoleUsecase) GetAllPromotion(principal *domain.Principal) ([]domain.RolePromotion, error) {
+	if _, err := uc.authorize(principal); err != nil {
+		return nil, err
+	}
+	promotions, err := uc.roleRepo.FindAllPromotion()
+	if err != nil {
+		return nil, &utils.InternalServerError{Message: "failed to get role promotions"}
+	}
+	return promotions, nil
+}
+
+func (uc *RoleUsecase) AddPromotion(principal *domain.Principal, rolePromotion *domain.RolePromotion, meta domain.AuditMeta) (*domain.RolePromotion, error) {
+	proposerRole, err := uc.authorize(principal)
+	if err != nil {
+		return nil, err
+	}
+	promoter, from, to, err := uc.resolvePromotion(rolePromotion)
+	if err != nil {
+		return nil, err
+	}
+	if from.ID == to.ID {
+		return nil, &utils.BadRequestError{Message: "from_role_id and to_role_id must be different"}
+	}
+	if promoter.Level > proposerRole.Level {
+		return nil, &utils.UnauthorizedError{Message: "user not authorized to manage higher level role"}
+	}
+	// promoter tidak boleh menaikkan role melebihi levelnya sendiri
+	if to.Level > promoter.Level {
+		return nil, &utils.BadRequestError{Message: "to_role_id level cannot be higher than promoter_role_id level"}
+	}
+	newPromotion, err := uc.roleRepo.SavePromotion(rolePromotion)
+	if errors.Is(err, domain.ErrRolePromotionExists) {
+		return nil, &utils.ConflictError{Message: "role promotion already exists"}
+	}
+	if err != nil {
+		fmt.Println("Error saving role promotion:", err)
+		return nil, &utils.InternalServerError{Message: "failed to add role promotion"}
+	}
+	utils.RecordAudit(uc.auditRepo, meta.Entry(principal.UserID, domain.AuditActionCreate, domain.AuditEntityRolePromotion, promotionID(newPromotion)), nil, newPromotion)
+	return newPromotion, nil
+}
+
+func (uc *RoleUsecase) DeletePromotion(principal *domain.Principal, rolePromotion *domain.RolePromotion, meta domain.AuditMeta) error {
+	proposerRole, err := uc.authorize(principal)
+	if err != nil {
+		return err
+	}
+	promoter, _, _, err := uc.resolvePromotion(rolePromotion)
+	if err != nil {
+		return err
+	}
+	if promoter.Level > proposerRole.Level {
+		return &utils.UnauthorizedError{Message: "user not authorized to manage higher level role"}
+	}
+	if err := uc.roleRepo.DeletePromotion(rolePromotion); err != nil {
+		return &utils.NotFoundError{Message: "role promotion not found"}
+	}
+	utils.RecordAudit(uc.auditRepo, meta.Entry(principal.UserID, domain.AuditActionDelete, domain.AuditEntityRolePromotion, promotionID(rolePromotion)), rolePromotion, nil)
+	return nil
+}
+
+// resolvePromotion menormalkan id dan memastikan ketiga role ada
+func (uc *RoleUsecase) resolvePromotion(rolePromotion *domain.RolePromotion) (*domain.Role, *domain.Role, *domain.Role, error) {
+	rolePromotion.PromoterRoleID = strings.ToUpper(rolePromotion.PromoterRoleID)
+	rolePromotion.FromRoleID = strings.ToUpper(rolePromotion.FromRoleID)
+	rolePromotion.ToRoleID = strings.ToUpper(rolePromotion.ToRoleID)
+	promoter, err := uc.roleRepo.FindByID(rolePromotion.PromoterRoleID)
+	if err != nil {
+		return nil, nil, nil, &utils.NotFoundError{Message: "promoter role not found"}
+	}
+	from, err := uc.roleRepo.FindByID(rolePromotion.FromRoleID)
+	if err != nil {
+		return nil, nil, nil, &utils.NotFoundError{Message: "from role not found"}
+	}
+	to, err := uc.roleRepo.FindByID(rolePromotion.ToRoleID)
+	if err != nil {
+		return nil, nil, nil, &utils.NotFoundError{Message: "to role not found"}
+	}
+	return promoter, from, to, nil
+}
+
+// ==================================================================== UTILITIES ====================================================================
+
+// validatePermissions menormalkan permission role dan memastikan setiap permission ada di katalog.
+// Scope kosong berarti global, scope unit hanya untuk permission yang unit_scoped.
+func (uc *RoleUsecase) validatePermissions(role *domain.Role) error {
+	if role.Permissions == nil {
+		role.Permissions = []domain.RolePermission{}
+	}
+	if len(role.Permissions) == 0 {
+		return nil
+	}
+	catalogue, err := uc.GetAllPermission()
+	if err != nil {
+		return err
+	}
+	known := map[string]domain.Permission{}
+	for _, permission := range catalogue {
+		known[permission.ID] = permission
+	}
+	seen := map[string]bool{}
+	for i := range role.Permissions {
+		permission := &role.Permissions[i]
+		permission.PermissionID = strings.ToLower(strings.TrimSpace(permission.PermissionID))
+		permission.Scope = strings.ToLower(strings.TrimSpace(permission.Scope))
+		if permission.Scope == "" {
+			permission.Scope = domain.PermissionScopeGlobal
+		}
+		entry, ok := known[permission.PermissionID]
+		if !ok {
+			return &utils.BadRequestError{Message: fmt.Sprintf("unknown permission %q", permission.PermissionID)}
+		}
+		if seen[permission.PermissionID] {
+			return &utils.BadRequestError{Message: fmt.Sprintf("duplicate permission %q", permission.PermissionID)}
+		}
+		seen[permission.PermissionID] = true
+		switch permission.Scope {
+		case domain.PermissionScopeGlobal:
+		case domain.PermissionScopeUnit:
+			if !entry.UnitScoped {
+				return &utils.BadRequestError{Message: fmt.Sprintf("permission %q cannot be limited to unit scope", permission.PermissionID)}
+			}
+		default:
+			return &utils.BadRequestError{Message: "permission scope must be one of unit, global"}
+		}
+	}
+	return nil
+}
+
+// checkRoleGrant memastikan proposer tidak membuat role di atas levelnya sendiri
+// atau memberikan permission dengan scope lebih luas dari miliknya.
+func checkRoleGrant(proposer *domain.Role, target *domain.Role) error {
+	if target.Level > proposer.Level {
+		return &utils.UnauthorizedError{Message: "role level cannot be higher than your own"}
+	}
+	owned := proposer.PermissionSet()
+	for _, permission := range target.Permissions {
+		if authorization.ParseScope(permission.Scope) > authorization.ParseScope(owned[permission.PermissionID]) {
+			return &utils.UnauthorizedError{Message: fmt.Sprintf("cannot grant permission %q you do not have", permission.PermissionID)}
+		}
+	}
+	return nil
+}
+
+// promotionID dipakai sebagai entity id audit, contoh: SUP:USR>HRD
+func promotionID(rolePromotion *domain.RolePromotion) string {
+	return rolePromotion.PromoterRoleID + ":" + rolePromotion.FromRoleID + ">" + rolePromotion.ToRoleID
+}
@@ -0,0 +1,96 @@
+package usecase_scope
+
+import (
+	"fmt"
+
+	"github.com/achmadnr21/emploman/internal/domain"
+	"github.com/achmadnr21/emploman/internal/utils"
+)
+
+// Resolver menentukan unit yang menjadi wewenang seorang employee, dipakai authorization.Authorizer untuk ScopeUnit. Wewenang "internal" berarti
+// unit seluruh assignment aktif employee ditambah unit yang diberikan administrator lewat unit scope.
+type Resolver struct {
+	scopeRepo     domain.UnitScopeInterface
+	empAssignRepo domain.EmployeeAssignmentInterface
+	unitRepo      domain.UnitInterface
+	groupRepo     domain.UnitGroupInterface
+}
+
+func NewResolver(scopeRepo domain.UnitScopeInterface, empAssignRepo domain.EmployeeAssignmentInterface, unitRepo domain.UnitInterface, groupRepo domain.UnitGroupInterface) *Resolver {
+	return &Resolver{
+		scopeRepo:     scopeRepo,
+		empAssignRepo: empAssignRepo,
+		unitRepo:      unitRepo,
+		groupRepo:     groupRepo,
+	}
+}
+
+// EmployeeInScope memastikan salah satu assignment aktif employee berada di dalam scope
+func (r *Resolver) EmployeeInScope(scope *domain.UnitScope, employeeID string) bool {
+	if scope.All {
+		return true
+	}
+	current, err := r.empAssignRepo.FindByEmployeeID(employeeID)
+	if err != nil {
+		return false
+	}
+	for _, assignment := range current {
+		if scope.Contains(assignment.UnitID) {
+			return true
+		}
+	}
+	return false
+}
+
+// Units mengembalikan unit internal employee: unit seluruh assignment aktif ditambah unit scope yang diberikan
+func (r *Resolver) Units(employeeID string) (*domain.UnitScope, error) {
+	scope := &domain.UnitScope{UnitIDs: []int{}}
+	seen := map[int]bool{}
+	add := func(ids ...int) {
+		for _, id := range ids {
+			if !seen[id] {
+				seen[id] = true
+				scope.UnitIDs = append(scope.UnitIDs, id)
+			}
+		}
+	}
+	// termasuk unit tempat employee menjabat Plt/Plh atau tugas tambahan
+	current, err := r.empAssignRepo.FindByEmployeeID(employeeID)
+	if err != nil {
+		fmt.Println("Error getting employee assignment:", err)
+		return nil, &utils.InternalServerError{Message: "failed to resolve unit scope"}
+	}
+	for _, assignment := range current {
+		add(assignment.UnitID)
+	}
+	grants, err := r.scopeRepo.FindByEmployeeID(employeeID)
+	if err != nil {
+		fmt.Println("Error getting unit scope:", err)
+		return nil, &utils.InternalServerError{Message: "failed to resolve unit scope"}
+	}
+	for _, grant := range grants {
+		unitIDs := []int{grant.UnitID}
+		// grant atas unit group berlaku untuk seluruh unit anggota grup
+		if grant.UnitGroupID != 0 {
+			group, err := r.groupRepo.FindByID(grant.UnitGroupID)
+			if err != nil {
+				fmt.Println("Error getting unit group:", err)
+				return nil, &utils.InternalServerError{Message: "failed to resolve unit scope"}
+			}
+			unitIDs = group.UnitIDs
+		}
+		if !grant.IncludeSubunits {
+			add(unitIDs...)
+			continue
+		}
+		for _, unitID := range unitIDs {
+			ids, err := r.unitRepo.FindSubtreeIDs(unitID)
+			if err != nil {
+				fmt.Println("Error getting unit subtree:", err)
+				return nil, &utils.InternalServerError{Message: "failed to resolve unit scope"}
+			}
+			add(ids...)
+		}
+	}
+	return scope, nil
+}
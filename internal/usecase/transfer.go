@@ -0,0 +1,401 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/achmadnr21/emploman/internal/authorization"
+	"github.com/achmadnr21/emploman/internal/domain"
+	"github.com/achmadnr21/emploman/internal/utils"
+)
+
+type TransferUsecase struct {
+	transferRepo  domain.TransferInterface
+	empAssignRepo domain.EmployeeAssignmentInterface
+	empRepo       domain.EmployeeInterface
+	authz         *authorization.Authorizer
+	unitRepo      domain.UnitInterface
+	positionRepo  domain.PositionInterface
+	auditRepo     domain.AuditInterface
+}
+
+func NewTransferUsecase(transferRepo domain.TransferInterface, empAssignRepo domain.EmployeeAssignmentInterface, empRepo domain.EmployeeInterface, authz *authorization.Authorizer, unitRepo domain.UnitInterface, positionRepo domain.PositionInterface, auditRepo domain.AuditInterface) *TransferUsecase {
+	return &TransferUsecase{
+		transferRepo:  transferRepo,
+		empAssignRepo: empAssignRepo,
+		empRepo:       empRepo,
+		authz:         authz,
+		unitRepo:      unitRepo,
+		positionRepo:  positionRepo,
+		auditRepo:     auditRepo,
+	}
+}
+
+// Create membuat usulan mutasi berstatus draft, hanya kepala unit asal employee yang boleh membuat
+func (uc *TransferUsecase) Create(principal *domain.Principal, transfer *domain.TransferRequest, comment string, meta domain.AuditMeta) (*domain.TransferRequest, error) {
+	comment, err := requireComment(comment)
+	if err != nil {
+		return nil, err
+	}
+	if emp, _ := uc.empRepo.FindByID(transfer.EmployeeID); emp == nil {
+		return nil, &utils.NotFoundError{Message: "employee not found"}
+	}
+	// unit asal adalah unit jabatan definitif employee saat ini
+	current, err := uc.primaryAssignment(transfer.EmployeeID)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		return nil, &utils.BadRequestError{Message: "employee has no active primary assignment"}
+	}
+	transfer.FromUnitID = current.UnitID
+	heads, err := uc.headUnits(principal.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !heads[transfer.FromUnitID] {
+		return nil, &utils.UnauthorizedError{Message: "only the head of the employee's unit can create a transfer request"}
+	}
+	if transfer.ToUnitID == transfer.FromUnitID {
+		return nil, &utils.BadRequestError{Message: "destination unit must be different from the current unit"}
+	}
+	if unit, _ := uc.unitRepo.FindByID(transfer.ToUnitID); unit == nil {
+		return nil, &utils.NotFoundError{Message: "destination unit not found"}
+	}
+	if position, _ := uc.positionRepo.FindByID(transfer.ToPositionID); position == nil {
+		return nil, &utils.NotFoundError{Message: "destination position not found"}
+	}
+	transfer.StartDate = utils.DateOnly(transfer.StartDate)
+	if transfer.StartDate.Before(utils.DateOnly(time.Time{})) {
+		return nil, &utils.BadRequestError{Message: "start_date cannot be in the past"}
+	}
+	transfer.Reason = strings.ToLower(strings.TrimSpace(transfer.Reason))
+	if transfer.Reason == "" {
+		transfer.Reason = domain.AssignmentReasonMutation
+	}
+	if !domain.IsValidAssignmentReason(transfer.Reason) {
+		return nil, &utils.BadRequestError{Message: "reason must be one of mutasi, promotion, secondment"}
+	}
+	transfer.Status = domain.TransferStatusDraft
+	transfer.CreatedBy = principal.UserID
+	transfer.DecreeNumber = ""
+	transfer.AssignmentID = nil
+	decision := &domain.TransferDecision{ToStatus: domain.TransferStatusDraft, ActorID: principal.UserID, Comment: comment}
+	// satu employee hanya boleh memiliki satu usulan mutasi yang masih berjalan
+	_, err = uc.transferRepo.Save(transfer, decision)
+	if errors.Is(err, domain.ErrTransferOpenExists) {
+		return nil, &utils.ConflictError{Message: "employee already has an open transfer request"}
+	}
+	if err != nil {
+		fmt.Println("Error saving transfer request:", err)
+		return nil, &utils.InternalServerError{Message: "failed to create transfer request"}
+	}
+	utils.RecordAudit(uc.auditRepo, meta.Entry(principal.UserID, domain.AuditActionCreate, domain.AuditEntityTransfer, transfer.ID), nil, transfer)
+	return uc.GetByID(principal, transfer.ID)
+}
+
+// GetAll mengembalikan transfer yang melibatkan proposer: kepegawaian melihat semua,
+// kepala unit melihat transfer dari atau ke unitnya, employee melihat transfer dirinya
+func (uc *TransferUsecase) GetAll(principal *domain.Principal, status string) ([]domain.TransferRequest, error) {
+	if status != "" && !isTransferStatus(status) {
+		return nil, &utils.BadRequestError{Message: "status must be one of draft, proposed, accepted, rejected, executed"}
+	}
+	transfers, err := uc.transferRepo.FindAll(status)
+	if err != nil {
+		fmt.Println("Error getting transfer requests:", err)
+		return nil, &utils.InternalServerError{Message: "failed to get transfer requests"}
+	}
+	hr, err := uc.isHR(principal)
+	if err != nil {
+		return nil, err
+	}
+	if hr {
+		return transfers, nil
+	}
+	heads, err := uc.headUnits(principal.UserID)
+	if err != nil {
+		return nil, err
+	}
+	visible := []domain.TransferRequest{}
+	for _, transfer := range transfers {
+		if transfer.EmployeeID == principal.UserID || heads[transfer.FromUnitID] || heads[transfer.ToUnitID] {
+			visible = append(visible, transfer)
+		}
+	}
+	return visible, nil
+}
+
+// GetByID mengembalikan transfer beserta seluruh keputusan yang sudah dibuat
+func (uc *TransferUsecase) GetByID(principal *domain.Principal, id int) (*domain.TransferRequest, error) {
+	transfer, err := uc.find(id)
+	if err != nil {
+		return nil, err
+	}
+	hr, err := uc.isHR(principal)
+	if err != nil {
+		return nil, err
+	}
+	if !hr && transfer.EmployeeID != principal.UserID {
+		heads, err := uc.headUnits(principal.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if !heads[transfer.FromUnitID] && !heads[transfer.ToUnitID] {
+			return nil, &utils.UnauthorizedError{Message: "you are not involved in this transfer request"}
+		}
+	}
+	transfer.Decisions, err = uc.transferRepo.FindDecisions(transfer.ID)
+	if err != nil {
+		fmt.Println("Error getting transfer decisions:", err)
+		return nil, &utils.InternalServerError{Message: "failed to get transfer decisions"}
+	}
+	return transfer, nil
+}
+
+// Propose mengajukan draft ke kepala unit tujuan, dilakukan oleh kepala unit asal
+func (uc *TransferUsecase) Propose(principal *domain.Principal, id int, comment string, meta domain.AuditMeta) (*domain.TransferRequest, error) {
+	transfer, comment, err := uc.prepare(id, comment, domain.TransferStatusDraft)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.requireHead(principal.UserID, transfer.FromUnitID, "origin"); err != nil {
+		return nil, err
+	}
+	return uc.decide(principal.UserID, transfer, domain.TransferStatusProposed, comment, meta)
+}
+
+// Accept menerima usulan mutasi, dilakukan oleh kepala unit tujuan
+func (uc *TransferUsecase) Accept(principal *domain.Principal, id int, comment string, meta domain.AuditMeta) (*domain.TransferRequest, error) {
+	transfer, comment, err := uc.prepare(id, comment, domain.TransferStatusProposed)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.requireHead(principal.UserID, transfer.ToUnitID, "destination"); err != nil {
+		return nil, err
+	}
+	return uc.decide(principal.UserID, transfer, domain.TransferStatusAccepted, comment, meta)
+}
+
+// Reject menolak transfer sesuai tahapannya: draft oleh kepala unit asal, proposed oleh
+// kepala unit tujuan, accepted oleh kepegawaian
+func (uc *TransferUsecase) Reject(principal *domain.Principal, id int, comment string, meta domain.AuditMeta) (*domain.TransferRequest, error) {
+	transfer, comment, err := uc.prepare(id, comment, domain.TransferStatusDraft, domain.TransferStatusProposed, domain.TransferStatusAccepted)
+	if err != nil {
+		return nil, err
+	}
+	switch transfer.Status {
+	case domain.TransferStatusDraft:
+		err = uc.requireHead(principal.UserID, transfer.FromUnitID, "origin")
+	case domain.TransferStatusProposed:
+		err = uc.requireHead(principal.UserID, transfer.ToUnitID, "destination")
+	case domain.TransferStatusAccepted:
+		err = uc.requireHR(principal)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return uc.decide(principal.UserID, transfer, domain.TransferStatusRejected, comment, meta)
+}
+
+// Execute memfinalisasi mutasi yang sudah diterima menjadi assignment baru, dilakukan oleh kepegawaian
+func (uc *TransferUsecase) Execute(principal *domain.Principal, id int, decreeNumber string, overrideFormation bool, comment string, meta domain.AuditMeta) (*domain.TransferRequest, error) {
+	transfer, comment, err := uc.prepare(id, comment, domain.TransferStatusAccepted)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.requireHR(principal); err != nil {
+		return nil, err
+	}
+	decreeNumber = strings.TrimSpace(decreeNumber)
+	if decreeNumber == "" {
+		return nil, &utils.BadRequestError{Message: "decree_number is required"}
+	}
+	// employee bisa saja sudah dipindahkan lewat jalur lain sejak usulan dibuat
+	current, err := uc.primaryAssignment(transfer.EmployeeID)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil || current.UnitID != transfer.FromUnitID {
+		return nil, &utils.ConflictError{Message: "employee is no longer assigned to the origin unit"}
+	}
+	if !transfer.StartDate.After(current.StartDate) {
+		return nil, &utils.ConflictError{Message: "start_date must be after the current assignment start date"}
+	}
+
+	assignment := &domain.EmployeeAssignment{
+		EmployeeID:        transfer.EmployeeID,
+		UnitID:            transfer.ToUnitID,
+		PositionID:        transfer.ToPositionID,
+		StartDate:         domain.NewDate(transfer.StartDate),
+		DecreeNumber:      decreeNumber,
+		Reason:            transfer.Reason,
+		Type:              domain.AssignmentTypePrimary,
+		OverrideFormation: overrideFormation,
+	}
+	before := *transfer
+	transfer.DecreeNumber = decreeNumber
+	decision := &domain.TransferDecision{
+		FromStatus: transfer.Status,
+		ToStatus:   domain.TransferStatusExecuted,
+		ActorID:    principal.UserID,
+		Comment:    comment,
+	}
+	// assignment dan status executed disimpan dalam satu transaksi yang hanya berjalan jika status masih accepted
+	err = uc.transferRepo.Execute(transfer, decision, assignment)
+	if errors.Is(err, domain.ErrTransferStatusChanged) {
+		return nil, &utils.ConflictError{Message: "transfer request has been changed by another user"}
+	}
+	if errors.Is(err, domain.ErrFormationFull) {
+		return nil, &utils.ConflictError{Message: "formation for the destination unit and position is full, set override_formation to execute anyway"}
+	}
+	if errors.Is(err, domain.ErrAssignmentOverlap) {
+		return nil, &utils.ConflictError{Message: "start_date overlaps with another primary assignment"}
+	}
+	if err != nil {
+		fmt.Println("Error executing transfer request:", err)
+		return nil, &utils.InternalServerError{Message: "failed to execute transfer request"}
+	}
+	utils.RecordAudit(uc.auditRepo, meta.Entry(principal.UserID, domain.AuditActionAssign, domain.AuditEntityAssignment, assignment.ID), current, assignment)
+	return uc.decided(principal.UserID, &before, transfer, meta)
+}
+
+// ==================================================================== UTILITIES ====================================================================
+
+func requireComment(comment string) (string, error) {
+	comment = strings.TrimSpace(comment)
+	if comment == "" {
+		return "", &utils.BadRequestError{Message: "comment is required"}
+	}
+	return comment, nil
+}
+
+func isTransferStatus(status string) bool {
+	switch status {
+	case domain.TransferStatusDraft, domain.TransferStatusProposed, domain.TransferStatusAccepted,
+		domain.TransferStatusRejected, domain.TransferStatusExecuted:
+		return true
+	}
+	return false
+}
+
+func (uc *TransferUsecase) find(id int) (*domain.TransferRequest, error) {
+	transfer, err := uc.transferRepo.FindByID(id)
+	if err != nil {
+		return nil, &utils.NotFoundError{Message: "transfer request not found"}
+	}
+	return transfer, nil
+}
+
+// prepare memvalidasi komentar dan memastikan transfer berada pada salah satu status asal
+func (uc *TransferUsecase) prepare(id int, comment string, fromStatuses ...string) (*domain.TransferRequest, string, error) {
+	comment, err := requireComment(comment)
+	if err != nil {
+		return nil, "", err
+	}
+	transfer, err := uc.find(id)
+	if err != nil {
+		return nil, "", err
+	}
+	for _, status := range fromStatuses {
+		if transfer.Status == status {
+			return transfer, comment, nil
+		}
+	}
+	return nil, "", &utils.ConflictError{Message: fmt.Sprintf("transfer request is %s", transfer.Status)}
+}
+
+// decide menyimpan perpindahan status beserta komentar lalu mengembalikan transfer terbaru
+func (uc *TransferUsecase) decide(proposerId string, transfer *domain.TransferRequest, toStatus string, comment string, meta domain.AuditMeta) (*domain.TransferRequest, error) {
+	before := *transfer
+	decision := &domain.TransferDecision{
+		FromStatus: transfer.Status,
+		ToStatus:   toStatus,
+		ActorID:    proposerId,
+		Comment:    comment,
+	}
+	err := uc.transferRepo.Transition(transfer, decision)
+	if errors.Is(err, domain.ErrTransferStatusChanged) {
+		return nil, &utils.ConflictError{Message: "transfer request has been changed by another user"}
+	}
+	if err != nil {
+		fmt.Println("Error updating transfer request:", err)
+		return nil, &utils.InternalServerError{Message: "failed to update transfer request"}
+	}
+	return uc.decided(proposerId, &before, transfer, meta)
+}
+
+// decided mencatat audit perpindahan status lalu mengembalikan transfer beserta seluruh keputusannya
+func (uc *TransferUsecase) decided(proposerId string, before *domain.TransferRequest, transfer *domain.TransferRequest, meta domain.AuditMeta) (*domain.TransferRequest, error) {
+	utils.RecordAudit(uc.auditRepo, meta.Entry(proposerId, domain.AuditActionStatus, domain.AuditEntityTransfer, transfer.ID), before, transfer)
+	decisions, err := uc.transferRepo.FindDecisions(transfer.ID)
+	if err != nil {
+		fmt.Println("Error getting transfer decisions:", err)
+		return nil, &utils.InternalServerError{Message: "failed to get transfer decisions"}
+	}
+	transfer.Decisions = decisions
+	return transfer, nil
+}
+
+// primaryAssignment mengembalikan jabatan definitif aktif employee, nil jika tidak ada
+func (uc *TransferUsecase) primaryAssignment(employeeID string) (*domain.EmployeeAssignmentResponse, error) {
+	current, err := uc.empAssignRepo.FindByEmployeeID(employeeID)
+	if err != nil {
+		fmt.Println("Error getting employee assignment:", err)
+		return nil, &utils.InternalServerError{Message: "failed to get employee assignment"}
+	}
+	for i := range current {
+		if current[i].Type == domain.AssignmentTypePrimary {
+			return &current[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// headUnits mengembalikan unit yang sedang dikepalai employee, termasuk sebagai Plt/Plh
+func (uc *TransferUsecase) headUnits(employeeID string) (map[int]bool, error) {
+	current, err := uc.empAssignRepo.FindByEmployeeID(employeeID)
+	if err != nil {
+		fmt.Println("Error getting employee assignment:", err)
+		return nil, &utils.InternalServerError{Message: "failed to get employee assignment"}
+	}
+	heads := map[int]bool{}
+	for _, assignment := range current {
+		if assignment.IsHead {
+			heads[assignment.UnitID] = true
+		}
+	}
+	return heads, nil
+}
+
+func (uc *TransferUsecase) requireHead(proposerId string, unitID int, side string) error {
+	heads, err := uc.headUnits(proposerId)
+	if err != nil {
+		return err
+	}
+	if !heads[unitID] {
+		return &utils.UnauthorizedError{Message: fmt.Sprintf("only the head of the %s unit can make this decision", side)}
+	}
+	return nil
+}
+
+// isHR kepegawaian adalah role yang boleh memutuskan mutasi ke seluruh unit
+func (uc *TransferUsecase) isHR(principal *domain.Principal) (bool, error) {
+	scope, err := uc.authz.Scope(principal, authorization.TransferReview)
+	if err != nil {
+		return false, err
+	}
+	return scope == authorization.ScopeGlobal, nil
+}
+
+func (uc *TransferUsecase) requireHR(principal *domain.Principal) error {
+	hr, err := uc.isHR(principal)
+	if err != nil {
+		return err
+	}
+	if !hr {
+		return &utils.UnauthorizedError{Message: "only HR can make this decision"}
+	}
+	return nil
+}
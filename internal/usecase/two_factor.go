@@ -0,0 +1,293 @@
+package usecase
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/achmadnr21/emploman/internal/authorization"
+	"github.com/achmadnr21/emploman/internal/domain"
+	"github.com/achmadnr21/emploman/internal/utils"
+	"github.com/skip2/go-qrcode"
+)
+
+const (
+	twoFactorIssuer        = "Emploman"
+	twoFactorRecoveryCodes = 10
+)
+
+type TwoFactorUsecase struct {
+	twoFactorRepo domain.TwoFactorInterface
+	empRepo       domain.EmployeeInterface
+	roleRepo      domain.RoleInterface
+	attemptRepo   domain.LoginAttemptInterface
+	tokenRepo     domain.RefreshTokenInterface
+	auditRepo     domain.AuditInterface
+	authz         *authorization.Authorizer
+}
+
+func NewTwoFactorUsecase(twoFactorRepo domain.TwoFactorInterface, empRepo domain.EmployeeInterface, roleRepo domain.RoleInterface, attemptRepo domain.LoginAttemptInterface, tokenRepo domain.RefreshTokenInterface, auditRepo domain.AuditInterface, authz *authorization.Authorizer) *TwoFactorUsecase {
+	return &TwoFactorUsecase{
+		twoFactorRepo: twoFactorRepo,
+		empRepo:       empRepo,
+		roleRepo:      roleRepo,
+		attemptRepo:   attemptRepo,
+		tokenRepo:     tokenRepo,
+		auditRepo:     auditRepo,
+		authz:         authz,
+	}
+}
+
+func (uc *TwoFactorUsecase) GetStatus(proposerId string) (*domain.TwoFactorStatus, error) {
+	role, err := uc.roleRepo.FindByUserID(proposerId)
+	if err != nil {
+		return nil, &utils.UnauthorizedError{Message: "Failed to get user role"}
+	}
+	status := &domain.TwoFactorStatus{Required: role.Require2FA}
+	tf, err := uc.findTwoFactor(proposerId)
+	if err != nil {
+		return nil, err
+	}
+	if tf == nil || !tf.Enabled {
+		return status, nil
+	}
+	status.Enabled = true
+	status.ConfirmedAt = tf.ConfirmedAt
+	status.RecoveryCodesRemaining, err = uc.twoFactorRepo.CountRecoveryCodes(proposerId)
+	if err != nil {
+		fmt.Println("Error counting recovery codes:", err)
+		return nil, &utils.InternalServerError{Message: "failed to get two-factor status"}
+	}
+	return status, nil
+}
+
+// Enroll membuat secret baru yang belum aktif. 2FA baru aktif setelah Confirm dengan kode dari secret ini.
+func (uc *TwoFactorUsecase) Enroll(proposerId string) (*domain.TwoFactorEnrollment, error) {
+	proposer, err := uc.empRepo.FindByID(proposerId)
+	if err != nil {
+		return nil, &utils.NotFoundError{Message: "user not found"}
+	}
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, &utils.InternalServerError{Message: "failed to generate secret"}
+	}
+	if err := uc.twoFactorRepo.SavePending(proposer.ID, secret); err != nil {
+		if errors.Is(err, domain.ErrTwoFactorEnabled) {
+			return nil, &utils.ConflictError{Message: "two-factor authentication is already enabled"}
+		}
+		fmt.Println("Error saving two-factor secret:", err)
+		return nil, &utils.InternalServerError{Message: "failed to start two-factor enrolment"}
+	}
+	uri := utils.TOTPURI(twoFactorIssuer, proposer.NIP, secret)
+	png, err := qrcode.Encode(uri, qrcode.Medium, 256)
+	if err != nil {
+		return nil, &utils.InternalServerError{Message: "failed to generate QR code"}
+	}
+	return &domain.TwoFactorEnrollment{
+		Secret:     secret,
+		OtpauthURL: uri,
+		QRCode:     "data:image/png;base64," + base64.StdEncoding.EncodeToString(png),
+	}, nil
+}
+
+// Confirm mengaktifkan 2FA dan mengembalikan recovery code. Recovery code hanya ditampilkan sekali.
+func (uc *TwoFactorUsecase) Confirm(proposerId string, code string, meta domain.AuditMeta) ([]string, error) {
+	tf, err := uc.findTwoFactor(proposerId)
+	if err != nil {
+		return nil, err
+	}
+	if tf == nil {
+		return nil, &utils.BadRequestError{Message: "two-factor enrolment has not been started"}
+	}
+	if tf.Enabled {
+		return nil, &utils.ConflictError{Message: "two-factor authentication is already enabled"}
+	}
+	step, valid := utils.VerifyTOTP(tf.Secret, code, time.Now())
+	if !valid {
+		return nil, &utils.BadRequestError{Message: "invalid two-factor code"}
+	}
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, &utils.InternalServerError{Message: "failed to generate recovery codes"}
+	}
+	enabled, err := uc.twoFactorRepo.Enable(proposerId, step, hashes)
+	if err != nil {
+		fmt.Println("Error enabling two-factor:", err)
+		return nil, &utils.InternalServerError{Message: "failed to enable two-factor authentication"}
+	}
+	if !enabled {
+		return nil, &utils.ConflictError{Message: "two-factor enrolment has changed, please try again"}
+	}
+	utils.RecordAudit(uc.auditRepo, meta.Entry(proposerId, domain.AuditActionCreate, domain.AuditEntityTwoFactor, proposerId), nil, nil)
+	return codes, nil
+}
+
+// Disable mematikan 2FA milik sendiri dengan kode TOTP atau recovery code, kecuali role mewajibkannya.
+// Seluruh sesi dicabut karena sesi tersebut login dengan 2FA yang sudah tidak berlaku.
+func (uc *TwoFactorUsecase) Disable(proposerId string, payload domain.TwoFactorCode, meta domain.AuditMeta) error {
+	role, err := uc.roleRepo.FindByUserID(proposerId)
+	if err != nil {
+		return &utils.UnauthorizedError{Message: "Failed to get user role"}
+	}
+	if role.Require2FA {
+		return &utils.BadRequestError{Message: "your role requires two-factor authentication"}
+	}
+	if err := uc.verifyCode(proposerId, payload, meta.IPAddress); err != nil {
+		return err
+	}
+	if err := uc.twoFactorRepo.Delete(proposerId); err != nil {
+		fmt.Println("Error disabling two-factor:", err)
+		return &utils.InternalServerError{Message: "failed to disable two-factor authentication"}
+	}
+	if err := uc.tokenRepo.RevokeAllByEmployeeID(proposerId); err != nil {
+		fmt.Println("Error revoking refresh tokens:", err)
+		return &utils.InternalServerError{Message: "failed to revoke refresh tokens"}
+	}
+	utils.RecordAudit(uc.auditRepo, meta.Entry(proposerId, domain.AuditActionDelete, domain.AuditEntityTwoFactor, proposerId), nil, nil)
+	return nil
+}
+
+// RegenerateRecoveryCodes mengganti seluruh recovery code, hanya dengan kode TOTP
+func (uc *TwoFactorUsecase) RegenerateRecoveryCodes(proposerId string, code string, meta domain.AuditMeta) ([]string, error) {
+	if err := uc.verifyCode(proposerId, domain.TwoFactorCode{Code: code}, meta.IPAddress); err != nil {
+		return nil, err
+	}
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, &utils.InternalServerError{Message: "failed to generate recovery codes"}
+	}
+	if err := uc.twoFactorRepo.ReplaceRecoveryCodes(proposerId, hashes); err != nil {
+		fmt.Println("Error replacing recovery codes:", err)
+		return nil, &utils.InternalServerError{Message: "failed to generate recovery codes"}
+	}
+	utils.RecordAudit(uc.auditRepo, meta.Entry(proposerId, domain.AuditActionUpdate, domain.AuditEntityTwoFactor, proposerId), nil, nil)
+	return codes, nil
+}
+
+// ResetByAdmin menghapus 2FA employee lain yang kehilangan perangkat dan recovery code.
+// Jika role employee mewajibkan 2FA, login berikutnya akan diarahkan untuk enrolment ulang.
+func (uc *TwoFactorUsecase) ResetByAdmin(principal *domain.Principal, nip string, meta domain.AuditMeta) error {
+	if err := uc.authz.Authorize(principal, authorization.EmployeeTwoFactorReset, authorization.Resource{}); err != nil {
+		return err
+	}
+	employee, err := uc.empRepo.FindByNIP(nip)
+	if err != nil {
+		return &utils.NotFoundError{Message: "employee not found"}
+	}
+	if employee.ID == principal.UserID {
+		return &utils.BadRequestError{Message: "cannot reset your own two-factor authentication"}
+	}
+	tf, err := uc.findTwoFactor(employee.ID)
+	if err != nil {
+		return err
+	}
+	if tf == nil {
+		return &utils.NotFoundError{Message: "two-factor authentication is not enabled"}
+	}
+	if err := uc.twoFactorRepo.Delete(employee.ID); err != nil {
+		fmt.Println("Error resetting two-factor:", err)
+		return &utils.InternalServerError{Message: "failed to reset two-factor authentication"}
+	}
+	utils.RecordAudit(uc.auditRepo, meta.Entry(principal.UserID, domain.AuditActionDelete, domain.AuditEntityTwoFactor, employee.ID), tf, nil)
+	return nil
+}
+
+// ==================================================================== UTILITIES ====================================================================
+
+// findTwoFactor mengembalikan nil tanpa error jika employee belum pernah enrolment
+func (uc *TwoFactorUsecase) findTwoFactor(employeeID string) (*domain.TwoFactor, error) {
+	tf, err := uc.twoFactorRepo.FindByEmployeeID(employeeID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		fmt.Println("Error getting two-factor:", err)
+		return nil, &utils.InternalServerError{Message: "failed to get two-factor status"}
+	}
+	return tf, nil
+}
+
+// verifyCode memeriksa kode 2FA milik sendiri dengan pembatasan yang sama seperti VerifyTwoFactor saat login,
+// kode yang salah dihitung sebagai kegagalan login nip dan ip tersebut
+func (uc *TwoFactorUsecase) verifyCode(employeeID string, payload domain.TwoFactorCode, ip string) error {
+	employee, err := uc.empRepo.FindByID(employeeID)
+	if err != nil {
+		return &utils.UnauthorizedError{Message: "account is inactive"}
+	}
+	nipKey := domain.LoginAttemptKeyNIP + employee.NIP
+	ipKey := domain.LoginAttemptKeyIP + ip
+	if err := checkLoginThrottle(uc.attemptRepo, []string{nipKey, ipKey}); err != nil {
+		return err
+	}
+	valid, err := verifyTwoFactorCode(uc.twoFactorRepo, employeeID, payload)
+	if err != nil {
+		return err
+	}
+	if !valid {
+		recordLoginFailure(uc.attemptRepo, nipKey, loginLockoutNIP)
+		recordLoginFailure(uc.attemptRepo, ipKey, loginLockoutIP)
+		return &utils.BadRequestError{Message: "invalid two-factor code"}
+	}
+	if err := uc.attemptRepo.Reset(nipKey); err != nil {
+		fmt.Println("Error resetting login attempts:", err)
+	}
+	return nil
+}
+
+// verifyTwoFactorCode memeriksa kode TOTP atau recovery code milik employee dengan 2FA aktif.
+// Kode yang diterima langsung ditandai terpakai agar tidak bisa dipakai ulang.
+func verifyTwoFactorCode(repo domain.TwoFactorInterface, employeeID string, payload domain.TwoFactorCode) (bool, error) {
+	tf, err := repo.FindByEmployeeID(employeeID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, &utils.BadRequestError{Message: "two-factor authentication is not enabled"}
+	}
+	if err != nil {
+		fmt.Println("Error getting two-factor:", err)
+		return false, &utils.InternalServerError{Message: "failed to verify two-factor code"}
+	}
+	if !tf.Enabled {
+		return false, &utils.BadRequestError{Message: "two-factor authentication is not enabled"}
+	}
+	if payload.RecoveryCode != "" {
+		used, err := repo.UseRecoveryCode(employeeID, utils.HashToken(normalizeRecoveryCode(payload.RecoveryCode)))
+		if err != nil {
+			fmt.Println("Error using recovery code:", err)
+			return false, &utils.InternalServerError{Message: "failed to verify two-factor code"}
+		}
+		return used, nil
+	}
+	step, valid := utils.VerifyTOTP(tf.Secret, payload.Code, time.Now())
+	if !valid || step <= tf.LastUsedStep {
+		return false, nil
+	}
+	used, err := repo.UseStep(employeeID, step)
+	if err != nil {
+		fmt.Println("Error using two-factor step:", err)
+		return false, &utils.InternalServerError{Message: "failed to verify two-factor code"}
+	}
+	return used, nil
+}
+
+// generateRecoveryCodes membuat recovery code berformat xxxxx-xxxxx beserta hash untuk disimpan
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, twoFactorRecoveryCodes)
+	hashes := make([]string, twoFactorRecoveryCodes)
+	for i := range codes {
+		random, err := utils.GenerateRandomID(5)
+		if err != nil {
+			return nil, nil, err
+		}
+		codes[i] = random[:5] + "-" + random[5:]
+		hashes[i] = utils.HashToken(random)
+	}
+	return codes, hashes, nil
+}
+
+// normalizeRecoveryCode menerima recovery code dengan atau tanpa tanda hubung dan huruf besar
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.ReplaceAll(code, "-", "")
+}
@@ -1,19 +1,25 @@
 package usecase
 
 import (
+	"errors"
+	"fmt"
+
+	"github.com/achmadnr21/emploman/internal/authorization"
 	"github.com/achmadnr21/emploman/internal/domain"
 	"github.com/achmadnr21/emploman/internal/utils"
 )
 
 type UnitUsecase struct {
-	unitRepo domain.UnitInterface
-	roleRepo domain.RoleInterface
+	unitRepo  domain.UnitInterface
+	authz     *authorization.Authorizer
+	auditRepo domain.AuditInterface
 }
 
-func NewUnitUsecase(unitRepo domain.UnitInterface, roleRepo domain.RoleInterface) *UnitUsecase {
+func NewUnitUsecase(unitRepo domain.UnitInterface, authz *authorization.Authorizer, auditRepo domain.AuditInterface) *UnitUsecase {
 	return &UnitUsecase{
-		unitRepo: unitRepo,
-		roleRepo: roleRepo,
+		unitRepo:  unitRepo,
+		authz:     authz,
+		auditRepo: auditRepo,
 	}
 }
 
@@ -24,15 +30,9 @@ func (uc *UnitUsecase) GetAllUnit() ([]domain.Unit, error) {
 	}
 	return units, nil
 }
-func (uc *UnitUsecase) AddUnit(proposerId string, unit *domain.Unit) (*domain.Unit, error) {
-	// cek proposer role.
-	proposer, err := uc.roleRepo.FindByUserID(proposerId)
-	if err != nil {
-		return nil, &utils.NotFoundError{Message: "user not found"}
-	}
-	// check if proposer can add unit
-	if !proposer.CanAddUnit {
-		return nil, &utils.UnauthorizedError{Message: "user not authorized to add unit"}
+func (uc *UnitUsecase) AddUnit(principal *domain.Principal, unit *domain.Unit, meta domain.AuditMeta) (*domain.Unit, error) {
+	if err := uc.authz.Authorize(principal, authorization.UnitManage, authorization.Resource{}); err != nil {
+		return nil, err
 	}
 	// checking all fillable
 	if unit.Name == "" || len(unit.Name) < 5 {
@@ -44,24 +44,26 @@ func (uc *UnitUsecase) AddUnit(proposerId string, unit *domain.Unit) (*domain.Un
 	if unit.Description == "" || len(unit.Description) < 5 {
 		return nil, &utils.BadRequestError{Message: "unit description is required and must be at least 5 characters"}
 	}
+	// parent_id 0 berarti unit teratas
+	if unit.ParentID != nil && *unit.ParentID == 0 {
+		unit.ParentID = nil
+	}
+	if unit.ParentID != nil {
+		if _, err := uc.unitRepo.FindByID(*unit.ParentID); err != nil {
+			return nil, &utils.NotFoundError{Message: "parent unit not found"}
+		}
+	}
 	newunit, err := uc.unitRepo.Save(unit)
 	if err != nil {
 		return nil, err
 	}
+	utils.RecordAudit(uc.auditRepo, meta.Entry(principal.UserID, domain.AuditActionCreate, domain.AuditEntityUnit, newunit.ID), nil, newunit)
 	return newunit, nil
 }
 
-func (uc *UnitUsecase) UpdateUnit(proposerId string, unit *domain.Unit) (*domain.Unit, error) {
-	// cek proposer role.
-
-	proposer, err := uc.roleRepo.FindByUserID(proposerId)
-
-	if err != nil {
-		return nil, &utils.NotFoundError{Message: "user not found"}
-	}
-	// check if proposer can add unit
-	if !proposer.CanAddUnit {
-		return nil, &utils.UnauthorizedError{Message: "user not authorized to add unit"}
+func (uc *UnitUsecase) UpdateUnit(principal *domain.Principal, unit *domain.Unit, meta domain.AuditMeta) (*domain.Unit, error) {
+	if err := uc.authz.Authorize(principal, authorization.UnitManage, authorization.Resource{}); err != nil {
+		return nil, err
 	}
 
 	// get unit by id
@@ -69,6 +71,7 @@ func (uc *UnitUsecase) UpdateUnit(proposerId string, unit *domain.Unit) (*domain
 	if err != nil {
 		return nil, &utils.NotFoundError{Message: "unit not found"}
 	}
+	before := *oldunit
 	// checking all fillable
 	if unit.Name != "" && len(unit.Name) > 5 {
 		oldunit.Name = unit.Name
@@ -79,27 +82,47 @@ func (uc *UnitUsecase) UpdateUnit(proposerId string, unit *domain.Unit) (*domain
 	if unit.Address != "" && len(unit.Address) > 10 {
 		oldunit.Address = unit.Address
 	}
+	// parent_id tidak diisi berarti tetap, 0 berarti dipindah menjadi unit teratas
+	if unit.ParentID != nil {
+		if err := uc.checkParent(*unit.ParentID); err != nil {
+			return nil, err
+		}
+		oldunit.ParentID = unit.ParentID
+		if *unit.ParentID == 0 {
+			oldunit.ParentID = nil
+		}
+	}
 
 	newunit, err := uc.unitRepo.Update(oldunit)
+	if errors.Is(err, domain.ErrUnitCycle) {
+		return nil, &utils.BadRequestError{Message: err.Error()}
+	}
 	if err != nil {
 		return nil, err
 	}
+	utils.RecordAudit(uc.auditRepo, meta.Entry(principal.UserID, domain.AuditActionUpdate, domain.AuditEntityUnit, newunit.ID), &before, newunit)
 	return newunit, nil
 }
-func (uc *UnitUsecase) DeleteUnit(proposerId string, id int) error {
-	// cek proposer role.
-	proposer, err := uc.roleRepo.FindByUserID(proposerId)
+func (uc *UnitUsecase) DeleteUnit(principal *domain.Principal, id int, meta domain.AuditMeta) error {
+	if err := uc.authz.Authorize(principal, authorization.UnitManage, authorization.Resource{}); err != nil {
+		return err
+	}
+	oldunit, err := uc.unitRepo.FindByID(id)
 	if err != nil {
-		return &utils.NotFoundError{Message: "user not found"}
+		return &utils.NotFoundError{Message: "unit not found"}
 	}
-	// check if proposer can add unit
-	if !proposer.CanAddUnit {
-		return &utils.UnauthorizedError{Message: "user not authorized to add unit"}
+	subtree, err := uc.unitRepo.FindSubtreeIDs(id)
+	if err != nil {
+		return &utils.InternalServerError{Message: "failed to check sub units"}
+	}
+	if len(subtree) > 1 {
+		return &utils.BadRequestError{Message: "unit still has sub units"}
 	}
 	err = uc.unitRepo.Delete(id)
 	if err != nil {
 		return &utils.NotFoundError{Message: "unit not found"}
 	}
+	utils.RecordAudit(uc.auditRepo, meta.Entry(principal.UserID, domain.AuditActionDelete, domain.AuditEntityUnit, id), oldunit, nil)
 	return nil
 }
 
@@ -118,3 +141,41 @@ func (uc *UnitUsecase) SearchUnit(input string) ([]domain.Unit, error) {
 	}
 	return units, nil
 }
+
+// GetUnitTree mengembalikan unit beserta seluruh sub unit secara bertingkat untuk bagan organisasi
+func (uc *UnitUsecase) GetUnitTree(id int) (*domain.UnitTree, error) {
+	units, err := uc.unitRepo.FindSubtree(id)
+	if err != nil {
+		fmt.Println("Error getting unit subtree:", err)
+		return nil, &utils.InternalServerError{Message: "failed to get unit tree"}
+	}
+	if len(units) == 0 {
+		return nil, &utils.NotFoundError{Message: "unit not found"}
+	}
+	// units urut berdasarkan kedalaman, sehingga parent selalu sudah ada sebelum child
+	nodes := make(map[int]*domain.UnitTree, len(units))
+	for i := range units {
+		node := &units[i]
+		node.Children = []*domain.UnitTree{}
+		nodes[node.ID] = node
+		if node.Depth > 0 && node.ParentID != nil {
+			if parent, ok := nodes[*node.ParentID]; ok {
+				parent.Children = append(parent.Children, node)
+			}
+		}
+	}
+	return &units[0], nil
+}
+
+// ==================================================================== UTILITIES ====================================================================
+
+// checkParent memastikan parent ada, pengecekan siklus dilakukan unitRepo.Update dalam transaksi yang sama dengan perubahan
+func (uc *UnitUsecase) checkParent(parentID int) error {
+	if parentID == 0 {
+		return nil
+	}
+	if _, err := uc.unitRepo.FindByID(parentID); err != nil {
+		return &utils.NotFoundError{Message: "parent unit not found"}
+	}
+	return nil
+}
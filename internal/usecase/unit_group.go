@@ -0,0 +1,144 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/achmadnr21/emploman/internal/authorization"
+	"github.com/achmadnr21/emploman/internal/domain"
+	"github.com/achmadnr21/emploman/internal/utils"
+)
+
+type UnitGroupUsecase struct {
+	groupRepo domain.UnitGroupInterface
+	unitRepo  domain.UnitInterface
+	authz     *authorization.Authorizer
+	auditRepo domain.AuditInterface
+}
+
+func NewUnitGroupUsecase(groupRepo domain.UnitGroupInterface, unitRepo domain.UnitInterface, authz *authorization.Authorizer, auditRepo domain.AuditInterface) *UnitGroupUsecase {
+	return &UnitGroupUsecase{
+		groupRepo: groupRepo,
+		unitRepo:  unitRepo,
+		authz:     authz,
+		auditRepo: auditRepo,
+	}
+}
+
+func (uc *UnitGroupUsecase) GetAll(principal *domain.Principal) ([]domain.UnitGroup, error) {
+	if err := uc.authz.Authorize(principal, authorization.UnitScopeRead, authorization.Resource{}); err != nil {
+		return nil, err
+	}
+	groups, err := uc.groupRepo.FindAll()
+	if err != nil {
+		fmt.Println("Error getting unit groups:", err)
+		return nil, &utils.InternalServerError{Message: "failed to get unit groups"}
+	}
+	return groups, nil
+}
+
+func (uc *UnitGroupUsecase) GetByID(principal *domain.Principal, id int) (*domain.UnitGroup, error) {
+	if err := uc.authz.Authorize(principal, authorization.UnitScopeRead, authorization.Resource{}); err != nil {
+		return nil, err
+	}
+	group, err := uc.groupRepo.FindByID(id)
+	if err != nil {
+		return nil, &utils.NotFoundError{Message: "unit group not found"}
+	}
+	return group, nil
+}
+
+func (uc *UnitGroupUsecase) AddUnitGroup(principal *domain.Principal, group *domain.UnitGroup, meta domain.AuditMeta) (*domain.UnitGroup, error) {
+	if err := uc.authorize(principal); err != nil {
+		return nil, err
+	}
+	if err := uc.validate(group); err != nil {
+		return nil, err
+	}
+	saved, err := uc.groupRepo.Save(group)
+	if errors.Is(err, domain.ErrUnitGroupExists) {
+		return nil, &utils.ConflictError{Message: err.Error()}
+	}
+	if err != nil {
+		fmt.Println("Error saving unit group:", err)
+		return nil, &utils.InternalServerError{Message: "failed to add unit group"}
+	}
+	utils.RecordAudit(uc.auditRepo, meta.Entry(principal.UserID, domain.AuditActionCreate, domain.AuditEntityUnitGroup, saved.ID), nil, saved)
+	return saved, nil
+}
+
+// UpdateUnitGroup mengganti nama dan seluruh anggota grup, unit scope yang memakai grup langsung mengikuti anggota baru
+func (uc *UnitGroupUsecase) UpdateUnitGroup(principal *domain.Principal, group *domain.UnitGroup, meta domain.AuditMeta) (*domain.UnitGroup, error) {
+	if err := uc.authorize(principal); err != nil {
+		return nil, err
+	}
+	before, err := uc.groupRepo.FindByID(group.ID)
+	if err != nil {
+		return nil, &utils.NotFoundError{Message: "unit group not found"}
+	}
+	if err := uc.validate(group); err != nil {
+		return nil, err
+	}
+	updated, err := uc.groupRepo.Update(group)
+	if errors.Is(err, domain.ErrUnitGroupExists) {
+		return nil, &utils.ConflictError{Message: err.Error()}
+	}
+	if err != nil {
+		fmt.Println("Error updating unit group:", err)
+		return nil, &utils.InternalServerError{Message: "failed to update unit group"}
+	}
+	utils.RecordAudit(uc.auditRepo, meta.Entry(principal.UserID, domain.AuditActionUpdate, domain.AuditEntityUnitGroup, updated.ID), before, updated)
+	return updated, nil
+}
+
+func (uc *UnitGroupUsecase) DeleteUnitGroup(principal *domain.Principal, id int, meta domain.AuditMeta) error {
+	if err := uc.authorize(principal); err != nil {
+		return err
+	}
+	before, err := uc.groupRepo.FindByID(id)
+	if err != nil {
+		return &utils.NotFoundError{Message: "unit group not found"}
+	}
+	err = uc.groupRepo.Delete(id)
+	if errors.Is(err, domain.ErrUnitGroupInUse) {
+		return &utils.ConflictError{Message: err.Error()}
+	}
+	if err != nil {
+		return &utils.NotFoundError{Message: "unit group not found"}
+	}
+	utils.RecordAudit(uc.auditRepo, meta.Entry(principal.UserID, domain.AuditActionDelete, domain.AuditEntityUnitGroup, id), before, nil)
+	return nil
+}
+
+// ==================================================================== UTILITIES ====================================================================
+
+// authorize unit group hanya dikelola oleh pemegang permission unit_scope.manage
+func (uc *UnitGroupUsecase) authorize(principal *domain.Principal) error {
+	return uc.authz.Authorize(principal, authorization.UnitScopeManage, authorization.Resource{})
+}
+
+// validate memastikan nama terisi dan seluruh anggota grup adalah unit yang ada, anggota ganda diabaikan
+func (uc *UnitGroupUsecase) validate(group *domain.UnitGroup) error {
+	group.Name = strings.TrimSpace(group.Name)
+	if group.Name == "" {
+		return &utils.BadRequestError{Message: "unit group name cannot be empty"}
+	}
+	if len(group.UnitIDs) == 0 {
+		return &utils.BadRequestError{Message: "unit group must have at least one unit"}
+	}
+	seen := map[int]bool{}
+	unitIDs := []int{}
+	for _, unitID := range group.UnitIDs {
+		if seen[unitID] {
+			continue
+		}
+		seen[unitID] = true
+		if _, err := uc.unitRepo.FindByID(unitID); err != nil {
+			return &utils.NotFoundError{Message: fmt.Sprintf("unit %d not found", unitID)}
+		}
+		unitIDs = append(unitIDs, unitID)
+	}
+	group.UnitIDs = unitIDs
+	return nil
+}
@@ -0,0 +1,128 @@
+package usecase
+
+import (
+	"fmt"
+
+	"github.com/achmadnr21/emploman/internal/authorization"
+	"github.com/achmadnr21/emploman/internal/domain"
+	"github.com/achmadnr21/emploman/internal/utils"
+)
+
+type UnitScopeUsecase struct {
+	scopeRepo domain.UnitScopeInterface
+	empRepo   domain.EmployeeInterface
+	roleRepo  domain.RoleInterface
+	unitRepo  domain.UnitInterface
+	groupRepo domain.UnitGroupInterface
+	auditRepo domain.AuditInterface
+	authz     *authorization.Authorizer
+}
+
+func NewUnitScopeUsecase(scopeRepo domain.UnitScopeInterface, empRepo domain.EmployeeInterface, roleRepo domain.RoleInterface, unitRepo domain.UnitInterface, groupRepo domain.UnitGroupInterface, auditRepo domain.AuditInterface, authz *authorization.Authorizer) *UnitScopeUsecase {
+	return &UnitScopeUsecase{
+		scopeRepo: scopeRepo,
+		empRepo:   empRepo,
+		roleRepo:  roleRepo,
+		unitRepo:  unitRepo,
+		groupRepo: groupRepo,
+		auditRepo: auditRepo,
+		authz:     authz,
+	}
+}
+
+func (uc *UnitScopeUsecase) GetByEmployeeID(principal *domain.Principal, employeeID string) ([]domain.UnitScopeGrant, error) {
+	// employee boleh melihat scope miliknya sendiri
+	if err := uc.authz.Authorize(principal, authorization.UnitScopeRead, authorization.Employee(employeeID)); err != nil {
+		return nil, err
+	}
+	grants, err := uc.scopeRepo.FindByEmployeeID(employeeID)
+	if err != nil {
+		fmt.Println("Error getting unit scopes:", err)
+		return nil, &utils.InternalServerError{Message: "failed to get unit scopes"}
+	}
+	return grants, nil
+}
+
+func (uc *UnitScopeUsecase) Grant(principal *domain.Principal, grant *domain.UnitScopeGrant, meta domain.AuditMeta) (*domain.UnitScopeGrant, error) {
+	if err := uc.authorize(principal); err != nil {
+		return nil, err
+	}
+	employee, err := uc.empRepo.FindByID(grant.EmployeeID)
+	if err != nil {
+		return nil, &utils.NotFoundError{Message: "employee not found"}
+	}
+	// scope hanya berarti bagi pemegang role dengan permission ber-scope unit
+	role, err := uc.roleRepo.FindByID(employee.RoleID)
+	if err != nil {
+		return nil, &utils.NotFoundError{Message: "employee role not found"}
+	}
+	if !hasUnitPermission(role) {
+		return nil, &utils.BadRequestError{Message: "employee role has no unit-scoped permission"}
+	}
+	// grant diberikan atas satu unit atau satu unit group
+	if (grant.UnitID == 0) == (grant.UnitGroupID == 0) {
+		return nil, &utils.BadRequestError{Message: "exactly one of unit_id or unit_group_id is required"}
+	}
+	if grant.UnitID != 0 {
+		unit, err := uc.unitRepo.FindByID(grant.UnitID)
+		if err != nil {
+			return nil, &utils.NotFoundError{Message: "unit not found"}
+		}
+		grant.UnitName = unit.Name
+	} else {
+		group, err := uc.groupRepo.FindByID(grant.UnitGroupID)
+		if err != nil {
+			return nil, &utils.NotFoundError{Message: "unit group not found"}
+		}
+		grant.UnitGroupName = group.Name
+	}
+	grants, err := uc.scopeRepo.FindByEmployeeID(grant.EmployeeID)
+	if err != nil {
+		fmt.Println("Error getting unit scopes:", err)
+		return nil, &utils.InternalServerError{Message: "failed to get unit scopes"}
+	}
+	for _, existing := range grants {
+		if existing.UnitID == grant.UnitID && existing.UnitGroupID == grant.UnitGroupID {
+			return nil, &utils.ConflictError{Message: "unit already granted to employee"}
+		}
+	}
+	grant.GrantedBy = principal.UserID
+	saved, err := uc.scopeRepo.Save(grant)
+	if err != nil {
+		fmt.Println("Error saving unit scope:", err)
+		return nil, &utils.InternalServerError{Message: "failed to grant unit scope"}
+	}
+	utils.RecordAudit(uc.auditRepo, meta.Entry(principal.UserID, domain.AuditActionCreate, domain.AuditEntityUnitScope, saved.ID), nil, saved)
+	return saved, nil
+}
+
+func (uc *UnitScopeUsecase) Revoke(principal *domain.Principal, id int, meta domain.AuditMeta) error {
+	if err := uc.authorize(principal); err != nil {
+		return err
+	}
+	grant, err := uc.scopeRepo.FindByID(id)
+	if err != nil {
+		return &utils.NotFoundError{Message: "unit scope not found"}
+	}
+	if err := uc.scopeRepo.Delete(id); err != nil {
+		return &utils.NotFoundError{Message: "unit scope not found"}
+	}
+	utils.RecordAudit(uc.auditRepo, meta.Entry(principal.UserID, domain.AuditActionDelete, domain.AuditEntityUnitScope, id), grant, nil)
+	return nil
+}
+
+// ==================================================================== UTILITIES ====================================================================
+
+// authorize hanya pemegang permission unit_scope.manage yang boleh mengatur unit scope
+func (uc *UnitScopeUsecase) authorize(principal *domain.Principal) error {
+	return uc.authz.Authorize(principal, authorization.UnitScopeManage, authorization.Resource{})
+}
+
+func hasUnitPermission(role *domain.Role) bool {
+	for _, permission := range role.Permissions {
+		if permission.Scope == domain.PermissionScopeUnit {
+			return true
+		}
+	}
+	return false
+}
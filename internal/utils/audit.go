@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/achmadnr21/emploman/internal/domain"
+)
+
+// auditHiddenFields tidak pernah disimpan ke audit log
+var auditHiddenFields = []string{"password", "token_hash", "created_at", "modified_at"}
+
+// RecordAudit menyimpan audit log berisi field yang berubah antara before dan after.
+// before nil berarti create, after nil berarti delete. Kegagalan menyimpan audit hanya
+// dicatat ke log agar tidak membatalkan operasi yang sudah berhasil.
+func RecordAudit(repo domain.AuditInterface, entry *domain.AuditLog, before interface{}, after interface{}) {
+	beforeJSON, afterJSON, err := AuditDiff(before, after)
+	if err != nil {
+		fmt.Println("Error building audit diff:", err)
+	}
+	entry.Before = beforeJSON
+	entry.After = afterJSON
+	if err := repo.Save(entry); err != nil {
+		fmt.Println("Error saving audit log:", err)
+	}
+}
+
+// AuditDiff mengubah before dan after menjadi JSON yang hanya berisi field yang berbeda
+func AuditDiff(before interface{}, after interface{}) (json.RawMessage, json.RawMessage, error) {
+	beforeMap, err := toAuditMap(before)
+	if err != nil {
+		return nil, nil, err
+	}
+	afterMap, err := toAuditMap(after)
+	if err != nil {
+		return nil, nil, err
+	}
+	if beforeMap != nil && afterMap != nil {
+		for key, value := range beforeMap {
+			if other, ok := afterMap[key]; ok && reflect.DeepEqual(value, other) {
+				delete(beforeMap, key)
+				delete(afterMap, key)
+			}
+		}
+	}
+	beforeJSON, err := marshalAuditMap(beforeMap)
+	if err != nil {
+		return nil, nil, err
+	}
+	afterJSON, err := marshalAuditMap(afterMap)
+	if err != nil {
+		return nil, nil, err
+	}
+	return beforeJSON, afterJSON, nil
+}
+
+func toAuditMap(value interface{}) (map[string]interface{}, error) {
+	if value == nil || (reflect.ValueOf(value).Kind() == reflect.Ptr && reflect.ValueOf(value).IsNil()) {
+		return nil, nil
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]interface{}{}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		// bukan object (misal string atau angka), simpan sebagai field value
+		var scalar interface{}
+		if err := json.Unmarshal(raw, &scalar); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"value": scalar}, nil
+	}
+	for _, field := range auditHiddenFields {
+		delete(result, field)
+	}
+	return result, nil
+}
+
+func marshalAuditMap(value map[string]interface{}) (json.RawMessage, error) {
+	if value == nil {
+		return nil, nil
+	}
+	return json.Marshal(value)
+}
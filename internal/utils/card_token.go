@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Token kartu pegawai dibuat sependek mungkin agar QR code tetap mudah dipindai.
+// Format: base64url(nip|issued_unix).base64url(hmac_sha256)
+
+// cardSecrets terpisah dari secret JWT karena kartu yang sudah dicetak harus tetap valid
+// walaupun key JWT dirotasi. Secret pertama dipakai untuk tanda tangan, sisanya hanya verifikasi.
+var cardSecrets [][]byte
+
+// CardInit menyiapkan secret kartu pegawai, cardSecret boleh berisi beberapa secret dipisah koma
+func CardInit(cardSecret string) error {
+	secrets := splitSecrets(cardSecret)
+	if len(secrets) == 0 {
+		return errors.New("CARD_SECRET is required")
+	}
+	cardSecrets = nil
+	for _, secret := range secrets {
+		cardSecrets = append(cardSecrets, []byte(secret))
+	}
+	return nil
+}
+
+// GenerateCardToken membuat token bertanda tangan untuk QR code kartu pegawai
+func GenerateCardToken(nip string, issuedAt time.Time) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(nip + "|" + strconv.FormatInt(issuedAt.Unix(), 10)))
+	return payload + "." + base64.RawURLEncoding.EncodeToString(cardSignature(cardSecrets[0], payload))
+}
+
+// ParseCardToken memverifikasi tanda tangan token kartu dan mengembalikan nip serta waktu terbit
+func ParseCardToken(token string) (string, time.Time, error) {
+	invalid := &UnauthorizedError{Message: "invalid card token"}
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 {
+		return "", time.Time{}, invalid
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil || !validCardSignature(signature, parts[0]) {
+		return "", time.Time{}, invalid
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return "", time.Time{}, invalid
+	}
+	nip, issued, found := strings.Cut(string(payload), "|")
+	if !found {
+		return "", time.Time{}, invalid
+	}
+	issuedUnix, err := strconv.ParseInt(issued, 10, 64)
+	if err != nil {
+		return "", time.Time{}, invalid
+	}
+	return nip, time.Unix(issuedUnix, 0), nil
+}
+
+// validCardSignature menerima tanda tangan dari secret mana pun agar kartu lama tetap valid selama rotasi
+func validCardSignature(signature []byte, payload string) bool {
+	for _, secret := range cardSecrets {
+		if hmac.Equal(signature, cardSignature(secret, payload)) {
+			return true
+		}
+	}
+	return false
+}
+
+// cardSignature memakai prefix agar tidak bisa dipertukarkan dengan token lain
+func cardSignature(secret []byte, payload string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("card:" + payload))
+	return mac.Sum(nil)
+}
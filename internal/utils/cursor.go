@@ -0,0 +1,25 @@
+package utils
+
+import (
+	"encoding/base64"
+	"encoding/json"
+)
+
+// EncodeCursor mengubah nilai kolom sort dari baris terakhir menjadi cursor opaque
+func EncodeCursor(values []string) string {
+	raw, _ := json.Marshal(values)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeCursor kebalikan dari EncodeCursor
+func DecodeCursor(cursor string) ([]string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, &BadRequestError{Message: "invalid cursor"}
+	}
+	var values []string
+	if err := json.Unmarshal(raw, &values); err != nil {
+		return nil, &BadRequestError{Message: "invalid cursor"}
+	}
+	return values, nil
+}
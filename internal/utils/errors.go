@@ -37,7 +37,8 @@ func (e *InternalServerError) Error() string {
 }
 
 type TooManyRequestError struct {
-	Message string
+	Message    string
+	RetryAfter int // detik, dikirim sebagai header Retry-After jika lebih dari 0
 }
 
 func (e *TooManyRequestError) Error() string {
@@ -1,6 +1,11 @@
 package utils
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -18,3 +23,55 @@ func CheckPasswordHash(password, hashedPassword string) bool {
 	err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
 	return err == nil
 }
+
+// HashToken menghasilkan sha256 hex dari token, dipakai untuk menyimpan token di database
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// GenerateRandomID menghasilkan string hex acak sepanjang 2*n karakter
+func GenerateRandomID(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// PasswordMinLength adalah panjang minimal password baru
+const PasswordMinLength = 8
+
+// ValidatePassword memastikan password baru minimal PasswordMinLength karakter dan berisi huruf serta angka
+func ValidatePassword(password string) error {
+	if len(password) < PasswordMinLength {
+		return &BadRequestError{Message: fmt.Sprintf("password must be at least %d characters", PasswordMinLength)}
+	}
+	// batas input bcrypt adalah 72 byte
+	if len(password) > 72 {
+		return &BadRequestError{Message: "password cannot be more than 72 characters"}
+	}
+	var hasLetter, hasDigit bool
+	for _, c := range password {
+		switch {
+		case (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z'):
+			hasLetter = true
+		case c >= '0' && c <= '9':
+			hasDigit = true
+		}
+	}
+	if !hasLetter || !hasDigit {
+		return &BadRequestError{Message: "password must contain letters and numbers"}
+	}
+	return nil
+}
+
+// GenerateTemporaryPassword membuat password sementara yang lolos ValidatePassword
+func GenerateTemporaryPassword() (string, error) {
+	random, err := GenerateRandomID(6)
+	if err != nil {
+		return "", err
+	}
+	// hex bisa saja tanpa huruf atau tanpa angka, tambahkan prefix agar selalu valid
+	return "Tmp" + random + "9", nil
+}
@@ -1,5 +1,7 @@
 package utils
 
+import "time"
+
 func IsAlpha(s string) bool {
 	if s == "" {
 		return false
@@ -34,3 +36,11 @@ func IsAlphanumeric(s string) bool {
 	}
 	return true
 }
+
+// DateOnly membuang jam dari t, dipakai untuk kolom bertipe date. Zero time diganti tanggal hari ini.
+func DateOnly(t time.Time) time.Time {
+	if t.IsZero() {
+		t = time.Now()
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -1,9 +1,12 @@
 package utils
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/achmadnr21/emploman/internal/domain"
 	"github.com/golang-jwt/jwt/v5"
 )
 
@@ -11,74 +14,171 @@ import (
 const JWT_EXP_MIN = 30          // Token akses expired dalam 5 menit
 const REF_EXP_MIN = 7 * 24 * 60 // Token refresh expired dalam 7 hari
 
-// Secret key untuk signing dan verifying JWT
+// Keyring untuk signing dan verifying JWT. Refresh token hanya diverifikasi Emploman sendiri
+// sehingga cukup HMAC, token akses bisa memakai RS256/EdDSA agar bisa diverifikasi layanan lain.
 type JwtService struct {
-	jwtSecretKey     []byte
-	refreshSecretKey []byte
+	access  *Keyring
+	refresh *Keyring
 }
 
+// Audience token akses. Layanan lain yang memverifikasi lewat JWKS wajib memeriksa aud agar
+// token terbatas (ganti password, 2FA) tidak diterima sebagai token akses biasa.
+const (
+	AccessTokenAudience     = "emploman"
+	RestrictedTokenAudience = "emploman-restricted"
+)
+
 // Claims structure untuk payload JWT
 type Claims struct {
-	UserId string `json:"user_id"`
+	UserId   string `json:"user_id"`
+	FamilyID string `json:"fid,omitempty"` // hanya diisi pada refresh token
+	// Purpose menandai token akses terbatas, kosong berarti token akses biasa
+	Purpose string `json:"pur,omitempty"`
+	// RoleID dan Permissions hanya diisi pada token akses biasa agar tidak perlu query role tiap request
+	RoleID      string               `json:"rid,omitempty"`
+	Permissions domain.PermissionSet `json:"perm,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// Purpose token akses terbatas
+const (
+	TokenPurposePasswordChange = "password_change"  // hanya untuk mengganti password
+	TokenPurposeTwoFactor      = "two_factor"       // login menunggu verifikasi kode 2FA
+	TokenPurposeTwoFactorSetup = "two_factor_setup" // role mewajibkan 2FA yang belum diaktifkan
+)
+
 var jwtService JwtService
 
-// buat mutex untuk mengunci akses ke jwtService
+// JwtInit menyiapkan keyring. jwtSecret dan refreshSecret boleh berisi beberapa secret dipisah koma,
+// secret pertama dipakai untuk signing dan sisanya hanya untuk verifikasi selama rotasi. keyFiles
+// berisi file PEM RSA/Ed25519 untuk token akses, signingKeyID memilih key signing (kid). Jika kosong,
+// private key pertama dari keyFiles dipakai, atau secret HMAC pertama jika tidak ada key file.
+func JwtInit(jwtSecret string, refreshSecret string, keyFiles []string, signingKeyID string) error {
+	accessSecrets := splitSecrets(jwtSecret)
+	refreshSecrets := splitSecrets(refreshSecret)
+	if len(accessSecrets) == 0 {
+		return errors.New("JWT_SECRET is required")
+	}
+	if len(refreshSecrets) == 0 {
+		return errors.New("REFRESH_SECRET is required")
+	}
+
+	access := newKeyring()
+	for _, secret := range accessSecrets {
+		if err := access.add(hmacKey(secret)); err != nil {
+			return err
+		}
+	}
+	// token tanpa kid terbit sebelum keyring dipakai, ditandatangani secret pertama
+	access.legacy = access.keys[access.order[0]]
+	signingID := access.order[0]
+	asymmetricSigning := ""
+	for _, file := range keyFiles {
+		key, err := LoadJwtKeyFile(file)
+		if err != nil {
+			return err
+		}
+		if err := access.add(key); err != nil {
+			return err
+		}
+		if key.signKey != nil && asymmetricSigning == "" {
+			asymmetricSigning = key.ID
+		}
+	}
+	if asymmetricSigning != "" {
+		signingID = asymmetricSigning
+	}
+	if signingKeyID != "" {
+		signingID = signingKeyID
+	}
+	if err := access.setSigning(signingID); err != nil {
+		return err
+	}
 
-func JwtInit(jwtSecret string, refreshSecret string) {
+	refresh := newKeyring()
+	for _, secret := range refreshSecrets {
+		if err := refresh.add(hmacKey(secret)); err != nil {
+			return err
+		}
+	}
+	refresh.legacy = refresh.keys[refresh.order[0]]
+	if err := refresh.setSigning(refresh.order[0]); err != nil {
+		return err
+	}
 
-	jwtService.jwtSecretKey = []byte(jwtSecret)
-	jwtService.refreshSecretKey = []byte(refreshSecret)
+	jwtService.access = access
+	jwtService.refresh = refresh
+	return nil
 }
 
 func JwtPrint() {
 
-	fmt.Printf("JWT Secret %s\n", jwtService.jwtSecretKey)
-	fmt.Printf("Refresh Secret %s\n", jwtService.refreshSecretKey)
+	for _, kid := range jwtService.access.order {
+		fmt.Printf("JWT access key %s (%s)\n", kid, jwtService.access.keys[kid].Method.Alg())
+	}
+	fmt.Printf("JWT access signing key %s\n", jwtService.access.signing.ID)
+	for _, kid := range jwtService.refresh.order {
+		fmt.Printf("JWT refresh key %s (%s)\n", kid, jwtService.refresh.keys[kid].Method.Alg())
+	}
+
+}
 
+// AccessTokenJWKS mengembalikan public key token akses untuk endpoint /.well-known/jwks.json
+func AccessTokenJWKS() JWKS {
+	return jwtService.access.jwks()
 }
 
-// GenerateAccessToken membuat token akses (JWT biasa)
-func GenerateAccessToken(user_id string) (string, error) {
+// GenerateAccessToken membuat token akses (JWT biasa) beserta role dan permission pemiliknya
+func GenerateAccessToken(user_id string, role *domain.Role) (string, error) {
 
 	claims := &Claims{
-		UserId: user_id,
+		UserId:      user_id,
+		RoleID:      role.ID,
+		Permissions: role.PermissionSet(),
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute * time.Duration(JWT_EXP_MIN))),
 			Issuer:    "emploman",
+			Audience:  jwt.ClaimStrings{AccessTokenAudience},
 		},
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return jwtService.access.sign(claims)
+}
 
-	generatedToken, err := token.SignedString(jwtService.jwtSecretKey)
+// GenerateRestrictedToken membuat token akses terbatas yang hanya diterima endpoint sesuai purpose
+func GenerateRestrictedToken(user_id string, purpose string, ttl time.Duration) (string, error) {
 
-	return generatedToken, err
+	claims := &Claims{
+		UserId:  user_id,
+		Purpose: purpose,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+			Issuer:    "emploman",
+			Audience:  jwt.ClaimStrings{RestrictedTokenAudience},
+		},
+	}
+	return jwtService.access.sign(claims)
 }
 
-// GenerateRefreshToken membuat token refresh
-func GenerateRefreshToken(user_id string) (string, error) {
+// GenerateRefreshToken membuat token refresh, tokenID dipakai sebagai jti dan familyID
+// menandai rantai rotasi dari satu sesi login
+func GenerateRefreshToken(user_id string, tokenID string, familyID string, expiresAt time.Time) (string, error) {
 
 	claims := &Claims{
-		UserId: user_id,
+		UserId:   user_id,
+		FamilyID: familyID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute * time.Duration(REF_EXP_MIN))),
+			ID:        tokenID,
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
 			Issuer:    "emploman",
 		},
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	generatedToken, err := token.SignedString(jwtService.refreshSecretKey)
-
-	return generatedToken, err
+	return jwtService.refresh.sign(claims)
 }
 
 // ParseAccessToken untuk memverifikasi dan mengurai JWT akses
 func ParseAccessToken(tokenString string) (*Claims, error) {
 
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return jwtService.jwtSecretKey, nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, jwtService.access.keyFunc)
 
 	if err != nil {
 		return nil, &UnauthorizedError{Message: "invalid refresh token"}
@@ -94,9 +194,7 @@ func ParseAccessToken(tokenString string) (*Claims, error) {
 // ParseRefreshToken untuk memverifikasi dan mengurai JWT refresh
 func ParseRefreshToken(tokenString string) (*Claims, error) {
 
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return jwtService.refreshSecretKey, nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, jwtService.refresh.keyFunc)
 	if err != nil {
 		return nil, &UnauthorizedError{Message: "invalid refresh token"}
 	}
@@ -107,6 +205,17 @@ func ParseRefreshToken(tokenString string) (*Claims, error) {
 	return nil, &UnauthorizedError{Message: "invalid refresh token"}
 }
 
+// splitSecrets memisahkan secret yang dipisah koma dan membuang entri kosong
+func splitSecrets(value string) []string {
+	var secrets []string
+	for _, secret := range strings.Split(value, ",") {
+		if secret = strings.TrimSpace(secret); secret != "" {
+			secrets = append(secrets, secret)
+		}
+	}
+	return secrets
+}
+
 func GetCurrentTime() int64 {
 	return time.Now().Unix()
 }
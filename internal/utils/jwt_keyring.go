@@ -0,0 +1,196 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// rsaMinBits adalah ukuran minimal key RSA yang diterima
+const rsaMinBits = 2048
+
+// JwtKey adalah satu key pada keyring. signKey nil berarti key hanya dipakai untuk verifikasi,
+// misalnya public key dari key lama yang token-tokennya belum expired.
+type JwtKey struct {
+	ID        string
+	Method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+// Keyring menyimpan beberapa key aktif yang dibedakan lewat header kid. Token baru selalu
+// ditandatangani key signing, verifikasi menerima semua key di keyring sehingga key bisa
+// dirotasi tanpa membuat semua user logout.
+type Keyring struct {
+	signing *JwtKey
+	// legacy dipakai untuk token tanpa kid yang terbit sebelum keyring dipakai
+	legacy *JwtKey
+	keys   map[string]*JwtKey
+	order  []string
+}
+
+// JWK adalah public key dalam format RFC 7517
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+func newKeyring() *Keyring {
+	return &Keyring{
+		keys: map[string]*JwtKey{},
+	}
+}
+
+func (k *Keyring) add(key *JwtKey) error {
+	if _, exists := k.keys[key.ID]; exists {
+		return fmt.Errorf("duplicate jwt key id %q", key.ID)
+	}
+	k.keys[key.ID] = key
+	k.order = append(k.order, key.ID)
+	return nil
+}
+
+func (k *Keyring) setSigning(kid string) error {
+	key, ok := k.keys[kid]
+	if !ok {
+		return fmt.Errorf("signing key %q not found", kid)
+	}
+	if key.signKey == nil {
+		return fmt.Errorf("signing key %q has no private key", kid)
+	}
+	k.signing = key
+	return nil
+}
+
+func (k *Keyring) sign(claims jwt.Claims) (string, error) {
+	if k.signing == nil {
+		return "", errors.New("no jwt signing key configured")
+	}
+	token := jwt.NewWithClaims(k.signing.Method, claims)
+	token.Header["kid"] = k.signing.ID
+	return token.SignedString(k.signing.signKey)
+}
+
+// keyFunc memilih key berdasarkan kid dan menolak token yang algoritmanya berbeda dengan key,
+// misalnya token HS256 yang ditandatangani memakai public key RSA
+func (k *Keyring) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key := k.keys[kid]
+	if kid == "" {
+		key = k.legacy
+	}
+	if key == nil {
+		return nil, fmt.Errorf("unknown jwt key id %q", kid)
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method %s for key %q", token.Method.Alg(), kid)
+	}
+	return key.verifyKey, nil
+}
+
+// jwks mengembalikan public key asimetris di keyring, key HMAC tidak pernah dipublikasikan
+func (k *Keyring) jwks() JWKS {
+	set := JWKS{Keys: []JWK{}}
+	for _, kid := range k.order {
+		key := k.keys[kid]
+		switch public := key.verifyKey.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				Kty: "RSA",
+				Kid: key.ID,
+				Use: "sig",
+				Alg: key.Method.Alg(),
+				N:   base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				Kty: "OKP",
+				Kid: key.ID,
+				Use: "sig",
+				Alg: key.Method.Alg(),
+				Crv: "Ed25519",
+				X:   base64.RawURLEncoding.EncodeToString(public),
+			})
+		}
+	}
+	return set
+}
+
+// hmacKey membuat key HS256, kid diturunkan dari hash secret agar stabil antar restart
+func hmacKey(secret string) *JwtKey {
+	return &JwtKey{
+		ID:        "hs-" + HashToken(secret)[:8],
+		Method:    jwt.SigningMethodHS256,
+		signKey:   []byte(secret),
+		verifyKey: []byte(secret),
+	}
+}
+
+// LoadJwtKeyFile membaca private atau public key RSA/Ed25519 dari file PEM.
+// kid diambil dari nama file tanpa ekstensi, contoh keys/2025-01.pem menjadi 2025-01.
+// File yang hanya berisi public key dipakai untuk verifikasi saja.
+func LoadJwtKeyFile(path string) (*JwtKey, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM block found", path)
+	}
+	var parsed interface{}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("%s: unsupported PEM block %q", path, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	key := &JwtKey{ID: strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))}
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.Method, key.signKey, key.verifyKey = jwt.SigningMethodRS256, k, &k.PublicKey
+	case *rsa.PublicKey:
+		key.Method, key.verifyKey = jwt.SigningMethodRS256, k
+	case ed25519.PrivateKey:
+		key.Method, key.signKey, key.verifyKey = jwt.SigningMethodEdDSA, k, k.Public()
+	case ed25519.PublicKey:
+		key.Method, key.verifyKey = jwt.SigningMethodEdDSA, k
+	default:
+		return nil, fmt.Errorf("%s: unsupported key type %T, use RSA or Ed25519", path, parsed)
+	}
+	if public, ok := key.verifyKey.(*rsa.PublicKey); ok && public.N.BitLen() < rsaMinBits {
+		return nil, fmt.Errorf("%s: RSA key must be at least %d bits", path, rsaMinBits)
+	}
+	return key, nil
+}
@@ -0,0 +1,9 @@
+package utils
+
+import "github.com/achmadnr21/emploman/internal/domain"
+
+// PrincipalRole mengambil role employee saat ini, bukan role id di token, agar promosi atau
+// penurunan role langsung berlaku untuk token yang sudah terbit
+func PrincipalRole(roleRepo domain.RoleInterface, principal *domain.Principal) (*domain.Role, error) {
+	return roleRepo.FindByUserID(principal.UserID)
+}
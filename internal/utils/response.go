@@ -5,6 +5,7 @@ type Response struct {
 	Message string      `json:"message"`
 	Dev     string      `json:"developer"`
 	Data    interface{} `json:"data"`
+	Meta    interface{} `json:"meta,omitempty"`
 }
 
 func ResponseSuccess(message string, data interface{}) *Response {
@@ -16,6 +17,13 @@ func ResponseSuccess(message string, data interface{}) *Response {
 	}
 }
 
+// ResponseSuccessWithMeta sama seperti ResponseSuccess dengan tambahan blok meta (pagination, dsb)
+func ResponseSuccessWithMeta(message string, data interface{}, meta interface{}) *Response {
+	response := ResponseSuccess(message, data)
+	response.Meta = meta
+	return response
+}
+
 func ResponseError(message string) *Response {
 	return &Response{
 		Status:  "error",